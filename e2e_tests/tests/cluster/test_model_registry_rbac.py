@@ -1,3 +1,4 @@
+import json
 import uuid
 from typing import Any, Dict, List, Tuple
 
@@ -214,9 +215,9 @@ def test_model_registry_rbac() -> None:
 
         # Test noperms user.
         d = client.Determined._from_session(noperms)
-        with pytest.raises(Exception) as e:
+        with pytest.raises(errors.ForbiddenException) as e:
             d.get_models()
-        assert "doesn't have view permissions" in str(e.value)
+        assert "cannot get models" in str(e.value)
 
         # Unassign view permissions to a certain workspace.
         # List should return models only in workspaces with permissions.
@@ -302,6 +303,8 @@ def test_model_rbac_deletes() -> None:
             user=bindings.v1User(username=get_random_string(), active=True, admin=True),
         )
 
+        # Deleted models keep their names reserved, so names must be unique across runs.
+        model_prefix = "model_" + get_random_string() + "_"
         model_num = 0
         try:
             # test deleting model registries
@@ -337,7 +340,7 @@ def test_model_rbac_deletes() -> None:
                 delete_session: api.Session = t["delete_session"]
                 should_error: bool = t["should_error"]
 
-                model_name = "model_" + str(model_num)
+                model_name = model_prefix + str(model_num)
                 model_num += 1
                 create_model_registry(create_session, model_name, workspace_id)
 
@@ -385,7 +388,7 @@ def test_model_rbac_deletes() -> None:
                 delete_session = t["delete_session"]
                 should_error = t["should_error"]
 
-                model_name = "model_" + str(model_num)
+                model_name = model_prefix + str(model_num)
                 model_num += 1
                 m, ca_model_version = register_model_version(
                     sess=create_session, model_name=model_name, workspace_id=workspace_id
@@ -415,7 +418,1136 @@ def test_model_rbac_deletes() -> None:
             admin_session = api_utils.admin_session()
             for i in range(model_num):
                 try:
-                    bindings.delete_DeleteModel(admin_session, modelName="model_" + str(i))
+                    bindings.delete_DeleteModel(admin_session, modelName=model_prefix + str(i))
                 # model is has already been cleaned up
                 except errors.NotFoundException:
                     continue
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_get_models_by_ids() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Editor"]),
+            ],
+            [],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        readable, unreadable = (
+            bindings.post_PostModel(
+                admin,
+                body=bindings.v1PostModelRequest(name=get_random_string(), workspaceId=w.id),
+            ).model
+            for w in workspaces
+        )
+        missing_id = unreadable.id + 1000000
+        try:
+            resp = bindings.post_GetModelsByIds(
+                creds[0],
+                body=bindings.v1GetModelsByIdsRequest(
+                    modelIds=[unreadable.id, readable.id, missing_id]
+                ),
+            )
+            assert [m.id for m in resp.models] == [readable.id]
+            assert resp.missingModelIds == [unreadable.id, missing_id]
+
+            resp = bindings.post_GetModelsByIds(
+                admin,
+                body=bindings.v1GetModelsByIdsRequest(
+                    modelIds=[readable.id, unreadable.id],
+                    workspaceId=workspaces[1].id,
+                ),
+            )
+            assert [m.id for m in resp.models] == [unreadable.id]
+            assert resp.missingModelIds == [readable.id]
+        finally:
+            for m in [readable, unreadable]:
+                bindings.delete_DeleteModel(admin, modelName=m.name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_check_authz() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Viewer"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        viewer = bindings.get_GetMe(creds[0]).user
+        assert viewer.id is not None
+        m = bindings.post_PostModel(
+            admin,
+            body=bindings.v1PostModelRequest(
+                name=get_random_string(), workspaceId=workspaces[0].id
+            ),
+        ).model
+        action = bindings.CheckModelAuthZRequestAction
+        try:
+            for a, allowed in [(action.GET, True), (action.EDIT, False), (action.DELETE, False)]:
+                resp = bindings.post_CheckModelAuthZ(
+                    admin,
+                    body=bindings.v1CheckModelAuthZRequest(
+                        userId=viewer.id, action=a, modelId=m.id
+                    ),
+                )
+                assert resp.allowed == allowed, a
+                assert bool(resp.error) != allowed, a
+                # Only get checks explain their decision.
+                assert bool(resp.reason) == (a == action.GET), a
+
+            # Create checks do not need the workspace to exist.
+            resp = bindings.post_CheckModelAuthZ(
+                admin,
+                body=bindings.v1CheckModelAuthZRequest(
+                    userId=viewer.id, action=action.CREATE, workspaceId=-1
+                ),
+            )
+            assert not resp.allowed
+
+            # Checks have no side effects.
+            assert bindings.get_GetModel(admin, modelName=m.name).model.name == m.name
+        finally:
+            bindings.delete_DeleteModel(admin, modelName=m.name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_get_model_inaccessible_workspace() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Viewer"]),
+            ],
+            [
+                (1, ["Viewer"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        m = bindings.post_PostModel(
+            admin,
+            body=bindings.v1PostModelRequest(
+                name=get_random_string(), workspaceId=workspaces[0].id
+            ),
+        ).model
+        try:
+            assert bindings.get_GetModel(creds[0], modelName=m.name).model.id == m.id
+
+            # Users who cannot see the workspace get the same error as for a missing model.
+            with pytest.raises(errors.NotFoundException) as notFoundErr:
+                bindings.get_GetModel(creds[1], modelName=m.name)
+            assert "not found" in str(notFoundErr.value).lower()
+            with pytest.raises(errors.NotFoundException):
+                bindings.get_GetModel(creds[1], modelName=str(m.id))
+        finally:
+            bindings.delete_DeleteModel(admin, modelName=m.name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_tags() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Viewer"]),
+                (1, ["Editor"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        m = bindings.post_PostModel(
+            admin,
+            body=bindings.v1PostModelRequest(
+                name=get_random_string(), workspaceId=workspaces[0].id
+            ),
+        ).model
+        try:
+            with pytest.raises(errors.ForbiddenException):
+                bindings.put_PutModelTag(
+                    creds[0],
+                    body=bindings.v1PutModelTagRequest(
+                        modelName=m.name, key="team", value="vision"
+                    ),
+                    key="team",
+                    modelName=m.name,
+                )
+
+            resp = bindings.put_PutModelTag(
+                creds[1],
+                body=bindings.v1PutModelTagRequest(modelName=m.name, key="team", value="vision"),
+                key="team",
+                modelName=m.name,
+            )
+            assert resp.model.tags == {"team": "vision"}
+            assert resp.model.labels == m.labels
+
+            models = bindings.get_GetModels(
+                creds[0], tags=["team=vision"], workspaceIds=[workspaces[0].id]
+            ).models
+            assert [mod.id for mod in models] == [m.id]
+            models = bindings.get_GetModels(
+                creds[0], tags=["team=nlp"], workspaceIds=[workspaces[0].id]
+            ).models
+            assert models == []
+
+            bindings.delete_DeleteModelTag(creds[1], key="team", modelName=m.name)
+            # Deleting a tag that is not set succeeds.
+            resp = bindings.delete_DeleteModelTag(creds[1], key="team", modelName=m.name)
+            assert not resp.model.tags
+        finally:
+            bindings.delete_DeleteModel(admin, modelName=m.name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_copy_model() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Viewer"]),
+                (1, ["Viewer"]),
+            ],
+            [
+                (1, ["Editor"]),
+                (2, ["Editor"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        m, _ = register_model_version(admin, get_random_string(), workspaces[0].id)
+        copies = []
+        try:
+            # Copying needs to see the source and to create models in the target workspace.
+            for sess, workspace in [(creds[0], workspaces[1]), (creds[2], workspaces[1])]:
+                with pytest.raises(errors.ForbiddenException):
+                    bindings.post_CopyModel(
+                        sess,
+                        body=bindings.v1CopyModelRequest(
+                            modelName=m.name, workspaceId=workspace.id
+                        ),
+                        modelName=m.name,
+                    )
+
+            for suffix in [" copy", " copy 2"]:
+                copy = bindings.post_CopyModel(
+                    creds[1],
+                    body=bindings.v1CopyModelRequest(
+                        modelName=m.name, workspaceId=workspaces[1].id
+                    ),
+                    modelName=m.name,
+                ).model
+                copies.append(copy)
+                assert copy.name == m.name + suffix
+                assert copy.workspaceId == workspaces[1].id
+                assert copy.numVersions == 1
+
+            versions = bindings.get_GetModelVersions(admin, modelName=copies[0].name)
+            original = bindings.get_GetModelVersions(admin, modelName=m.name)
+            assert versions.modelVersions[0].version == 1
+            assert (
+                versions.modelVersions[0].checkpoint.uuid
+                == original.modelVersions[0].checkpoint.uuid
+            )
+
+            # Copying versions that do not exist fails without creating a model.
+            with pytest.raises(errors.NotFoundException):
+                bindings.post_CopyModel(
+                    creds[1],
+                    body=bindings.v1CopyModelRequest(
+                        modelName=m.name,
+                        workspaceId=workspaces[1].id,
+                        name=get_random_string(),
+                        versions=[1, 2],
+                    ),
+                    modelName=m.name,
+                )
+        finally:
+            for c in copies:
+                bindings.delete_DeleteModel(admin, modelName=c.name)
+            bindings.delete_DeleteModel(admin, modelName=m.name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_export_import_model() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Viewer"]),
+                (1, ["Viewer"]),
+            ],
+            [
+                (1, ["Editor"]),
+                (2, ["Editor"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        m, _ = register_model_version(admin, get_random_string(), workspaces[0].id)
+        imported = []
+        try:
+            with pytest.raises(errors.ForbiddenException):
+                bindings.get_ExportModel(creds[2], modelName=m.name)
+            manifest = bindings.get_ExportModel(creds[1], modelName=m.name).manifest
+            assert bindings.get_ExportModel(creds[1], modelName=m.name).manifest == manifest
+            checkpoint_uuid = json.loads(manifest)["versions"][0]["checkpoint_uuid"]
+
+            # Importing needs to create models in the target workspace.
+            with pytest.raises(errors.ForbiddenException):
+                bindings.post_ImportModel(
+                    creds[0],
+                    body=bindings.v1ImportModelRequest(
+                        manifest=manifest, workspaceId=workspaces[1].id, name=get_random_string()
+                    ),
+                )
+
+            # Missing checkpoints are listed, and nothing is imported.
+            missing = str(uuid.uuid4())
+            name = get_random_string()
+            with pytest.raises(errors.NotFoundException, match=missing):
+                bindings.post_ImportModel(
+                    creds[1],
+                    body=bindings.v1ImportModelRequest(
+                        manifest=manifest,
+                        workspaceId=workspaces[1].id,
+                        name=name,
+                        checkpointUuids={checkpoint_uuid: missing},
+                    ),
+                )
+            with pytest.raises(errors.NotFoundException):
+                bindings.get_GetModel(admin, modelName=name)
+
+            copy = bindings.post_ImportModel(
+                creds[1],
+                body=bindings.v1ImportModelRequest(
+                    manifest=manifest, workspaceId=workspaces[1].id, name=name
+                ),
+            ).model
+            imported.append(copy)
+            assert copy.workspaceId == workspaces[1].id
+            versions = bindings.get_GetModelVersions(admin, modelName=name).modelVersions
+            assert [(v.version, v.checkpoint.uuid) for v in versions] == [(1, checkpoint_uuid)]
+        finally:
+            for c in imported:
+                bindings.delete_DeleteModel(admin, modelName=c.name)
+            bindings.delete_DeleteModel(admin, modelName=m.name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_checkpoint_model_versions() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Viewer"]),
+            ],
+            [
+                (1, ["Viewer"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        shared, version = register_model_version(admin, get_random_string(), workspaces[0].id)
+        assert version.checkpoint is not None
+        checkpoint_uuid = version.checkpoint.uuid
+        restricted = create_model_registry(admin, get_random_string(), workspaces[1].id)
+        try:
+            restricted.register_version(checkpoint_uuid)
+
+            resp = bindings.get_GetCheckpointModelVersions(admin, checkpointUuid=checkpoint_uuid)
+            assert sorted(v.model.name for v in resp.modelVersions) == sorted(
+                [shared.name, restricted.name]
+            )
+            assert resp.pagination.total == 2
+
+            # The model in the workspace the user cannot view is left out, and not counted.
+            resp = bindings.get_GetCheckpointModelVersions(creds[0], checkpointUuid=checkpoint_uuid)
+            assert [v.model.name for v in resp.modelVersions] == [shared.name]
+            assert resp.pagination.total == 1
+        finally:
+            bindings.delete_DeleteModel(admin, modelName=restricted.name)
+            bindings.delete_DeleteModel(admin, modelName=shared.name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_compare_model_versions() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Viewer"]),
+            ],
+            [],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        m, version = register_model_version(admin, get_random_string(), workspaces[0].id)
+        # The second version comes from an experiment in a workspace the user cannot view.
+        other, other_version = register_model_version(
+            admin, get_random_string(), workspaces[1].id
+        )
+        assert other_version.checkpoint is not None
+        try:
+            hidden = m.register_version(other_version.checkpoint.uuid)
+            for v, accuracy in [(1, 0.5), (hidden.model_version, 0.75)]:
+                bindings.patch_PatchModelVersion(
+                    admin,
+                    body=bindings.v1PatchModelVersion(metadata={"accuracy": accuracy}),
+                    modelName=m.name,
+                    modelVersionNum=v,
+                )
+
+            changes = bindings.get_CompareModelVersions(
+                admin, modelName=m.name, versionA=1, versionB=2
+            ).changes
+            assert [(c.key, c.delta) for c in changes] == [("accuracy", 0.25)]
+
+            # The hidden side is reported like a version that does not exist.
+            for missing in [2, 3]:
+                with pytest.raises(errors.NotFoundException, match=f"version {missing}"):
+                    bindings.get_CompareModelVersions(
+                        creds[0], modelName=m.name, versionA=1, versionB=missing
+                    )
+            assert (
+                bindings.get_CompareModelVersions(
+                    creds[0], modelName=m.name, versionA=1, versionB=1
+                ).changes
+                == []
+            )
+        finally:
+            bindings.delete_DeleteModel(admin, modelName=other.name)
+            bindings.delete_DeleteModel(admin, modelName=m.name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_private_models() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Editor"]),
+                (1, ["Viewer"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        private = bindings.post_PostModel(
+            creds[0],
+            body=bindings.v1PostModelRequest(
+                name=get_random_string(),
+                workspaceId=workspaces[0].id,
+                visibility=bindings.v1ModelVisibility.PRIVATE,
+            ),
+        ).model
+        public = bindings.post_PostModel(
+            creds[0],
+            body=bindings.v1PostModelRequest(
+                name=get_random_string(), workspaceId=workspaces[0].id
+            ),
+        ).model
+        try:
+            assert private.visibility == bindings.v1ModelVisibility.PRIVATE
+            assert public.visibility == bindings.v1ModelVisibility.WORKSPACE
+
+            def listed(sess: api.Session) -> List[int]:
+                resp = bindings.get_GetModels(sess, workspaceIds=[workspaces[0].id])
+                return sorted(m.id for m in resp.models)
+
+            assert listed(creds[0]) == sorted([private.id, public.id])
+            assert listed(creds[1]) == [public.id]
+            with pytest.raises(errors.ForbiddenException):
+                bindings.get_GetModel(creds[1], modelName=private.name)
+
+            bindings.patch_PatchModel(
+                creds[0],
+                body=bindings.v1PatchModel(visibility=bindings.v1ModelVisibility.WORKSPACE),
+                modelName=private.name,
+            )
+            assert listed(creds[1]) == sorted([private.id, public.id])
+        finally:
+            for m in [private, public]:
+                bindings.delete_DeleteModel(admin, modelName=m.name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_access_grants() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Editor"]),
+            ],
+            [
+                (1, ["Viewer"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        m = bindings.post_PostModel(
+            creds[0],
+            body=bindings.v1PostModelRequest(
+                name=get_random_string(), workspaceId=workspaces[0].id
+            ),
+        ).model
+        grantee = bindings.get_GetMe(creds[1]).user
+        assert grantee.id is not None
+
+        def grant(access: bindings.v1ModelAccessLevel) -> None:
+            bindings.put_PutModelAccessGrant(
+                creds[0],
+                body=bindings.v1PutModelAccessGrantRequest(
+                    access=access, modelName=m.name, userId=grantee.id
+                ),
+                modelName=m.name,
+                userId=grantee.id,
+            )
+
+        def describe(sess: api.Session) -> None:
+            bindings.patch_PatchModel(
+                sess, body=bindings.v1PatchModel(description="shared"), modelName=m.name
+            )
+
+        try:
+            with pytest.raises(errors.NotFoundException):
+                bindings.get_GetModel(creds[1], modelName=m.name)
+
+            grant(bindings.v1ModelAccessLevel.READ)
+            assert bindings.get_GetModel(creds[1], modelName=m.name).model.id == m.id
+            with pytest.raises(errors.ForbiddenException):
+                describe(creds[1])
+            with pytest.raises(errors.ForbiddenException):
+                bindings.get_GetModelAccessGrants(creds[1], modelName=m.name)
+
+            grant(bindings.v1ModelAccessLevel.EDIT)
+            describe(creds[1])
+            grants = bindings.get_GetModelAccessGrants(creds[0], modelName=m.name).grants
+            assert [(g.userId, g.access) for g in grants] == [
+                (grantee.id, bindings.v1ModelAccessLevel.EDIT)
+            ]
+
+            bindings.delete_DeleteModelAccessGrant(creds[0], modelName=m.name, userId=grantee.id)
+            with pytest.raises(errors.NotFoundException):
+                bindings.get_GetModel(creds[1], modelName=m.name)
+        finally:
+            bindings.delete_DeleteModel(admin, modelName=m.name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_bulk_actions() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Editor"]),
+            ],
+            [
+                (0, ["Viewer"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        editable, readonly = [
+            bindings.post_PostModel(
+                admin,
+                body=bindings.v1PostModelRequest(name=get_random_string(), workspaceId=w.id),
+            ).model
+            for w in workspaces
+        ]
+        try:
+            ids = [editable.id, readonly.id, -1]
+            resp = bindings.post_ArchiveModels(
+                creds[0], body=bindings.v1ArchiveModelsRequest(modelIds=ids)
+            )
+            assert [r.id for r in resp.results] == ids
+            assert [r.code for r in resp.results] == [0, 7, 5]
+            assert resp.results[0].error == ""
+            assert "access denied" in resp.results[1].error
+            assert bindings.get_GetModel(admin, modelName=editable.name).model.archived
+            assert not bindings.get_GetModel(admin, modelName=readonly.name).model.archived
+
+            resp = bindings.post_DeleteModels(
+                creds[0], body=bindings.v1DeleteModelsRequest(modelIds=ids)
+            )
+            assert [r.code for r in resp.results] == [0, 7, 5]
+            with pytest.raises(errors.NotFoundException):
+                bindings.get_GetModel(admin, modelName=editable.name)
+        finally:
+            bindings.delete_DeleteModel(admin, modelName=readonly.name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_search() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Viewer"]),
+            ],
+            [],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        word = get_random_string()
+        name_match, description_match, hidden = [
+            bindings.post_PostModel(
+                admin,
+                body=bindings.v1PostModelRequest(
+                    name=name,
+                    description=description,
+                    workspaceId=w.id,
+                ),
+            ).model
+            for name, description, w in [
+                (f"{word} {get_random_string()}", "", workspaces[0]),
+                (get_random_string(), f"based on {word}", workspaces[0]),
+                (f"{word} {get_random_string()}", "", workspaces[1]),
+            ]
+        ]
+        try:
+            resp = bindings.get_GetModels(creds[0], search=word)
+            assert [m.id for m in resp.models] == [name_match.id, description_match.id]
+            assert resp.pagination.total == 2
+
+            resp = bindings.get_GetModels(creds[0], search=word, limit=1, offset=1)
+            assert [m.id for m in resp.models] == [description_match.id]
+
+            resp = bindings.get_GetModels(admin, search=word)
+            assert sorted(m.id for m in resp.models) == sorted(
+                [name_match.id, description_match.id, hidden.id]
+            )
+        finally:
+            for m in [name_match, description_match, hidden]:
+                bindings.delete_DeleteModel(admin, modelName=m.name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_hide_version_checkpoint_storage() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (1, ["Viewer"]),
+            ],
+            [
+                (0, ["Viewer"]),
+                (1, ["Viewer"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        # The checkpoint comes from an experiment in the first workspace.
+        m, _ = register_model_version(admin, get_random_string(), workspaces[0].id)
+        copy = bindings.post_CopyModel(
+            admin,
+            body=bindings.v1CopyModelRequest(modelName=m.name, workspaceId=workspaces[1].id),
+            modelName=m.name,
+        ).model
+        try:
+            hidden = bindings.get_GetModelVersion(
+                creds[0], modelName=copy.name, modelVersionNum=1
+            ).modelVersion
+            assert hidden.checkpoint.uuid
+            assert not hidden.checkpoint.resources
+            assert hidden.checkpoint.storageId is None
+
+            shown = bindings.get_GetModelVersion(
+                creds[1], modelName=copy.name, modelVersionNum=1
+            ).modelVersion
+            assert shown.checkpoint.uuid == hidden.checkpoint.uuid
+            assert shown.checkpoint.resources
+        finally:
+            bindings.delete_DeleteModel(admin, modelName=copy.name)
+            bindings.delete_DeleteModel(admin, modelName=m.name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_patch_expected_version() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Editor"]),
+                (1, ["Viewer"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        m = bindings.post_PostModel(
+            admin,
+            body=bindings.v1PostModelRequest(
+                name=get_random_string(), workspaceId=workspaces[0].id
+            ),
+        ).model
+        try:
+            assert m.version == 1
+            bindings.patch_PatchModel(
+                creds[0],
+                body=bindings.v1PatchModel(description="first", expectedVersion=1),
+                modelName=m.name,
+            )
+            assert bindings.get_GetModel(admin, modelName=m.name).model.version == 2
+
+            # A stale edit is rejected and reports the current version.
+            with pytest.raises(errors.APIException) as e:
+                bindings.patch_PatchModel(
+                    creds[0],
+                    body=bindings.v1PatchModel(description="second", expectedVersion=1),
+                    modelName=m.name,
+                )
+            assert "current version is 2" in str(e.value)
+            got = bindings.get_GetModel(admin, modelName=m.name).model
+            assert got.description == "first"
+
+            # Authorization is checked before the version.
+            with pytest.raises(errors.ForbiddenException):
+                bindings.patch_PatchModel(
+                    creds[1],
+                    body=bindings.v1PatchModel(description="second", expectedVersion=1),
+                    modelName=m.name,
+                )
+
+            # Edits without an expected version are applied as before.
+            bindings.patch_PatchModel(
+                creds[0], body=bindings.v1PatchModel(description="second"), modelName=m.name
+            )
+            assert bindings.get_GetModel(admin, modelName=m.name).model.version == 3
+        finally:
+            bindings.delete_DeleteModel(admin, modelName=m.name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_count_models() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Editor"]),
+                (1, ["Viewer"]),
+            ],
+            [
+                (0, ["Editor"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        label = get_random_string()
+        models = [
+            bindings.post_PostModel(
+                creds[0],
+                body=bindings.v1PostModelRequest(
+                    name=get_random_string(),
+                    workspaceId=workspaces[0].id,
+                    labels=[label],
+                    visibility=visibility,
+                ),
+            ).model
+            for visibility in [
+                bindings.v1ModelVisibility.WORKSPACE,
+                bindings.v1ModelVisibility.WORKSPACE,
+                bindings.v1ModelVisibility.PRIVATE,
+            ]
+        ]
+        bindings.post_ArchiveModel(creds[0], modelName=models[1].name)
+        try:
+            for sess in [creds[0], creds[1]]:
+                for include_archived in [False, True]:
+                    listed = bindings.get_GetModels(
+                        sess,
+                        workspaceIds=[workspaces[0].id],
+                        labels=[label],
+                        includeArchived=include_archived,
+                    )
+                    count = bindings.get_CountModels(
+                        sess,
+                        workspaceId=workspaces[0].id,
+                        labels=[label],
+                        includeArchived=include_archived,
+                    ).count
+                    assert count == listed.pagination.total
+
+            # The private model only counts for its owner.
+            assert bindings.get_CountModels(creds[0], workspaceId=workspaces[0].id).count == 2
+            assert bindings.get_CountModels(creds[1], workspaceId=workspaces[0].id).count == 1
+
+            with pytest.raises(errors.ForbiddenException):
+                bindings.get_CountModels(creds[1], workspaceId=workspaces[1].id)
+        finally:
+            for m in models:
+                bindings.delete_DeleteModel(admin, modelName=m.name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_get_models_cursor() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Editor"]),
+                (1, ["Viewer"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        label = get_random_string()
+        prefix = get_random_string()
+        names = [f"{prefix}-{i}" for i in range(5)]
+        for name in names:
+            bindings.post_PostModel(
+                creds[0],
+                body=bindings.v1PostModelRequest(
+                    name=name, workspaceId=workspaces[0].id, labels=[label]
+                ),
+            )
+        # Only visible to its owner, so the viewer's pages skip it.
+        private_name = f"{prefix}-private"
+        bindings.post_PostModel(
+            creds[0],
+            body=bindings.v1PostModelRequest(
+                name=private_name,
+                workspaceId=workspaces[0].id,
+                labels=[label],
+                visibility=bindings.v1ModelVisibility.PRIVATE,
+            ),
+        )
+        try:
+
+            def page(cursor: str) -> bindings.v1GetModelsResponse:
+                return bindings.get_GetModels(
+                    creds[1],
+                    labels=[label],
+                    sortBy=bindings.v1GetModelsRequestSortBy.NAME,
+                    limit=2,
+                    cursor=cursor,
+                )
+
+            first = page("")
+            assert [m.name for m in first.models] == names[:2]
+            assert first.pagination.total == len(names)
+            assert first.nextCursor
+
+            # Archiving the model the cursor points at does not shift the next page.
+            bindings.post_ArchiveModel(creds[0], modelName=names[1])
+            second = page(first.nextCursor)
+            assert [m.name for m in second.models] == names[2:4]
+            assert second.nextCursor
+
+            third = page(second.nextCursor)
+            assert [m.name for m in third.models] == names[4:]
+            assert not third.nextCursor
+
+            with pytest.raises(errors.APIException):
+                bindings.get_GetModels(
+                    creds[1],
+                    labels=[label],
+                    sortBy=bindings.v1GetModelsRequestSortBy.CREATION_TIME,
+                    limit=2,
+                    cursor=first.nextCursor,
+                )
+        finally:
+            for name in names + [private_name]:
+                bindings.delete_DeleteModel(admin, modelName=name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_get_models_sort_by_metadata() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Editor"]),
+                (1, ["Viewer"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        label = get_random_string()
+        key = f"accuracy-{get_random_string()}"
+        # Sorted as text, "10" would come before "2".
+        values = {"low": 0.5, "mid": 2, "high": 10}
+        prefix = get_random_string()
+        names = [f"{prefix}-{v}" for v in values] + [f"{prefix}-none"]
+        for name, value in zip(names, values.values()):
+            bindings.post_PostModel(
+                creds[0],
+                body=bindings.v1PostModelRequest(
+                    name=name, workspaceId=workspaces[0].id, labels=[label], metadata={key: value}
+                ),
+            )
+        bindings.post_PostModel(
+            creds[0],
+            body=bindings.v1PostModelRequest(
+                name=names[-1], workspaceId=workspaces[0].id, labels=[label]
+            ),
+        )
+        try:
+
+            def sorted_names(order_by: bindings.v1OrderBy) -> List[str]:
+                result: List[str] = []
+                cursor = ""
+                while True:
+                    resp = bindings.get_GetModels(
+                        creds[1],
+                        labels=[label],
+                        sortBy=bindings.v1GetModelsRequestSortBy.METADATA,
+                        sortByMetadataKey=key,
+                        orderBy=order_by,
+                        limit=2,
+                        cursor=cursor,
+                    )
+                    result += [m.name for m in resp.models]
+                    if not resp.nextCursor:
+                        return result
+                    cursor = resp.nextCursor
+
+            # Models without the key come last either way.
+            assert sorted_names(bindings.v1OrderBy.ASC) == names
+            assert sorted_names(bindings.v1OrderBy.DESC) == names[2::-1] + names[3:]
+
+            with pytest.raises(errors.APIException):
+                bindings.get_GetModels(
+                    creds[1],
+                    sortBy=bindings.v1GetModelsRequestSortBy.METADATA,
+                    sortByMetadataKey=f"{key}-unknown",
+                )
+        finally:
+            for name in names:
+                bindings.delete_DeleteModel(admin, modelName=name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_creation_quota() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Editor"]),
+                (1, ["Viewer"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        workspace_id = workspaces[0].id
+
+        editor_quota = bindings.get_GetModelCreationQuota(creds[0], workspaceId=workspace_id)
+        assert editor_quota.allowed
+        assert editor_quota.reason == ""
+        if editor_quota.unlimited:
+            assert editor_quota.limit is None
+        else:
+            assert editor_quota.limit is not None
+            assert editor_quota.modelCount < editor_quota.limit
+
+        viewer_quota = bindings.get_GetModelCreationQuota(creds[1], workspaceId=workspace_id)
+        assert not viewer_quota.allowed
+        assert viewer_quota.reason != ""
+
+        # The reported decisions match what creating a model decides.
+        name = get_random_string()
+        bindings.post_PostModel(
+            creds[0], body=bindings.v1PostModelRequest(name=name, workspaceId=workspace_id)
+        )
+        try:
+            with pytest.raises(errors.ForbiddenException):
+                bindings.post_PostModel(
+                    creds[1],
+                    body=bindings.v1PostModelRequest(
+                        name=get_random_string(), workspaceId=workspace_id
+                    ),
+                )
+        finally:
+            bindings.delete_DeleteModel(admin, modelName=name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_post_version_idempotency_key() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Editor"]),
+                (1, ["Viewer"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        m, mv = register_model_version(admin, get_random_string(), workspaces[0].id)
+        other, other_mv = register_model_version(admin, get_random_string(), workspaces[0].id)
+        key = get_random_string()
+        try:
+            assert mv.checkpoint is not None and other_mv.checkpoint is not None
+
+            def post(sess: api.Session, checkpoint_uuid: str) -> bindings.v1ModelVersion:
+                return bindings.post_PostModelVersion(
+                    sess,
+                    body=bindings.v1PostModelVersionRequest(
+                        modelName=m.name,
+                        checkpointUuid=checkpoint_uuid,
+                        idempotencyKey=key,
+                    ),
+                    modelName=m.name,
+                ).modelVersion
+
+            first = post(creds[0], mv.checkpoint.uuid)
+            assert first.version == 2
+            # Retries return the version the first request registered.
+            assert post(creds[0], mv.checkpoint.uuid).id == first.id
+            assert bindings.get_GetModel(admin, modelName=m.name).model.numVersions == 2
+
+            with pytest.raises(errors.APIException) as e:
+                post(creds[0], other_mv.checkpoint.uuid)
+            assert "idempotency key" in str(e.value)
+
+            # Permissions are checked on retries too.
+            with pytest.raises(errors.ForbiddenException):
+                post(creds[1], mv.checkpoint.uuid)
+        finally:
+            bindings.delete_DeleteModel(admin, modelName=m.name)
+            bindings.delete_DeleteModel(admin, modelName=other.name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_labels() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Editor"]),
+                (1, ["Viewer"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        m = bindings.post_PostModel(
+            admin,
+            body=bindings.v1PostModelRequest(
+                name=get_random_string(), workspaceId=workspaces[0].id, labels=["b"]
+            ),
+        ).model
+        try:
+
+            def labels() -> List[str]:
+                return list(bindings.get_GetModel(admin, modelName=m.name).model.labels or [])
+
+            for label in ["a", "c", "a"]:
+                bindings.put_PutModelLabel(creds[0], modelName=m.name, label=label)
+            assert labels() == ["b", "a", "c"]
+
+            with pytest.raises(errors.ForbiddenException):
+                bindings.put_PutModelLabel(creds[1], modelName=m.name, label="d")
+            with pytest.raises(errors.ForbiddenException):
+                bindings.delete_DeleteModelLabel(creds[1], modelName=m.name, label="a")
+            assert labels() == ["b", "a", "c"]
+
+            for label in ["a", "a"]:
+                resp = bindings.delete_DeleteModelLabel(creds[0], modelName=m.name, label=label)
+            assert resp.model.labels == ["b", "c"]
+        finally:
+            bindings.delete_DeleteModel(admin, modelName=m.name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_delete_protected_version() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Editor"]),
+                (1, ["WorkspaceAdmin"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        m, mv = register_model_version(admin, get_random_string(), workspaces[0].id)
+        try:
+            assert mv.checkpoint is not None
+            checkpoint_uuid = mv.checkpoint.uuid
+
+            def post() -> bindings.v1ModelVersion:
+                return bindings.post_PostModelVersion(
+                    admin,
+                    body=bindings.v1PostModelVersionRequest(
+                        checkpointUuid=checkpoint_uuid, modelName=m.name
+                    ),
+                    modelName=m.name,
+                ).modelVersion
+
+            assert post().version == 2
+            bindings.patch_PatchModelVersion(
+                admin,
+                body=bindings.v1PatchModelVersion(labels=["production"]),
+                modelName=m.name,
+                modelVersionNum=1,
+            )
+
+            with pytest.raises(errors.APIException) as e:
+                bindings.delete_DeleteModelVersion(creds[1], modelName=m.name, modelVersionNum=1)
+            assert "production" in str(e.value)
+            with pytest.raises(errors.ForbiddenException):
+                bindings.delete_DeleteModelVersion(
+                    creds[0], modelName=m.name, modelVersionNum=1, force=True
+                )
+            bindings.delete_DeleteModelVersion(
+                creds[1], modelName=m.name, modelVersionNum=1, force=True
+            )
+
+            # Numbers of deleted versions are never given out again.
+            bindings.delete_DeleteModelVersion(admin, modelName=m.name, modelVersionNum=2)
+            assert post().version == 3
+        finally:
+            bindings.delete_DeleteModel(admin, modelName=m.name)
+
+
+@pytest.mark.test_model_registry_rbac
+def test_model_rbac_webhooks() -> None:
+    with test_rbac.create_workspaces_with_users(
+        [
+            [
+                (0, ["Editor"]),
+                (1, ["Viewer"]),
+            ],
+        ]
+    ) as (workspaces, creds):
+        admin = api_utils.admin_session()
+        m, mv = register_model_version(admin, get_random_string(), workspaces[0].id)
+        event = bindings.v1ModelWebhookEvent
+        try:
+            assert mv.checkpoint is not None
+            created = bindings.post_PostModelWebhook(
+                creds[0],
+                body=bindings.v1PostModelWebhookRequest(
+                    modelName=m.name,
+                    url="http://127.0.0.1:1/model-events",
+                    events=[event.VERSION_CREATED],
+                    secret="s3cret",
+                ),
+                modelName=m.name,
+            ).webhook
+            assert created.hasSecret
+            # The secret is write-only.
+            assert "s3cret" not in str(created.to_json())
+            webhooks = bindings.get_GetModelWebhooks(creds[0], modelName=m.name).webhooks
+            assert [w.id for w in webhooks] == [created.id]
+            assert "s3cret" not in str([w.to_json() for w in webhooks])
+
+            with pytest.raises(errors.ForbiddenException):
+                bindings.get_GetModelWebhooks(creds[1], modelName=m.name)
+            with pytest.raises(errors.ForbiddenException):
+                bindings.delete_DeleteModelWebhook(
+                    creds[1], modelName=m.name, webhookId=created.id
+                )
+
+            patched = bindings.patch_PatchModelWebhook(
+                creds[0],
+                body=bindings.v1PatchModelWebhookRequest(
+                    modelName=m.name,
+                    webhookId=created.id,
+                    events=[event.VERSION_CREATED, event.VERSION_PROMOTED],
+                ),
+                modelName=m.name,
+                webhookId=created.id,
+            ).webhook
+            assert patched.hasSecret
+            assert patched.events == [event.VERSION_CREATED, event.VERSION_PROMOTED]
+
+            # Registering a version queues a delivery, which fails and is retried.
+            bindings.post_PostModelVersion(
+                admin,
+                body=bindings.v1PostModelVersionRequest(
+                    checkpointUuid=mv.checkpoint.uuid, modelName=m.name
+                ),
+                modelName=m.name,
+            )
+            deliveries = bindings.get_GetModelWebhookDeliveries(
+                creds[0], modelName=m.name, webhookId=created.id
+            ).deliveries
+            assert [d.event for d in deliveries] == [event.VERSION_CREATED]
+
+            bindings.delete_DeleteModelWebhook(creds[0], modelName=m.name, webhookId=created.id)
+            assert bindings.get_GetModelWebhooks(creds[0], modelName=m.name).webhooks == []
+        finally:
+            bindings.delete_DeleteModel(admin, modelName=m.name)
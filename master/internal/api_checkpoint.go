@@ -100,7 +100,7 @@ func (m *Master) canDoActionOnCheckpointThroughModel(
 		if err != nil {
 			return err
 		}
-		if errCanGetModel = modelauth.AuthZProvider.Get().CanGetModel(
+		if errCanGetModel = modelauth.ForWorkspace(model.WorkspaceId).CanGetModel(
 			ctx, curUser, model, model.WorkspaceId); errCanGetModel == nil {
 			return nil
 		}
@@ -217,11 +217,11 @@ func makeRegisteredCheckpointErrorMessage(
 	modelQuery := internaldb.Bun().
 		NewSelect().
 		Model(&models).
-		Table("models").
-		ColumnExpr("id").
-		Where("id IN (?)", bun.In(modelIDs))
-	if modelQuery, err = modelauth.AuthZProvider.Get().
-		FilterReadableModelsQuery(ctx, *curUser, modelQuery); err != nil {
+		ModelTableExpr("models AS m").
+		ColumnExpr("m.id").
+		Where("m.id IN (?)", bun.In(modelIDs))
+	if modelQuery, err = modelauth.FilterReadableModelsByWorkspace(
+		ctx, *curUser, modelQuery); err != nil {
 		return nil, err
 	}
 	err = modelQuery.Scan(ctx)
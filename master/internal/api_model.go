@@ -3,25 +3,37 @@ package internal
 import (
 	"bytes"
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 
 	"github.com/determined-ai/determined/master/internal/api"
 	"github.com/determined-ai/determined/master/internal/authz"
+	"github.com/determined-ai/determined/master/internal/checkpoints"
+	"github.com/determined-ai/determined/master/internal/config"
 	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/db/bunutils"
 	"github.com/determined-ai/determined/master/internal/grpcutil"
 	modelauth "github.com/determined-ai/determined/master/internal/model"
+	"github.com/determined-ai/determined/master/internal/rbac"
 	"github.com/determined-ai/determined/master/internal/trials"
+	"github.com/determined-ai/determined/master/internal/user"
 	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
 	"github.com/determined-ai/determined/proto/pkg/apiv1"
 	"github.com/determined-ai/determined/proto/pkg/checkpointv1"
 	"github.com/determined-ai/determined/proto/pkg/modelv1"
@@ -50,6 +62,54 @@ func (a *apiServer) ModelFromIdentifier(identifier string) (*modelv1.Model, erro
 	}
 }
 
+// modelWorkspaceFromIdentifier returns the id and the workspace of a model without loading the
+// rest of it.
+func modelWorkspaceFromIdentifier(
+	ctx context.Context, identifier string,
+) (modelID int32, workspaceID int32, err error) {
+	q := db.Bun().NewSelect().
+		Table("models").
+		Column("id", "workspace_id").
+		Where("deleted_at IS NULL")
+	if allNumbers, _ := regexp.MatchString("^\\d+$", identifier); allNumbers {
+		q = q.Where("id = ?", identifier)
+	} else {
+		q = q.Where("name = ?", identifier)
+	}
+	switch err := q.Scan(ctx, &modelID, &workspaceID); {
+	case errors.Is(err, sql.ErrNoRows):
+		return 0, 0, status.Errorf(codes.NotFound, "model %q not found", identifier)
+	case err != nil:
+		return 0, 0, errors.Wrapf(err, "error fetching workspace of model %q", identifier)
+	}
+	return modelID, workspaceID, nil
+}
+
+// checkModelWorkspaceAccess turns away users who cannot see models in the workspace of a model
+// before the model is loaded, with the same error as a missing model so that they cannot tell
+// whether it exists. Users granted access to the model itself are let through, to be authorized
+// by CanGetModel.
+func checkModelWorkspaceAccess(ctx context.Context, curUser model.User, identifier string) error {
+	modelID, workspaceID, err := modelWorkspaceFromIdentifier(ctx, identifier)
+	if err != nil {
+		return err
+	}
+	canAccess, err := modelauth.ForWorkspace(workspaceID).CanAccessModelWorkspace(ctx, curUser,
+		workspaceID)
+	if err != nil {
+		return err
+	} else if canAccess {
+		return nil
+	}
+	switch _, err := db.GetModelAccessGrant(ctx, modelID, curUser.ID); {
+	case errors.Is(err, db.ErrNotFound):
+		return status.Errorf(codes.NotFound, "model %q not found", identifier)
+	case err != nil:
+		return err
+	}
+	return nil
+}
+
 func (a *apiServer) ModelVersionFromID(modelIdentifier string,
 	versionID int32,
 ) (*modelv1.ModelVersion, error) {
@@ -73,73 +133,113 @@ func (a *apiServer) ModelVersionFromID(modelIdentifier string,
 func (a *apiServer) GetModel(
 	ctx context.Context, req *apiv1.GetModelRequest,
 ) (*apiv1.GetModelResponse, error) {
+	curUser, err := modelRegistryUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkModelWorkspaceAccess(ctx, *curUser, req.ModelName); err != nil {
+		return nil, err
+	}
+
 	m, err := a.ModelFromIdentifier(req.ModelName)
 	if err != nil {
 		return nil, err
 	}
+	if err = modelauth.ForWorkspace(m.WorkspaceId).CanGetModel(ctx, *curUser, m,
+		m.WorkspaceId); err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "get", fmt.Sprintf("model %q", m.Name))
+	}
+	if err := limitModelAction(ctx, *curUser, config.ModelRateLimitGet); err != nil {
+		return nil, err
+	}
+	return &apiv1.GetModelResponse{Model: maskModel(ctx, *curUser, m)}, err
+}
+
+// modelRegistryUser returns the user of a model registry request. It fails with
+// modelauth.ErrUnauthenticated instead of returning a nil or zero user.
+func modelRegistryUser(ctx context.Context) (*model.User, error) {
 	curUser, _, err := grpcutil.GetUser(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if err = modelauth.AuthZProvider.Get().CanGetModel(ctx, *curUser, m,
-		m.WorkspaceId); err != nil {
-		return nil, authz.SubIfUnauthorized(err,
-			errors.Errorf("current user %q doesn't have permissions to get model %q",
-				curUser.Username, m.Name))
+	if err := modelauth.RequireUser(curUser); err != nil {
+		return nil, err
+	}
+	return curUser, nil
+}
+
+// limitModelAction fails with a *modelauth.ModelRateLimitError if curUser has taken action, one
+// of the config.ModelRateLimit actions, too often. It must be called once the action is
+// authorized.
+func limitModelAction(ctx context.Context, curUser model.User, action string) error {
+	return modelauth.RateLimiterProvider.Get().Allow(ctx, curUser, action)
+}
+
+// maskModel returns m as curUser may see it, masked by the authz of its workspace.
+func maskModel(ctx context.Context, curUser model.User, m *modelv1.Model) *modelv1.Model {
+	if m == nil {
+		return nil
+	}
+	return modelauth.ForWorkspace(m.WorkspaceId).MaskModelFields(ctx, curUser, m)
+}
+
+// maskModels masks each of models in place of the slice, leaving the models themselves as they
+// are.
+func maskModels(ctx context.Context, curUser model.User, models []*modelv1.Model) {
+	for i, m := range models {
+		models[i] = maskModel(ctx, curUser, m)
 	}
-	return &apiv1.GetModelResponse{Model: m}, err
 }
 
 func (a *apiServer) GetModels(
 	ctx context.Context, req *apiv1.GetModelsRequest,
 ) (*apiv1.GetModelsResponse, error) {
-	resp := &apiv1.GetModelsResponse{}
-	idFilterExpr := req.Id
-	nameFilter := "%" + req.Name + "%"
-	descFilterExpr := "%" + req.Description + "%"
-	archFilterExpr := ""
-	if req.Archived != nil {
-		archFilterExpr = strconv.FormatBool(req.Archived.Value)
-	}
-	userFilterExpr := strings.Join(req.Users, ",")
-	userIds := make([]string, 0, len(req.UserIds))
-	for _, userID := range req.UserIds {
-		userIds = append(userIds, strconv.Itoa(int(userID)))
-	}
-	userIDFilterExpr := strings.Join(userIds, ",")
-	labelFilterExpr := strings.Join(req.Labels, ",")
-	// Construct the ordering expression.
-	sortColMap := map[apiv1.GetModelsRequest_SortBy]string{
-		apiv1.GetModelsRequest_SORT_BY_UNSPECIFIED:       "id",
-		apiv1.GetModelsRequest_SORT_BY_NAME:              "name",
-		apiv1.GetModelsRequest_SORT_BY_DESCRIPTION:       "description",
-		apiv1.GetModelsRequest_SORT_BY_CREATION_TIME:     "creation_time",
-		apiv1.GetModelsRequest_SORT_BY_LAST_UPDATED_TIME: "last_updated_time",
-		apiv1.GetModelsRequest_SORT_BY_NUM_VERSIONS:      "num_versions",
-		apiv1.GetModelsRequest_SORT_BY_WORKSPACE:         "w.name",
-	}
-	orderByMap := map[apiv1.OrderBy]string{
-		apiv1.OrderBy_ORDER_BY_UNSPECIFIED: "ASC",
-		apiv1.OrderBy_ORDER_BY_ASC:         "ASC",
-		apiv1.OrderBy_ORDER_BY_DESC:        "DESC",
-	}
-	orderExpr := ""
-	switch _, ok := sortColMap[req.SortBy]; {
-	case !ok:
-		return nil, fmt.Errorf("unsupported sort by %s", req.SortBy)
-	case sortColMap[req.SortBy] != "id":
-		orderExpr = fmt.Sprintf(
-			"%s %s, id %s",
-			sortColMap[req.SortBy], orderByMap[req.OrderBy], orderByMap[req.OrderBy],
-		)
-	default:
-		orderExpr = fmt.Sprintf("id %s", orderByMap[req.OrderBy])
+	resp := &apiv1.GetModelsResponse{Models: []*modelv1.Model{}}
+	curUser, query, sortKey, err := readableModelsQuery(ctx, req, &resp.Models)
+	if err != nil {
+		return nil, err
 	}
 
-	curUser, _, err := grpcutil.GetUser(ctx)
+	if req.Cursor != nil {
+		err = getModelsPage(ctx, query, req, sortKey, resp)
+	} else if err = query.Scan(ctx); err == nil {
+		err = api.Paginate(&resp.Pagination, &resp.Models, req.Offset, req.Limit)
+	}
 	if err != nil {
 		return nil, err
 	}
+	maskModels(ctx, *curUser, resp.Models)
+	return resp, nil
+}
+
+// readableModelsQuery authorizes the current user to list models, and returns the query of the
+// models selected by req that the user may view, into dest, along with the key they are sorted by.
+func readableModelsQuery(
+	ctx context.Context, req *apiv1.GetModelsRequest, dest *[]*modelv1.Model,
+) (*model.User, *bun.SelectQuery, modelSortKey, error) {
+	query := db.Bun().NewSelect().
+		Model(dest).
+		ModelTableExpr("models AS m").
+		Apply(getModelColumns).
+		Join("LEFT JOIN users AS u ON u.id = m.user_id").
+		Join("LEFT JOIN workspaces AS w ON w.id = m.workspace_id").
+		Where("m.deleted_at IS NULL")
+
+	sortKey, err := modelSortKeyFor(req)
+	if err != nil {
+		return nil, nil, modelSortKey{}, err
+	}
+	if req.SortBy == apiv1.GetModelsRequest_SORT_BY_METADATA {
+		if err := validateModelSortMetadataKey(ctx, req.SortByMetadataKey); err != nil {
+			return nil, nil, modelSortKey{}, err
+		}
+	}
+	query = sortKey.order(query)
+
+	curUser, err := modelRegistryUser(ctx)
+	if err != nil {
+		return nil, nil, modelSortKey{}, err
+	}
 	var workspaceIdsGiven []int32
 	if req.WorkspaceIds != nil {
 		// default is to use workspace ids.
@@ -149,46 +249,445 @@ func (a *apiServer) GetModels(
 		if err := db.Bun().NewSelect().Table("workspaces").Column("id").
 			Where("name in (?)", bun.In(req.WorkspaceNames)).Distinct().
 			Scan(ctx, &workspaceIdsGiven); err != nil {
-			return nil, fmt.Errorf("getting workspace ids from names: %w", err)
+			return nil, nil, modelSortKey{}, fmt.Errorf("getting workspace ids from names: %w", err)
 		}
 	}
-	// function below returns a list of workspaces that have permissions
-	// filtered according to user given workspaces.
-	// if global permissions and no filter list given by user then it's an empty list.
-	workspaceIdsWithPermsAndFilterList, err := modelauth.AuthZProvider.Get().
-		CanGetModels(ctx, *curUser, workspaceIdsGiven)
+	// Models are authorized by the ModelAuthZ of their workspace, so each group of workspaces
+	// sharing one is checked and filtered on its own.
+	groups, err := modelauth.GroupWorkspacesByAuthZ(ctx)
 	if err != nil {
-		return nil, authz.SubIfUnauthorized(err, errors.Errorf(
-			"current user doesn't have view permissions in related workspaces"))
+		return nil, nil, modelSortKey{}, err
+	}
+	if workspaceIdsGiven != nil {
+		query = query.Where("m.workspace_id IN (?)", bun.In(workspaceIdsGiven))
+	}
+	var readable []*bun.SelectQuery
+	var denied error
+	for _, g := range groups {
+		groupQuery := query
+		if len(groups) > 1 {
+			groupQuery = g.ApplyWorkspaces(db.Bun().NewSelect().
+				TableExpr("models AS m").
+				Column("m.id").
+				Join("LEFT JOIN users AS u ON u.id = m.user_id").
+				Where("m.deleted_at IS NULL"))
+		}
+		groupQuery, err = readableModelsInGroup(ctx, *curUser, g, groupQuery, req, workspaceIdsGiven)
+		switch {
+		case errors.Is(err, errNoWorkspacesInGroup):
+			continue
+		case authz.IsPermissionDenied(err) && workspaceIdsGiven == nil && len(groups) > 1:
+			// Listing every workspace only needs permission in some of them.
+			denied = err
+			continue
+		case err != nil:
+			return nil, nil, modelSortKey{}, modelauth.PermissionDenied(err, *curUser, "get",
+				"models in related workspaces")
+		}
+		if len(groups) == 1 {
+			query = groupQuery
+		}
+		readable = append(readable, groupQuery)
 	}
-	var workspaceIds []string
-	var workspaceIdsWithPermsAndFilter string
-	if workspaceIdsWithPermsAndFilterList == nil {
-		workspaceIdsWithPermsAndFilter = ""
-	} else {
-		for _, wID := range workspaceIdsWithPermsAndFilterList {
-			workspaceIds = append(workspaceIds, strconv.Itoa(int(wID)))
-		}
-		workspaceIdsWithPermsAndFilter = strings.Join(workspaceIds, ",")
-	}
-
-	err = a.m.db.QueryProtof(
-		"get_models",
-		[]interface{}{orderExpr},
-		&resp.Models,
-		idFilterExpr,
-		archFilterExpr,
-		userFilterExpr,
-		userIDFilterExpr,
-		labelFilterExpr,
-		nameFilter,
-		descFilterExpr,
-		workspaceIdsWithPermsAndFilter,
-	)
+	if len(readable) == 0 && denied != nil {
+		return nil, nil, modelSortKey{}, modelauth.PermissionDenied(denied, *curUser, "get",
+			"models in related workspaces")
+	}
+	if err := limitModelAction(ctx, *curUser, config.ModelRateLimitGet); err != nil {
+		return nil, nil, modelSortKey{}, err
+	}
+	if len(groups) > 1 {
+		query = modelauth.WhereModelIn(query, readable)
+	}
+	return curUser, query, sortKey, nil
+}
+
+var errNoWorkspacesInGroup = errors.New("none of the given workspaces are in the group")
+
+// readableModelsInGroup authorizes curUser to list the models in the workspaces of g, and
+// restricts query to the ones matching req that they may view in the workspaces the authz allows.
+// workspaceIDs are the workspaces asked for, or nil for all of them; errNoWorkspacesInGroup is
+// returned if none are in g.
+func readableModelsInGroup(
+	ctx context.Context, curUser model.User, g modelauth.WorkspaceAuthZGroup,
+	query *bun.SelectQuery, req *apiv1.GetModelsRequest, workspaceIDs []int32,
+) (*bun.SelectQuery, error) {
+	if workspaceIDs != nil {
+		if workspaceIDs = g.Filter(workspaceIDs); len(workspaceIDs) == 0 {
+			return nil, errNoWorkspacesInGroup
+		}
+	}
+	allowedIDs, labels, err := g.AuthZ.CanGetModelsByLabel(ctx, curUser, workspaceIDs, req.Labels)
+	if err != nil {
+		return nil, err
+	}
+	// nil allows every workspace the query already covers.
+	if allowedIDs != nil && len(allowedIDs) == 0 {
+		query = query.Where("false")
+	} else if allowedIDs != nil {
+		query = query.Where("m.workspace_id IN (?)", bun.In(allowedIDs))
+	}
+	query, err = applyModelFilters(query, req, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	// Push model-level authorization down into the query. Listing fails if it can't be, since
+	// the workspaces allowed above would also show models the user may not view.
+	return g.AuthZ.FilterReadableModelsQuery(ctx, curUser, query)
+}
+
+func (a *apiServer) CountModels(
+	ctx context.Context, req *apiv1.CountModelsRequest,
+) (*apiv1.CountModelsResponse, error) {
+	curUser, err := modelRegistryUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Authorized like GetModels for the same workspace, so that the count matches its total.
+	_, labels, err := modelauth.ForWorkspace(req.WorkspaceId).
+		CanGetModelsByLabel(ctx, *curUser, []int32{req.WorkspaceId}, req.Labels)
+	if err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "get",
+			fmt.Sprintf("models in workspace %d", req.WorkspaceId))
+	}
+
+	query := db.Bun().NewSelect().
+		TableExpr("models AS m").
+		Join("LEFT JOIN users AS u ON u.id = m.user_id").
+		Where("m.deleted_at IS NULL")
+	query, err = applyModelFilters(query, &apiv1.GetModelsRequest{
+		Name:            req.Name,
+		Description:     req.Description,
+		Labels:          req.Labels,
+		LabelMatch:      req.LabelMatch,
+		Archived:        req.Archived,
+		IncludeArchived: req.IncludeArchived,
+		Users:           req.Users,
+		UserIds:         req.UserIds,
+		Tags:            req.Tags,
+		Search:          req.Search,
+	}, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := modelauth.ForWorkspace(req.WorkspaceId).
+		CountModels(ctx, *curUser, req.WorkspaceId, query)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error counting models in workspace %d", req.WorkspaceId)
+	}
+	return &apiv1.CountModelsResponse{Count: int32(count)}, nil
+}
+
+func (a *apiServer) GetModelCreationQuota(
+	ctx context.Context, req *apiv1.GetModelCreationQuotaRequest,
+) (*apiv1.GetModelCreationQuotaResponse, error) {
+	curUser, err := modelRegistryUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = a.GetWorkspaceByID(ctx, req.WorkspaceId, *curUser, false); err != nil {
+		return nil, err
+	}
+
+	quota, err := modelauth.ForWorkspace(req.WorkspaceId).
+		GetModelCreationQuota(ctx, *curUser, req.WorkspaceId)
+	if err != nil {
+		return nil, errors.Wrapf(err,
+			"error getting the model creation quota of workspace %d", req.WorkspaceId)
+	}
+	resp := &apiv1.GetModelCreationQuotaResponse{
+		Allowed:    quota.Allowed,
+		Reason:     quota.Reason,
+		Unlimited:  quota.Limit == nil,
+		ModelCount: int32(quota.Count),
+	}
+	if quota.Limit != nil {
+		resp.Limit = ptrs.Ptr(int32(*quota.Limit))
+	}
+	return resp, nil
+}
+
+// applyModelFilters restricts query, which selects from models aliased as m joined with their
+// users as u, to the models matching the filters of req. labels is used in place of req.Labels,
+// as returned by CanGetModelsByLabel.
+func applyModelFilters(
+	query *bun.SelectQuery, req *apiv1.GetModelsRequest, labels []string,
+) (*bun.SelectQuery, error) {
+	if req.Id != 0 {
+		query = query.Where("m.id = ?", req.Id)
+	}
+	if req.Archived != nil {
+		query = query.Where("m.archived = ?", req.Archived.Value)
+	} else if !req.IncludeArchived {
+		query = query.Where("m.archived = false")
+	}
+	if len(req.Users) > 0 {
+		query = query.Where("u.username IN (?)", bun.In(req.Users))
+	}
+	if len(req.UserIds) > 0 {
+		query = query.Where("m.user_id IN (?)", bun.In(req.UserIds))
+	}
+	if req.Name != "" {
+		query = query.Where("m.name ILIKE ?", "%"+req.Name+"%")
+	}
+	if req.Description != "" {
+		query = query.Where("m.description ILIKE ?", "%"+req.Description+"%")
+	}
+	if search := strings.TrimSpace(req.Search); search != "" {
+		// Matches the expression of the ix_models_search index.
+		query = query.Where("model_search_vector(m.name, m.description, m.labels) @@ "+
+			"plainto_tsquery('simple', ?)", search)
+	}
+	// Both operators can use the GIN index on models.labels.
+	if len(labels) > 0 {
+		if req.LabelMatch == apiv1.GetModelsRequest_LABEL_MATCH_ALL {
+			query = query.Where("m.labels @> ?", pgdialect.Array(labels))
+		} else {
+			query = query.Where("m.labels && ?", pgdialect.Array(labels))
+		}
+	}
+	if len(req.Tags) > 0 {
+		tags, err := parseModelTagFilters(req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		tagsJSON, err := json.Marshal(tags)
+		if err != nil {
+			return nil, err
+		}
+		// Containment can use the GIN index on models.tags.
+		query = query.Where("m.tags @> ?::jsonb", string(tagsJSON))
+	}
+	return query, nil
+}
+
+// canGetModelsByIDs checks which of modelIDs curUser may view, asking the ModelAuthZ of each
+// model's workspace. Models that don't exist are left to the default ModelAuthZ.
+func canGetModelsByIDs(
+	ctx context.Context, curUser model.User, modelIDs []int32, workspaceID int32,
+) (map[int32]bool, error) {
+	groups, err := modelauth.GroupWorkspacesByAuthZ(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 1 {
+		return groups[0].AuthZ.CanGetModelsByIDs(ctx, curUser, modelIDs, workspaceID)
+	}
+
+	var models []struct {
+		ID          int32
+		WorkspaceID int32
+	}
+	if len(modelIDs) > 0 {
+		if err := db.Bun().NewSelect().
+			Table("models").
+			Column("id", "workspace_id").
+			Where("id IN (?)", bun.In(modelIDs)).
+			Scan(ctx, &models); err != nil {
+			return nil, errors.Wrap(err, "error getting the workspaces of models")
+		}
+	}
+	workspaceOf := make(map[int32]int32, len(models))
+	for _, m := range models {
+		workspaceOf[m.ID] = m.WorkspaceID
+	}
+
+	allowed := make(map[int32]bool, len(modelIDs))
+	for i, g := range groups {
+		var ids []int32
+		for _, id := range modelIDs {
+			if wID, ok := workspaceOf[id]; (ok && g.Contains(wID)) || (!ok && i == 0) {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		groupAllowed, err := g.AuthZ.CanGetModelsByIDs(ctx, curUser, ids, workspaceID)
+		if err != nil {
+			return nil, err
+		}
+		for id, ok := range groupAllowed {
+			allowed[id] = ok
+		}
+	}
+	return allowed, nil
+}
+
+func (a *apiServer) GetModelsByIds(
+	ctx context.Context, req *apiv1.GetModelsByIdsRequest,
+) (*apiv1.GetModelsByIdsResponse, error) {
+	curUser, err := modelRegistryUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := canGetModelsByIDs(ctx, *curUser, req.ModelIds, req.GetWorkspaceId())
+	if err != nil {
+		return nil, err
+	}
+
+	var allowedIDs []int32
+	for id, ok := range allowed {
+		if ok {
+			allowedIDs = append(allowedIDs, id)
+		}
+	}
+	var models []*modelv1.Model
+	if len(allowedIDs) > 0 {
+		query := db.Bun().NewSelect().
+			Model(&models).
+			ModelTableExpr("models AS m").
+			Apply(getModelColumns).
+			Join("LEFT JOIN users AS u ON u.id = m.user_id").
+			Where("m.id IN (?)", bun.In(allowedIDs)).
+			Where("m.deleted_at IS NULL")
+		if req.WorkspaceId != nil {
+			query = query.Where("m.workspace_id = ?", *req.WorkspaceId)
+		}
+		if err := query.Scan(ctx); err != nil {
+			return nil, errors.Wrap(err, "error getting models by ids")
+		}
+	}
+
+	byID := make(map[int32]*modelv1.Model, len(models))
+	for _, m := range models {
+		byID[m.Id] = m
+	}
+	resp := &apiv1.GetModelsByIdsResponse{
+		Models:          []*modelv1.Model{},
+		MissingModelIds: []int32{},
+	}
+	seen := make(map[int32]bool, len(req.ModelIds))
+	for _, id := range req.ModelIds {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if m, ok := byID[id]; ok {
+			resp.Models = append(resp.Models, m)
+		} else {
+			resp.MissingModelIds = append(resp.MissingModelIds, id)
+		}
+	}
+	maskModels(ctx, *curUser, resp.Models)
+	return resp, nil
+}
+
+func (a *apiServer) CheckModelAuthZ(
+	ctx context.Context, req *apiv1.CheckModelAuthZRequest,
+) (*apiv1.CheckModelAuthZResponse, error) {
+	curUser, err := modelRegistryUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := rbac.AuthZProvider.Get().CanGetUserRoles(ctx, *curUser, req.UserId); err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "check model authorization of",
+			fmt.Sprintf("user %d", req.UserId))
+	}
+	fullUser, err := user.ByID(ctx, model.UserID(req.UserId))
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, api.NotFoundErrs("user", strconv.Itoa(int(req.UserId)), true)
+	} else if err != nil {
+		return nil, err
+	}
+	targetUser := fullUser.ToUser()
+
+	action := strings.ToLower(strings.TrimPrefix(req.Action.String(), "ACTION_"))
+	var m *modelv1.Model
+	if req.Action != apiv1.CheckModelAuthZRequest_ACTION_CREATE {
+		if req.ModelId == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "model_id is required to check %s",
+				action)
+		}
+		if m, err = a.ModelFromIdentifier(strconv.Itoa(int(*req.ModelId))); err != nil {
+			return nil, err
+		}
+	}
+	if req.WorkspaceId == nil && (req.Action == apiv1.CheckModelAuthZRequest_ACTION_CREATE ||
+		req.Action == apiv1.CheckModelAuthZRequest_ACTION_MOVE) {
+		return nil, status.Errorf(codes.InvalidArgument, "workspace_id is required to check %s",
+			action)
+	}
+
+	// Checks may read with locks, so run them in a transaction that is always rolled back.
+	tx, err := db.Bun().BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
-	return resp, api.Paginate(&resp.Pagination, &resp.Models, req.Offset, req.Limit)
+	defer func() {
+		if err := tx.Rollback(); err != nil {
+			log.WithError(err).Error("error rolling back model authz check")
+		}
+	}()
+
+	// Creation is authorized by the workspace the model would be created in.
+	modelAuthZ := modelauth.AuthZProvider.Get()
+	if m != nil {
+		modelAuthZ = modelauth.ForWorkspace(m.WorkspaceId)
+	} else if req.WorkspaceId != nil {
+		modelAuthZ = modelauth.ForWorkspace(*req.WorkspaceId)
+	}
+	switch req.Action {
+	case apiv1.CheckModelAuthZRequest_ACTION_GET:
+		allowed, reason, err := modelAuthZ.ExplainGetModel(ctx, targetUser, m, m.WorkspaceId)
+		if err != nil {
+			return nil, err
+		}
+		resp := &apiv1.CheckModelAuthZResponse{Allowed: allowed, Reason: reason}
+		if !allowed {
+			resp.Error = reason
+		}
+		return resp, nil
+	case apiv1.CheckModelAuthZRequest_ACTION_EDIT:
+		err = modelAuthZ.CanEditModel(ctx, targetUser, m, m.WorkspaceId)
+	case apiv1.CheckModelAuthZRequest_ACTION_CREATE:
+		err = modelAuthZ.CanCreateModel(ctx, tx, targetUser, *req.WorkspaceId)
+	case apiv1.CheckModelAuthZRequest_ACTION_DELETE:
+		err = modelAuthZ.CanDeleteModel(ctx, targetUser, m, m.WorkspaceId)
+	case apiv1.CheckModelAuthZRequest_ACTION_MOVE:
+		err = canMoveModel(ctx, tx, targetUser, m, *req.WorkspaceId)
+	case apiv1.CheckModelAuthZRequest_ACTION_ARCHIVE:
+		err = modelAuthZ.CanArchiveModel(ctx, targetUser, m, m.WorkspaceId)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported action %s", req.Action)
+	}
+
+	// A workspace at its model quota denies creates just like a missing permission, and so does a
+	// model the user cannot see.
+	if authz.IsPermissionDenied(err) || status.Code(err) == codes.ResourceExhausted ||
+		status.Code(err) == codes.NotFound {
+		return &apiv1.CheckModelAuthZResponse{Allowed: false, Error: err.Error()}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &apiv1.CheckModelAuthZResponse{Allowed: true}, nil
+}
+
+func getModelColumns(q *bun.SelectQuery) *bun.SelectQuery {
+	return q.
+		Column("m.id").
+		Column("m.name").
+		Column("m.description").
+		Column("m.notes").
+		Column("m.metadata").
+		ColumnExpr("proto_time(m.creation_time) AS creation_time").
+		ColumnExpr("proto_time(m.last_updated_time) AS last_updated_time").
+		ColumnExpr("array_to_json(m.labels) AS labels").
+		Column("u.username").
+		Column("m.user_id").
+		Column("m.owner_id").
+		Column("m.tags").
+		Column("m.version").
+		Column("m.max_versions").
+		Column("m.prune_versions").
+		ColumnExpr(bunutils.ProtoStateDBCaseString(modelv1.ModelVisibility_value, "m.visibility",
+			"visibility", "MODEL_VISIBILITY_")).
+		Column("m.workspace_id").
+		Column("m.archived").
+		ColumnExpr("(SELECT COUNT(*) FROM model_versions AS mv WHERE mv.model_id = m.id) " +
+			"AS num_versions")
 }
 
 func (a *apiServer) GetModelLabels(
@@ -199,18 +698,19 @@ func (a *apiServer) GetModelLabels(
 	modelQuery := db.Bun().NewSelect().
 		ModelTableExpr("models as m").
 		Column("m.id").
-		ColumnExpr("UNNEST(m.labels) AS label")
+		ColumnExpr("UNNEST(m.labels) AS label").
+		Where("m.deleted_at IS NULL")
 
 	if req.WorkspaceId != nil && int(*req.WorkspaceId) > 0 {
 		modelQuery = modelQuery.Where("workspace_id = ?", req.WorkspaceId)
 	}
 
-	curUser, _, err := grpcutil.GetUser(ctx)
+	curUser, err := modelRegistryUser(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if modelQuery, err = modelauth.AuthZProvider.Get().
-		FilterReadableModelsQuery(ctx, *curUser, modelQuery); err != nil {
+	if modelQuery, err = modelauth.FilterReadableModelsByWorkspace(
+		ctx, *curUser, modelQuery); err != nil {
 		return nil, err
 	}
 
@@ -229,30 +729,71 @@ func (a *apiServer) GetModelLabels(
 	return &resp, errors.Wrapf(err, "error getting model labels")
 }
 
-func (a *apiServer) clearModelName(ctx context.Context, modelName string) error {
-	if len(strings.ReplaceAll(modelName, " ", "")) == 0 {
-		return status.Errorf(codes.InvalidArgument, "model names cannot be blank")
-	}
-	if strings.Contains(modelName, "  ") {
-		return status.Errorf(codes.InvalidArgument, "model names cannot have excessive spacing")
-	}
-	if strings.Contains(modelName, "/") || strings.Contains(modelName, "\\") {
-		return status.Errorf(codes.InvalidArgument, "model names cannot have slashes")
+// checkModelNameAvailable returns an AlreadyExists error identifying the conflicting model and
+// its creator if a model other than excludeID already has modelName, compared case-insensitively.
+// Names that only conflict after Unicode normalization are rejected with a *ModelNameError.
+// Model names identify models in the API, so they are checked across all workspaces. A conflicting
+// model created after the check still fails the insert, see modelNameConflictError.
+func (a *apiServer) checkModelNameAvailable(
+	ctx context.Context, idb bun.IDB, modelName string, excludeID int32,
+) error {
+	var conflict struct {
+		ID       int32
+		Name     string
+		Username string
+		Deleted  bool
+	}
+	err := idb.NewSelect().
+		TableExpr("models AS m").
+		Join("LEFT JOIN users AS u ON u.id = m.user_id").
+		ColumnExpr("m.id").
+		ColumnExpr("m.name").
+		ColumnExpr("u.username").
+		ColumnExpr("m.deleted_at IS NOT NULL AS deleted").
+		Where("m.normalized_name = ?", model.NormalizeModelName(modelName)).
+		Where("m.id != ?", excludeID).
+		Scan(ctx, &conflict)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "error checking availability of model name %q", modelName)
+	}
+
+	switch {
+	case strings.EqualFold(conflict.Name, modelName) && conflict.Deleted:
+		// Deleted models keep their names until they are purged so that they can be restored.
+		return status.Errorf(codes.AlreadyExists,
+			"model name %q conflicts with deleted model %d created by %q, "+
+				"which can be restored until it is purged (names are case-insensitive)",
+			modelName, conflict.ID, conflict.Username)
+	case strings.EqualFold(conflict.Name, modelName):
+		return status.Errorf(codes.AlreadyExists,
+			"model name %q conflicts with model %d created by %q (names are case-insensitive)",
+			modelName, conflict.ID, conflict.Username)
+	default:
+		return modelauth.NewModelNameError(modelName, modelauth.NormalizedNameConflictRule,
+			fmt.Sprintf("model names must differ from %q (model %d) after Unicode normalization",
+				conflict.Name, conflict.ID))
 	}
-	re := regexp.MustCompile(`^\d+$`)
-	if len(re.FindAllString(modelName, 1)) > 0 {
-		return status.Errorf(codes.InvalidArgument, "model names cannot be only numbers")
+}
+
+// normalizedModelNameIndex is the unique index on the normalized names of models.
+const normalizedModelNameIndex = "ix_models_normalized_name"
+
+// modelNameConflictError returns the error to report for err, a unique violation from creating
+// or renaming a model to name, which happens when a conflicting model was created concurrently.
+func modelNameConflictError(name string, err error) error {
+	if strings.Contains(err.Error(), normalizedModelNameIndex) {
+		return modelauth.NewModelNameError(name, modelauth.NormalizedNameConflictRule,
+			"model names must differ from the names of other models after Unicode normalization "+
+				"and ignoring case")
 	}
-	return nil
+	return status.Errorf(codes.AlreadyExists, "avoid names equal to other models (case-insensitive)")
 }
 
 func (a *apiServer) PostModel(
 	ctx context.Context, req *apiv1.PostModelRequest,
 ) (*apiv1.PostModelResponse, error) {
-	if err := a.clearModelName(ctx, req.Name); err != nil {
-		return nil, err
-	}
-
 	b, err := protojson.Marshal(req.Metadata)
 	if err != nil {
 		return nil, errors.Wrap(err, "error marshaling model.Metadata")
@@ -273,27 +814,59 @@ func (a *apiServer) PostModel(
 		}
 		workspaceID = int(w.Id)
 	}
+	if err := modelauth.NamePolicyProvider.Get().Validate(req.Name, int32(workspaceID)); err != nil {
+		return nil, err
+	}
 
-	curUser, _, err := grpcutil.GetUser(ctx)
+	curUser, err := modelRegistryUser(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if err := modelauth.AuthZProvider.Get().CanCreateModel(ctx, *curUser,
-		int32(workspaceID)); err != nil {
+	reqLabels := strings.Join(req.Labels, ",")
+	var m *modelv1.Model
+	// Authorize within the insert so that a workspace's model quota holds under concurrent creates.
+	err = db.Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := modelauth.ForWorkspace(int32(workspaceID)).CanCreateModel(ctx, tx, *curUser,
+			int32(workspaceID)); err != nil {
+			return modelauth.PermissionDenied(err, *curUser, "create",
+				fmt.Sprintf("models in workspace %d", workspaceID))
+		}
+		if err := limitModelAction(ctx, *curUser, config.ModelRateLimitCreate); err != nil {
+			return err
+		}
+		subject := fmt.Sprintf("model %q", req.Name)
+		if err := checkModelMetadataSize(subject, b); err != nil {
+			return err
+		}
+		if err := checkModelLabelCount(subject, len(req.Labels)); err != nil {
+			return err
+		}
+		if err := a.checkModelNameAvailable(ctx, tx, req.Name, 0); err != nil {
+			return err
+		}
+		var insertErr error
+		m, insertErr = db.InsertModelTx(
+			ctx, tx, req.Name, req.Description, b,
+			reqLabels, req.Notes, model.UserID(user.User.Id), workspaceID, req.Visibility,
+		)
+		return errors.Wrapf(insertErr, "error creating model %q in database", req.Name)
+	})
+	if err != nil && strings.Contains(err.Error(), db.CodeUniqueViolation) {
+		return nil, modelNameConflictError(req.Name, err)
+	} else if err != nil {
 		return nil, err
 	}
-	reqLabels := strings.Join(req.Labels, ",")
-	m, err := db.InsertModel(
-		ctx, req.Name, req.Description, b,
-		reqLabels, req.Notes, model.UserID(user.User.Id), workspaceID,
-	)
+	modelauth.InvalidateCanGetModelsCache()
+	notifyModelEvent("model creation", modelauth.NotifierProvider.Get().ModelCreated(ctx, m))
+	return &apiv1.PostModelResponse{Model: maskModel(ctx, *curUser, m)}, nil
+}
 
-	if err != nil && strings.Contains(err.Error(), db.CodeUniqueViolation) {
-		return nil,
-			status.Errorf(codes.AlreadyExists, "avoid names equal to other models (case-insensitive)")
+// notifyModelEvent logs, rather than returns, a notifier error since the change it reports
+// has already been committed.
+func notifyModelEvent(event string, err error) {
+	if err != nil {
+		log.WithError(err).Warnf("failed to notify of %s", event)
 	}
-	return &apiv1.PostModelResponse{Model: m},
-		errors.Wrapf(err, "error creating model %q in database", req.Name)
 }
 
 func (a *apiServer) PatchModel(
@@ -305,24 +878,54 @@ func (a *apiServer) PatchModel(
 	}
 
 	if currModel.Archived {
-		return nil, errors.Errorf("model %q is archived and cannot have attributes updated",
-			currModel.Name)
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"model %q is archived and cannot have attributes updated", currModel.Name)
 	}
 
-	curUser, _, err := grpcutil.GetUser(ctx)
+	curUser, err := modelRegistryUser(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if err := modelauth.AuthZProvider.Get().CanEditModel(ctx, *curUser, currModel,
-		currModel.WorkspaceId); err != nil {
+	// Metadata is authorized on its own so that it can be curated by users who may not otherwise
+	// edit the model.
+	metadataOnly := req.Model.Name == nil && req.Model.Description == nil &&
+		req.Model.Notes == nil && req.Model.Labels == nil &&
+		req.Model.WorkspaceId == nil && req.Model.WorkspaceName == nil &&
+		req.Model.Visibility == nil && req.Model.MaxVersions == nil &&
+		req.Model.PruneVersions == nil
+	if !metadataOnly || req.Model.Metadata == nil {
+		if err := modelauth.ForWorkspace(currModel.WorkspaceId).CanEditModel(ctx, *curUser, currModel,
+			currModel.WorkspaceId); err != nil {
+			return nil, modelauth.PermissionDenied(err, *curUser, "edit",
+				fmt.Sprintf("model %q", currModel.Name))
+		}
+	}
+	if req.Model.Metadata != nil {
+		if err := modelauth.ForWorkspace(currModel.WorkspaceId).
+			CanEditModelMetadata(ctx, *curUser, currModel, currModel.WorkspaceId); err != nil {
+			return nil, modelauth.PermissionDenied(err, *curUser, "edit metadata of",
+				fmt.Sprintf("model %q", currModel.Name))
+		}
+	}
+	if err := limitModelAction(ctx, *curUser, config.ModelRateLimitEdit); err != nil {
 		return nil, err
 	}
 
+	// Checked here to fail fast, and again by the update in case of a concurrent edit.
+	if v := req.Model.ExpectedVersion; v != nil && *v != currModel.Version {
+		return nil, modelVersionConflict(currModel.Name, *v, currModel.Version)
+	}
+
 	madeChanges := false
 	if req.Model.Name != nil && req.Model.Name.Value != currModel.Name {
 		log.Infof("model (%v) name changing from %q to %q",
 			currModel.Id, currModel.Name, req.Model.Name.Value)
-		if err = a.clearModelName(ctx, req.Model.Name.Value); err != nil {
+		if err = modelauth.NamePolicyProvider.Get().Validate(req.Model.Name.Value,
+			currModel.WorkspaceId); err != nil {
+			return nil, err
+		}
+		if err = a.checkModelNameAvailable(ctx, db.Bun(), req.Model.Name.Value,
+			currModel.Id); err != nil {
 			return nil, err
 		}
 		madeChanges = true
@@ -352,6 +955,10 @@ func (a *apiServer) PatchModel(
 		if err2 != nil {
 			return nil, errors.Wrap(err2, "error marshaling request model metadata")
 		}
+		if err := checkModelMetadataSize(fmt.Sprintf("model %q", currModel.Name),
+			newMeta); err != nil {
+			return nil, err
+		}
 
 		if !bytes.Equal(currMeta, newMeta) {
 			log.Infof("model %q metadata changing from %q to %q",
@@ -379,6 +986,10 @@ func (a *apiServer) PatchModel(
 			reqLabelSet[label] = struct{}{}
 			reqLabelList = append(reqLabelList, label)
 		}
+		if err := checkModelLabelCount(fmt.Sprintf("model %q", currModel.Name),
+			len(reqLabelList)); err != nil {
+			return nil, err
+		}
 		reqLabels := strings.Join(reqLabelList, ",")
 		if currLabels != reqLabels {
 			log.Infof("model %q labels changing from %q to %q",
@@ -406,32 +1017,82 @@ func (a *apiServer) PatchModel(
 		newWorkspaceID := w.Id
 		if currWorkspaceID != newWorkspaceID {
 			// check if user has permissions in new workspace.
-			if err := modelauth.AuthZProvider.Get().CanEditModel(ctx, *curUser, currModel,
+			if err := modelauth.ForWorkspace(newWorkspaceID).CanEditModel(ctx, *curUser, currModel,
 				newWorkspaceID); err != nil {
-				return nil, err
+				return nil, modelauth.PermissionDenied(err, *curUser, "edit",
+					fmt.Sprintf("models in workspace %d", newWorkspaceID))
 			}
 			currWorkspaceID = newWorkspaceID
 			madeChanges = true
 		}
 	}
 
+	currVisibility := db.ModelVisibilityToDB(currModel.Visibility)
+	if req.Model.Visibility != nil {
+		if reqVisibility := db.ModelVisibilityToDB(*req.Model.Visibility); reqVisibility != currVisibility {
+			log.Infof("model %q visibility changing from %q to %q",
+				currModel.Name, currVisibility, reqVisibility)
+			madeChanges = true
+			currVisibility = reqVisibility
+		}
+	}
+
+	currMaxVersions := currModel.MaxVersions
+	if req.Model.MaxVersions != nil && *req.Model.MaxVersions != currMaxVersions {
+		if *req.Model.MaxVersions < 0 {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"max_versions of model %q must be at least 0", currModel.Name)
+		}
+		log.Infof("model %q max versions changing from %d to %d",
+			currModel.Name, currMaxVersions, *req.Model.MaxVersions)
+		madeChanges = true
+		currMaxVersions = *req.Model.MaxVersions
+	}
+
+	currPruneVersions := currModel.PruneVersions
+	if req.Model.PruneVersions != nil && *req.Model.PruneVersions != currPruneVersions {
+		log.Infof("model %q version pruning changing from %t to %t",
+			currModel.Name, currPruneVersions, *req.Model.PruneVersions)
+		madeChanges = true
+		currPruneVersions = *req.Model.PruneVersions
+	}
+
 	if !madeChanges {
-		return &apiv1.PatchModelResponse{Model: currModel}, nil
+		return &apiv1.PatchModelResponse{Model: maskModel(ctx, *curUser, currModel)}, nil
 	}
 
 	finalModel := &modelv1.Model{}
 	err = a.m.db.QueryProto(
 		"update_model", finalModel, currModel.Id, currModel.Name, currModel.Description,
-		currModel.Notes, currMeta, currLabels, currWorkspaceID)
-
+		currModel.Notes, currMeta, currLabels, currWorkspaceID, currVisibility,
+		req.Model.ExpectedVersion, currMaxVersions, currPruneVersions,
+		model.NormalizeModelName(currModel.Name))
+
+	if errors.Is(err, db.ErrNotFound) && req.Model.ExpectedVersion != nil {
+		var version int32
+		if err := db.Bun().NewSelect().Table("models").Column("version").
+			Where("id = ?", currModel.Id).Scan(ctx, &version); err != nil {
+			return nil, errors.Wrapf(err, "error getting version of model %q", currModel.Name)
+		}
+		return nil, modelVersionConflict(currModel.Name, *req.Model.ExpectedVersion, version)
+	}
 	if err != nil && strings.Contains(err.Error(), db.CodeUniqueViolation) {
-		return nil,
-			status.Errorf(codes.AlreadyExists, "avoid names equal to other models (case-insensitive)")
+		return nil, modelNameConflictError(currModel.Name, err)
+	}
+	if currWorkspaceID != currModel.WorkspaceId {
+		modelauth.InvalidateCanGetModelsCache()
 	}
-	return &apiv1.PatchModelResponse{Model: finalModel},
+	return &apiv1.PatchModelResponse{Model: maskModel(ctx, *curUser, finalModel)},
 		errors.Wrapf(err, "error updating model %q in database", currModel.Name)
 }
 
+// modelVersionConflict is returned when a model was edited since the version an edit is based on.
+// It includes the current version so that clients can refetch the model and retry.
+func modelVersionConflict(name string, expected, current int32) error {
+	return status.Errorf(codes.FailedPrecondition,
+		"model %q was edited since version %d; its current version is %d", name, expected, current)
+}
+
 func (a *apiServer) ArchiveModel(
 	ctx context.Context, req *apiv1.ArchiveModelRequest,
 ) (*apiv1.ArchiveModelResponse, error) {
@@ -440,12 +1101,16 @@ func (a *apiServer) ArchiveModel(
 		return nil, err
 	}
 
-	curUser, _, err := grpcutil.GetUser(ctx)
+	curUser, err := modelRegistryUser(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if err := modelauth.AuthZProvider.Get().CanEditModel(ctx, *curUser, currModel,
+	if err := modelauth.ForWorkspace(currModel.WorkspaceId).CanArchiveModel(ctx, *curUser, currModel,
 		currModel.WorkspaceId); err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "archive",
+			fmt.Sprintf("model %q", currModel.Name))
+	}
+	if err := limitModelAction(ctx, *curUser, config.ModelRateLimitEdit); err != nil {
 		return nil, err
 	}
 
@@ -457,6 +1122,12 @@ func (a *apiServer) ArchiveModel(
 			req.ModelName)
 	}
 
+	modelauth.InvalidateCanGetModelsCache()
+	if err == nil {
+		currModel.Archived = true
+		notifyModelEvent("model archival",
+			modelauth.NotifierProvider.Get().ModelArchived(ctx, currModel))
+	}
 	return &apiv1.ArchiveModelResponse{},
 		errors.Wrapf(err, "error archiving model %q", req.ModelName)
 }
@@ -469,12 +1140,16 @@ func (a *apiServer) UnarchiveModel(
 		return nil, err
 	}
 
-	curUser, _, err := grpcutil.GetUser(ctx)
+	curUser, err := modelRegistryUser(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if err := modelauth.AuthZProvider.Get().CanEditModel(ctx, *curUser, currModel,
+	if err := modelauth.ForWorkspace(currModel.WorkspaceId).CanUnarchiveModel(ctx, *curUser, currModel,
 		currModel.WorkspaceId); err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "unarchive",
+			fmt.Sprintf("model %q", currModel.Name))
+	}
+	if err := limitModelAction(ctx, *curUser, config.ModelRateLimitEdit); err != nil {
 		return nil, err
 	}
 
@@ -486,39 +1161,67 @@ func (a *apiServer) UnarchiveModel(
 			req.ModelName)
 	}
 
+	modelauth.InvalidateCanGetModelsCache()
+	if err == nil {
+		currModel.Archived = false
+		notifyModelEvent("model unarchival",
+			modelauth.NotifierProvider.Get().ModelUnarchived(ctx, currModel))
+	}
 	return &apiv1.UnarchiveModelResponse{},
 		errors.Wrapf(err, "error unarchiving model %q", req.ModelName)
 }
 
-func (a *apiServer) MoveModel(
-	ctx context.Context, req *apiv1.MoveModelRequest,
-) (*apiv1.MoveModelResponse, error) {
-	currModel, err := a.ModelFromIdentifier(req.ModelName)
+// canMoveModel checks a move with the authz of both the source and the destination workspace,
+// since each may select its own implementation. idb is the transaction the model is moved in.
+func canMoveModel(
+	ctx context.Context, idb bun.IDB, curUser model.User, m *modelv1.Model, toWorkspaceID int32,
+) error {
+	for _, modelAuthZ := range modelauth.ForWorkspaces(m.WorkspaceId, toWorkspaceID) {
+		err := modelAuthZ.CanMoveModel(ctx, idb, curUser, m, m.WorkspaceId, toWorkspaceID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *apiServer) MoveModel(
+	ctx context.Context, req *apiv1.MoveModelRequest,
+) (*apiv1.MoveModelResponse, error) {
+	currModel, err := a.ModelFromIdentifier(req.ModelName)
 	if err != nil {
 		return nil, err
 	}
-	curUser, _, err := grpcutil.GetUser(ctx)
+	curUser, err := modelRegistryUser(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	err = modelauth.AuthZProvider.
-		Get().
-		CanMoveModel(ctx, *curUser, currModel, currModel.WorkspaceId, req.DestinationWorkspaceId)
-	if err != nil {
+	// A missing destination is reported as not found before any permission checks.
+	if _, err = a.GetWorkspaceByID(ctx, req.DestinationWorkspaceId, *curUser, false); err != nil {
 		return nil, err
 	}
 
-	holder := &modelv1.Model{}
-	err = a.m.db.QueryProto("move_model", holder, currModel.Id, req.DestinationWorkspaceId)
-	if err != nil {
-		return nil, errors.Wrapf(err, "error moving a model (%s)", req.ModelName)
-	}
-	if holder.Id == 0 {
-		return nil, errors.Wrapf(err, "Model (%s) does not exist or not moveable by this user",
-			req.ModelName)
+	// Authorize within the move so that the destination's model quota holds under concurrent
+	// creates and moves.
+	err = db.Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		err := canMoveModel(ctx, tx, *curUser, currModel, req.DestinationWorkspaceId)
+		if err != nil {
+			return modelauth.PermissionDenied(err, *curUser, "move",
+				fmt.Sprintf("model %q to workspace %d", currModel.Name, req.DestinationWorkspaceId))
+		}
+		if err := limitModelAction(ctx, *curUser, config.ModelRateLimitEdit); err != nil {
+			return err
+		}
+		return db.MoveModelTx(ctx, tx, currModel.Id, req.DestinationWorkspaceId)
+	})
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "model %q not found", req.ModelName)
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "error moving model %q", req.ModelName)
 	}
 
+	modelauth.InvalidateCanGetModelsCache()
 	return &apiv1.MoveModelResponse{}, nil
 }
 
@@ -531,12 +1234,16 @@ func (a *apiServer) DeleteModel(
 		return nil, err
 	}
 
-	curUser, _, err := grpcutil.GetUser(ctx)
+	curUser, err := modelRegistryUser(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if err := modelauth.AuthZProvider.Get().CanDeleteModel(ctx, *curUser, currModel,
+	if err := modelauth.ForWorkspace(currModel.WorkspaceId).CanDeleteModel(ctx, *curUser, currModel,
 		currModel.WorkspaceId); err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "delete",
+			fmt.Sprintf("model %q", currModel.Name))
+	}
+	if err := limitModelAction(ctx, *curUser, config.ModelRateLimitDelete); err != nil {
 		return nil, err
 	}
 	holder := &modelv1.Model{}
@@ -547,10 +1254,626 @@ func (a *apiServer) DeleteModel(
 			req.ModelName)
 	}
 
+	modelauth.InvalidateCanGetModelsCache()
+	if err == nil {
+		notifyModelEvent("model deletion",
+			modelauth.NotifierProvider.Get().ModelDeleted(ctx, currModel))
+	}
 	return &apiv1.DeleteModelResponse{},
 		errors.Wrapf(err, "error deleting model %q", req.ModelName)
 }
 
+// modelActionResult reports the outcome of an action on one model in a bulk model action.
+func modelActionResult(id int32, err error) *apiv1.ModelActionResult {
+	if err == nil {
+		return &apiv1.ModelActionResult{Id: id}
+	}
+	code := codes.Internal
+	var statusErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &statusErr) {
+		code = statusErr.GRPCStatus().Code()
+	}
+	return &apiv1.ModelActionResult{Id: id, Error: err.Error(), Code: int32(code)}
+}
+
+// bulkModelAction runs action on each model once, in the order given. Every model is acted on
+// separately, so that a failure for one model does not undo or prevent the others.
+func bulkModelAction(
+	modelIDs []int32, action func(identifier string) error,
+) []*apiv1.ModelActionResult {
+	results := make([]*apiv1.ModelActionResult, 0, len(modelIDs))
+	seen := make(map[int32]bool, len(modelIDs))
+	for _, id := range modelIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		results = append(results, modelActionResult(id, action(strconv.Itoa(int(id)))))
+	}
+	return results
+}
+
+func (a *apiServer) ArchiveModels(
+	ctx context.Context, req *apiv1.ArchiveModelsRequest,
+) (*apiv1.ArchiveModelsResponse, error) {
+	if _, err := modelRegistryUser(ctx); err != nil {
+		return nil, err
+	}
+	results := bulkModelAction(req.ModelIds, func(identifier string) error {
+		_, err := a.ArchiveModel(ctx, &apiv1.ArchiveModelRequest{ModelName: identifier})
+		return err
+	})
+	return &apiv1.ArchiveModelsResponse{Results: results}, nil
+}
+
+func (a *apiServer) DeleteModels(
+	ctx context.Context, req *apiv1.DeleteModelsRequest,
+) (*apiv1.DeleteModelsResponse, error) {
+	if _, err := modelRegistryUser(ctx); err != nil {
+		return nil, err
+	}
+	results := bulkModelAction(req.ModelIds, func(identifier string) error {
+		_, err := a.DeleteModel(ctx, &apiv1.DeleteModelRequest{ModelName: identifier})
+		return err
+	})
+	return &apiv1.DeleteModelsResponse{Results: results}, nil
+}
+
+// deletedModelFromIdentifier returns a model that was deleted but not yet purged.
+func (a *apiServer) deletedModelFromIdentifier(identifier string) (*modelv1.Model, error) {
+	m := &modelv1.Model{}
+	switch err := a.m.db.QueryProto("get_deleted_model", m, identifier); err {
+	case db.ErrNotFound:
+		return nil, status.Errorf(
+			codes.NotFound, "deleted model %q not found", identifier)
+	default:
+		return m, errors.Wrapf(err,
+			"error fetching deleted model %q from database", identifier)
+	}
+}
+
+func (a *apiServer) RestoreModel(
+	ctx context.Context, req *apiv1.RestoreModelRequest,
+) (*apiv1.RestoreModelResponse, error) {
+	currModel, err := a.deletedModelFromIdentifier(req.ModelName)
+	if err != nil {
+		return nil, err
+	}
+
+	curUser, err := modelRegistryUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Authorize within the restore so that the workspace's model quota holds under concurrent
+	// creates and restores.
+	err = db.Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := modelauth.ForWorkspace(currModel.WorkspaceId).CanRestoreModel(ctx, tx, *curUser,
+			currModel, currModel.WorkspaceId); err != nil {
+			return modelauth.PermissionDenied(err, *curUser, "restore",
+				fmt.Sprintf("model %q", currModel.Name))
+		}
+		if err := limitModelAction(ctx, *curUser, config.ModelRateLimitDelete); err != nil {
+			return err
+		}
+		return db.RestoreModelTx(ctx, tx, currModel.Id)
+	})
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound,
+			"deleted model %q was not found and cannot be restored", req.ModelName)
+	} else if err != nil {
+		return nil, err
+	}
+
+	modelauth.InvalidateCanGetModelsCache()
+	restoredModel, err := a.ModelFromIdentifier(strconv.Itoa(int(currModel.Id)))
+	if err != nil {
+		return nil, err
+	}
+	notifyModelEvent("model restoration",
+		modelauth.NotifierProvider.Get().ModelRestored(ctx, restoredModel))
+	return &apiv1.RestoreModelResponse{Model: maskModel(ctx, *curUser, restoredModel)}, nil
+}
+
+func (a *apiServer) TransferModelOwnership(
+	ctx context.Context, req *apiv1.TransferModelOwnershipRequest,
+) (*apiv1.TransferModelOwnershipResponse, error) {
+	currModel, err := a.ModelFromIdentifier(req.ModelName)
+	if err != nil {
+		return nil, err
+	}
+
+	curUser, err := modelRegistryUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := modelauth.ForWorkspace(currModel.WorkspaceId).
+		CanTransferModelOwnership(ctx, *curUser, currModel, req.NewOwnerId); err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "transfer ownership of",
+			fmt.Sprintf("model %q", currModel.Name))
+	}
+	if err := limitModelAction(ctx, *curUser, config.ModelRateLimitEdit); err != nil {
+		return nil, err
+	}
+
+	newOwner, err := user.ByID(ctx, model.UserID(req.NewOwnerId))
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, status.Errorf(codes.InvalidArgument, "user %d does not exist", req.NewOwnerId)
+	} else if err != nil {
+		return nil, err
+	}
+	if !newOwner.Active {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"user %q is not active and cannot own models", newOwner.Username)
+	}
+
+	err = db.TransferModelOwnership(ctx, currModel.Id, newOwner.ID, curUser.ID)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, api.NotFoundErrs("model", req.ModelName, true)
+	} else if err != nil {
+		return nil, err
+	}
+	log.Infof("model %q ownership transferred from user %d to %q by %q",
+		currModel.Name, currModel.OwnerId, newOwner.Username, curUser.Username)
+
+	transferredModel, err := a.ModelFromIdentifier(strconv.Itoa(int(currModel.Id)))
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.TransferModelOwnershipResponse{
+		Model: maskModel(ctx, *curUser, transferredModel),
+	}, nil
+}
+
+// validateModelTagKey checks that key can be used as a tag key and in a key=value filter.
+func validateModelTagKey(key string) error {
+	if strings.TrimSpace(key) == "" {
+		return status.Error(codes.InvalidArgument, "tag keys cannot be blank")
+	}
+	if strings.Contains(key, "=") {
+		return status.Errorf(codes.InvalidArgument, "tag key %q cannot contain '='", key)
+	}
+	return nil
+}
+
+// parseModelTagFilters parses tag filters given as key=value.
+func parseModelTagFilters(filters []string) (map[string]string, error) {
+	tags := make(map[string]string, len(filters))
+	for _, f := range filters {
+		key, value, ok := strings.Cut(f, "=")
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"tag filter %q must be given as key=value", f)
+		}
+		if err := validateModelTagKey(key); err != nil {
+			return nil, err
+		}
+		if prev, ok := tags[key]; ok && prev != value {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"tag %q cannot be filtered on both %q and %q", key, prev, value)
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
+
+func (a *apiServer) PutModelTag(
+	ctx context.Context, req *apiv1.PutModelTagRequest,
+) (*apiv1.PutModelTagResponse, error) {
+	currModel, err := a.ModelFromIdentifier(req.ModelName)
+	if err != nil {
+		return nil, err
+	}
+
+	curUser, err := modelRegistryUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := modelauth.ForWorkspace(currModel.WorkspaceId).CanEditModelTags(ctx, *curUser, currModel,
+		currModel.WorkspaceId); err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "edit tags of",
+			fmt.Sprintf("model %q", currModel.Name))
+	}
+	if err := limitModelAction(ctx, *curUser, config.ModelRateLimitEdit); err != nil {
+		return nil, err
+	}
+	if currModel.Archived {
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"model %q is archived and cannot have tags updated", currModel.Name)
+	}
+	if err := validateModelTagKey(req.Key); err != nil {
+		return nil, err
+	}
+
+	// Count the tags under the model's lock so that concurrent puts cannot exceed the limit.
+	err = db.Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		tags, err := db.LockModelTagsTx(ctx, tx, currModel.Id)
+		if err != nil {
+			return err
+		}
+		if _, ok := tags[req.Key]; !ok {
+			if err := checkModelTagCount(fmt.Sprintf("model %q", currModel.Name),
+				len(tags)+1); err != nil {
+				return err
+			}
+		}
+		return db.SetModelTagTx(ctx, tx, currModel.Id, req.Key, req.Value)
+	})
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "model %q not found", req.ModelName)
+	} else if err != nil {
+		return nil, err
+	}
+	taggedModel, err := a.ModelFromIdentifier(strconv.Itoa(int(currModel.Id)))
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.PutModelTagResponse{Model: maskModel(ctx, *curUser, taggedModel)}, nil
+}
+
+func (a *apiServer) DeleteModelTag(
+	ctx context.Context, req *apiv1.DeleteModelTagRequest,
+) (*apiv1.DeleteModelTagResponse, error) {
+	currModel, err := a.ModelFromIdentifier(req.ModelName)
+	if err != nil {
+		return nil, err
+	}
+
+	curUser, err := modelRegistryUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := modelauth.ForWorkspace(currModel.WorkspaceId).CanEditModelTags(ctx, *curUser, currModel,
+		currModel.WorkspaceId); err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "edit tags of",
+			fmt.Sprintf("model %q", currModel.Name))
+	}
+	if err := limitModelAction(ctx, *curUser, config.ModelRateLimitEdit); err != nil {
+		return nil, err
+	}
+	if currModel.Archived {
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"model %q is archived and cannot have tags updated", currModel.Name)
+	}
+
+	if err := db.DeleteModelTag(ctx, currModel.Id, req.Key); err != nil {
+		return nil, err
+	}
+	untaggedModel, err := a.ModelFromIdentifier(strconv.Itoa(int(currModel.Id)))
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.DeleteModelTagResponse{Model: maskModel(ctx, *curUser, untaggedModel)}, nil
+}
+
+// validateModelLabel checks that a label can be stored, given that PatchModel passes labels to
+// the database joined with commas.
+func validateModelLabel(label string) error {
+	if strings.TrimSpace(label) == "" {
+		return status.Error(codes.InvalidArgument, "model labels must not be blank")
+	}
+	if strings.Contains(label, ",") {
+		return status.Errorf(codes.InvalidArgument, "model label %q must not contain commas", label)
+	}
+	return nil
+}
+
+// checkModelMetadataSize fails with InvalidArgument if metadata, serialized, is larger than
+// model_registry.max_metadata_bytes. Only metadata that a request sets is checked, so that models
+// stored before the limit was lowered can still be read and otherwise edited.
+func checkModelMetadataSize(subject string, metadata []byte) error {
+	limit := config.GetMasterConfig().ModelRegistry.MetadataBytesLimit()
+	if len(metadata) > limit {
+		return status.Errorf(codes.InvalidArgument,
+			"metadata of %s is %d bytes, more than the allowed %d bytes", subject, len(metadata), limit)
+	}
+	return nil
+}
+
+// checkModelLabelCount fails with InvalidArgument if n labels are more than
+// model_registry.max_labels.
+func checkModelLabelCount(subject string, n int) error {
+	limit := config.GetMasterConfig().ModelRegistry.LabelsLimit()
+	if n > limit {
+		return status.Errorf(codes.InvalidArgument,
+			"%s would have %d labels, more than the allowed %d", subject, n, limit)
+	}
+	return nil
+}
+
+// checkModelTagCount fails with InvalidArgument if n tags are more than model_registry.max_tags.
+func checkModelTagCount(subject string, n int) error {
+	limit := config.GetMasterConfig().ModelRegistry.TagsLimit()
+	if n > limit {
+		return status.Errorf(codes.InvalidArgument,
+			"%s would have %d tags, more than the allowed %d", subject, n, limit)
+	}
+	return nil
+}
+
+func (a *apiServer) PutModelLabel(
+	ctx context.Context, req *apiv1.PutModelLabelRequest,
+) (*apiv1.PutModelLabelResponse, error) {
+	currModel, err := a.ModelFromIdentifier(req.ModelName)
+	if err != nil {
+		return nil, err
+	}
+
+	curUser, err := modelRegistryUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := modelauth.ForWorkspace(currModel.WorkspaceId).CanEditModel(ctx, *curUser, currModel,
+		currModel.WorkspaceId); err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "edit",
+			fmt.Sprintf("model %q", currModel.Name))
+	}
+	if err := limitModelAction(ctx, *curUser, config.ModelRateLimitEdit); err != nil {
+		return nil, err
+	}
+	if currModel.Archived {
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"model %q is archived and cannot have labels updated", currModel.Name)
+	}
+	if err := validateModelLabel(req.Label); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(currModel.Labels, req.Label) {
+		if err := checkModelLabelCount(fmt.Sprintf("model %q", currModel.Name),
+			len(currModel.Labels)+1); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.AddModelLabel(ctx, currModel.Id, req.Label); err != nil {
+		return nil, err
+	}
+	labeledModel, err := a.ModelFromIdentifier(strconv.Itoa(int(currModel.Id)))
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.PutModelLabelResponse{Model: maskModel(ctx, *curUser, labeledModel)}, nil
+}
+
+func (a *apiServer) DeleteModelLabel(
+	ctx context.Context, req *apiv1.DeleteModelLabelRequest,
+) (*apiv1.DeleteModelLabelResponse, error) {
+	currModel, err := a.ModelFromIdentifier(req.ModelName)
+	if err != nil {
+		return nil, err
+	}
+
+	curUser, err := modelRegistryUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := modelauth.ForWorkspace(currModel.WorkspaceId).CanEditModel(ctx, *curUser, currModel,
+		currModel.WorkspaceId); err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "edit",
+			fmt.Sprintf("model %q", currModel.Name))
+	}
+	if err := limitModelAction(ctx, *curUser, config.ModelRateLimitEdit); err != nil {
+		return nil, err
+	}
+	if currModel.Archived {
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"model %q is archived and cannot have labels updated", currModel.Name)
+	}
+
+	if err := db.RemoveModelLabel(ctx, currModel.Id, req.Label); err != nil {
+		return nil, err
+	}
+	unlabeledModel, err := a.ModelFromIdentifier(strconv.Itoa(int(currModel.Id)))
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.DeleteModelLabelResponse{
+		Model: maskModel(ctx, *curUser, unlabeledModel),
+	}, nil
+}
+
+// maxCopyModelNameAttempts bounds how many generated names are tried for a copy of a model.
+const maxCopyModelNameAttempts = 100
+
+// copyModelName returns the first of "<name> copy", "<name> copy 2", ... that may be used for a
+// new model in the workspace, checking names using idb.
+func (a *apiServer) copyModelName(
+	ctx context.Context, idb bun.IDB, name string, workspaceID int32,
+) (string, error) {
+	for i := 1; i <= maxCopyModelNameAttempts; i++ {
+		candidate := name + " copy"
+		if i > 1 {
+			candidate = fmt.Sprintf("%s copy %d", name, i)
+		}
+		if err := modelauth.NamePolicyProvider.Get().Validate(candidate, workspaceID); err != nil {
+			continue
+		}
+		err := a.checkModelNameAvailable(ctx, idb, candidate, 0)
+		var nameErr *modelauth.ModelNameError
+		if err == nil {
+			return candidate, nil
+		} else if status.Code(err) != codes.AlreadyExists && !errors.As(err, &nameErr) {
+			return "", err
+		}
+	}
+	return "", status.Errorf(codes.AlreadyExists,
+		"could not generate a name for a copy of model %q, please provide one", name)
+}
+
+func (a *apiServer) CopyModel(
+	ctx context.Context, req *apiv1.CopyModelRequest,
+) (*apiv1.CopyModelResponse, error) {
+	source, err := a.ModelFromIdentifier(req.ModelName)
+	if err != nil {
+		return nil, err
+	}
+
+	curUser, err := modelRegistryUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := modelauth.ForWorkspace(source.WorkspaceId).CanGetModel(ctx, *curUser, source,
+		source.WorkspaceId); err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "get",
+			fmt.Sprintf("model %q", source.Name))
+	}
+
+	workspaceID := source.WorkspaceId
+	if req.WorkspaceId != nil {
+		workspaceID = *req.WorkspaceId
+	}
+	if req.Name != nil {
+		if err := modelauth.NamePolicyProvider.Get().Validate(*req.Name, workspaceID); err != nil {
+			return nil, err
+		}
+	}
+	versions := make([]int32, 0, len(req.Versions))
+	seen := make(map[int32]bool, len(req.Versions))
+	for _, v := range req.Versions {
+		if !seen[v] {
+			seen[v] = true
+			versions = append(versions, v)
+		}
+	}
+	metadata, err := protojson.Marshal(source.Metadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshaling model.Metadata")
+	}
+
+	var m *modelv1.Model
+	var name string
+	// Authorize within the insert so that a workspace's model quota holds under concurrent creates.
+	err = db.Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := modelauth.ForWorkspace(workspaceID).CanCreateModel(ctx, tx, *curUser,
+			workspaceID); err != nil {
+			return modelauth.PermissionDenied(err, *curUser, "create",
+				fmt.Sprintf("models in workspace %d", workspaceID))
+		}
+		if err := limitModelAction(ctx, *curUser, config.ModelRateLimitCreate); err != nil {
+			return err
+		}
+		if req.Name != nil {
+			name = *req.Name
+			if err := a.checkModelNameAvailable(ctx, tx, name, 0); err != nil {
+				return err
+			}
+		} else {
+			generated, err := a.copyModelName(ctx, tx, source.Name, workspaceID)
+			if err != nil {
+				return err
+			}
+			name = generated
+		}
+
+		var insertErr error
+		m, insertErr = db.InsertModelTx(ctx, tx, name, source.Description, metadata,
+			strings.Join(source.Labels, ","), source.Notes, curUser.ID, int(workspaceID),
+			source.Visibility)
+		if insertErr != nil {
+			return errors.Wrapf(insertErr, "error creating model %q in database", name)
+		}
+		toCopy, err := copyableModelVersionsTx(ctx, tx, *curUser, source, m, versions)
+		if err != nil {
+			return err
+		}
+		_, err = db.CopyModelTx(ctx, tx, source.Id, m.Id, toCopy)
+		return err
+	})
+	if err != nil && strings.Contains(err.Error(), db.CodeUniqueViolation) {
+		return nil, modelNameConflictError(name, err)
+	} else if err != nil {
+		return nil, err
+	}
+	modelauth.InvalidateCanGetModelsCache()
+
+	m, err = a.ModelFromIdentifier(strconv.Itoa(int(m.Id)))
+	if err != nil {
+		return nil, err
+	}
+	notifyModelEvent("model creation", modelauth.NotifierProvider.Get().ModelCreated(ctx, m))
+	log.Infof("model %q copied to %q by %q", source.Name, m.Name, curUser.Username)
+	return &apiv1.CopyModelResponse{Model: maskModel(ctx, *curUser, m)}, nil
+}
+
+// copyableModelVersionsTx returns the numbers of the versions of source that are copied into m,
+// a new model: versions, or all of them if none are given, leaving out those curUser may not
+// read. Copying fails if curUser may not register the checkpoint of one of them as a version of
+// m, or if m may not have as many versions.
+func copyableModelVersionsTx(
+	ctx context.Context, tx bun.Tx, curUser model.User, source, m *modelv1.Model, versions []int32,
+) ([]int32, error) {
+	q := tx.NewSelect().
+		TableExpr("model_versions AS mv").
+		Column("mv.version", "mv.checkpoint_uuid").
+		ColumnExpr("p.workspace_id").
+		Join("LEFT JOIN checkpoints_view AS c ON c.uuid = mv.checkpoint_uuid").
+		Join("LEFT JOIN experiments AS e ON e.id = c.experiment_id").
+		Join("LEFT JOIN projects AS p ON p.id = e.project_id").
+		Where("mv.model_id = ?", source.Id).
+		OrderExpr("mv.version")
+	if len(versions) > 0 {
+		q = q.Where("mv.version IN (?)", bun.In(versions))
+	}
+	// Versions are filtered as they are when listing them, so that unreadable ones are reported
+	// like missing ones.
+	q, err := modelauth.ForWorkspace(source.WorkspaceId).
+		FilterModelVersionsQuery(ctx, curUser, source, q)
+	if err != nil {
+		return nil, err
+	}
+	var sourceVersions []struct {
+		Version        int32
+		CheckpointUUID string
+		WorkspaceID    *int32
+	}
+	if err := q.Scan(ctx, &sourceVersions); err != nil {
+		return nil, errors.Wrapf(err, "error getting versions of model %q", source.Name)
+	}
+	if len(versions) > 0 && len(sourceVersions) != len(versions) {
+		return nil, status.Errorf(codes.NotFound, "not all of versions %v of model %q exist",
+			versions, source.Name)
+	}
+
+	limit := config.GetMasterConfig().ModelRegistry.VersionsLimit(int(m.MaxVersions))
+	if limit > 0 && len(sourceVersions) > limit {
+		return nil, status.Errorf(codes.ResourceExhausted,
+			"model %q may have at most %d versions, so %d versions of model %q cannot be copied",
+			m.Name, limit, len(sourceVersions), source.Name)
+	}
+
+	// The copies are registered in the destination workspace, whose authz may not allow the
+	// checkpoints the source model was allowed.
+	toCopy := make([]int32, len(sourceVersions))
+	for i, v := range sourceVersions {
+		if err := modelauth.ForWorkspace(m.WorkspaceId).CanCreateModelVersionFromCheckpoint(ctx,
+			curUser, m, m.WorkspaceId, v.CheckpointUUID, v.WorkspaceID); err != nil {
+			return nil, modelauth.PermissionDenied(err, curUser, "register",
+				fmt.Sprintf("checkpoint %s as a version of model %q", v.CheckpointUUID, m.Name))
+		}
+		toCopy[i] = v.Version
+	}
+	return toCopy, nil
+}
+
+// purgeDeletedModels permanently deletes models that were deleted more than retention ago.
+func purgeDeletedModels(ctx context.Context, retention time.Duration) {
+	t := time.NewTicker(time.Hour)
+	defer t.Stop()
+	for {
+		purged, err := db.PurgeDeletedModels(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.WithError(err).Error("error purging deleted models")
+		} else if purged > 0 {
+			log.Infof("purged %d models deleted more than %s ago", purged, retention)
+		}
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 func (a *apiServer) GetModelVersion(
 	ctx context.Context, req *apiv1.GetModelVersionRequest,
 ) (*apiv1.GetModelVersionResponse, error) {
@@ -559,23 +1882,63 @@ func (a *apiServer) GetModelVersion(
 		return nil, err
 	}
 
-	curUser, _, err := grpcutil.GetUser(ctx)
+	curUser, err := modelRegistryUser(ctx)
 	if err != nil {
 		return nil, err
 	}
 	currModel, _ := a.ModelFromIdentifier(req.ModelName)
-	if err = modelauth.AuthZProvider.Get().CanGetModel(ctx, *curUser, currModel,
-		currModel.WorkspaceId); err != nil {
-		return nil, authz.SubIfUnauthorized(err,
-			errors.Errorf("current user %q doesn't have permissions to get model %q",
-				curUser.Username, currModel.Name))
+	if err = modelauth.ForWorkspace(currModel.WorkspaceId).CanGetModelVersion(ctx, *curUser,
+		currModel, mv, currModel.WorkspaceId); err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "get",
+			fmt.Sprintf("model version %v:%v", currModel.Name, mv.Version))
+	}
+	if err := limitModelAction(ctx, *curUser, config.ModelRateLimitGet); err != nil {
+		return nil, err
 	}
 
+	// Users who cannot read the checkpoint's artifacts still see the version, but not where
+	// its files are stored.
+	if c := mv.Checkpoint; c != nil {
+		ckptWorkspaceID, err := checkpointExperimentWorkspaceID(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		canDownload, err := modelauth.ForWorkspace(currModel.WorkspaceId).
+			CanDownloadModelVersionCheckpoint(ctx, *curUser, mv, currModel.WorkspaceId, ckptWorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		if !canDownload {
+			c.Resources = nil
+			c.StorageId = nil
+		}
+	}
+
+	mv.Model = maskModel(ctx, *curUser, mv.Model)
 	resp := &apiv1.GetModelVersionResponse{}
 	resp.ModelVersion = mv
 	return resp, nil
 }
 
+// checkpointExperimentWorkspaceID returns the workspace of the experiment that produced the
+// checkpoint, or nil if it was not produced by an experiment.
+func checkpointExperimentWorkspaceID(
+	ctx context.Context, c *checkpointv1.Checkpoint,
+) (*int32, error) {
+	expID := c.GetTraining().GetExperimentId()
+	if expID == 0 {
+		return nil, nil
+	}
+	workspaceIDs, err := db.ExperimentIDsToWorkspaceIDs(ctx, []int32{expID})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting workspace of checkpoint %s", c.Uuid)
+	}
+	if len(workspaceIDs) == 0 {
+		return nil, nil
+	}
+	return ptrs.Ptr(int32(workspaceIDs[0])), nil
+}
+
 func (a *apiServer) GetModelVersions(
 	ctx context.Context, req *apiv1.GetModelVersionsRequest,
 ) (*apiv1.GetModelVersionsResponse, error) {
@@ -584,16 +1947,18 @@ func (a *apiServer) GetModelVersions(
 		return nil, err
 	}
 
-	curUser, _, err := grpcutil.GetUser(ctx)
+	curUser, err := modelRegistryUser(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := modelauth.AuthZProvider.Get().CanGetModel(ctx, *curUser, parentModel,
-		parentModel.WorkspaceId); err != nil {
-		return nil, authz.SubIfUnauthorized(err,
-			errors.Errorf("current user %q doesn't have permissions to get model %q",
-				curUser.Username, parentModel.Name))
+	if err := modelauth.ForWorkspace(parentModel.WorkspaceId).
+		CanGetModelVersions(ctx, *curUser, parentModel, parentModel.WorkspaceId); err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "get",
+			fmt.Sprintf("versions of model %q", parentModel.Name))
+	}
+	if err := limitModelAction(ctx, *curUser, config.ModelRateLimitGet); err != nil {
+		return nil, err
 	}
 
 	resp := &apiv1.GetModelVersionsResponse{Model: parentModel}
@@ -602,10 +1967,139 @@ func (a *apiServer) GetModelVersions(
 		return nil, err
 	}
 
+	// Drop versions the user may not read before sorting and paginating so the total count
+	// reflects only what is returned.
+	readableQuery, err := modelauth.ForWorkspace(parentModel.WorkspaceId).
+		FilterModelVersionsQuery(ctx, *curUser, parentModel,
+			db.Bun().NewSelect().TableExpr("model_versions AS mv").
+				Column("mv.id").
+				Where("mv.model_id = ?", parentModel.Id))
+	if err != nil {
+		return nil, err
+	}
+	var readableIDs []int32
+	if err = readableQuery.Scan(ctx, &readableIDs); err != nil {
+		return nil, errors.Wrapf(err, "error filtering versions of model %q", parentModel.Name)
+	}
+	readable := make(map[int32]bool, len(readableIDs))
+	for _, id := range readableIDs {
+		readable[id] = true
+	}
+	readableVersions := resp.ModelVersions[:0]
+	for _, mv := range resp.ModelVersions {
+		if readable[mv.Id] {
+			readableVersions = append(readableVersions, mv)
+		}
+	}
+	resp.ModelVersions = readableVersions
+
 	api.Sort(
 		resp.ModelVersions, req.OrderBy, req.SortBy, apiv1.GetModelVersionsRequest_SORT_BY_VERSION,
 	)
-	return resp, api.Paginate(&resp.Pagination, &resp.ModelVersions, req.Offset, req.Limit)
+	if err := api.Paginate(&resp.Pagination, &resp.ModelVersions, req.Offset, req.Limit); err != nil {
+		return nil, err
+	}
+	resp.Model = maskModel(ctx, *curUser, resp.Model)
+	for _, mv := range resp.ModelVersions {
+		mv.Model = maskModel(ctx, *curUser, mv.Model)
+	}
+	return resp, nil
+}
+
+func (a *apiServer) GetCheckpointModelVersions(
+	ctx context.Context, req *apiv1.GetCheckpointModelVersionsRequest,
+) (*apiv1.GetCheckpointModelVersionsResponse, error) {
+	ckptUUID, err := uuid.Parse(req.CheckpointUuid)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid checkpoint UUID %q: %s",
+			req.CheckpointUuid, err)
+	}
+	curUser, err := modelRegistryUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	modelIDs, err := checkpoints.GetModelIDsAssociatedWithCheckpoint(ctx, ckptUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Models the user may not view are left out, rather than failing the request, so that the
+	// lookup does not tell them apart from checkpoints that are not registered to any model.
+	allowed, err := canGetModelsByIDs(ctx, *curUser, modelIDs, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := limitModelAction(ctx, *curUser, config.ModelRateLimitGet); err != nil {
+		return nil, err
+	}
+	var allowedIDs []int32
+	for _, id := range modelIDs {
+		if allowed[id] {
+			allowedIDs = append(allowedIDs, id)
+		}
+	}
+	var models []*modelv1.Model
+	if len(allowedIDs) > 0 {
+		if err := db.Bun().NewSelect().
+			Model(&models).
+			ModelTableExpr("models AS m").
+			Apply(getModelColumns).
+			Join("LEFT JOIN users AS u ON u.id = m.user_id").
+			Where("m.id IN (?)", bun.In(allowedIDs)).
+			Scan(ctx); err != nil {
+			return nil, errors.Wrap(err, "error getting models of checkpoint")
+		}
+	}
+
+	// Each model's versions are filtered by the authz of its workspace, in a single query.
+	readable := make([]*bun.SelectQuery, 0, len(models))
+	for _, m := range models {
+		sub, err := modelauth.ForWorkspace(m.WorkspaceId).FilterModelVersionsQuery(ctx, *curUser, m,
+			db.Bun().NewSelect().TableExpr("model_versions AS mv").
+				Column("mv.id").
+				Where("mv.model_id = ?", m.Id))
+		if err != nil {
+			return nil, err
+		}
+		readable = append(readable, sub)
+	}
+	var versionIDs []int32
+	if len(readable) > 0 {
+		if err := db.Bun().NewSelect().
+			TableExpr("model_versions AS mv").
+			Column("mv.id").
+			Where("mv.checkpoint_uuid = ?", ckptUUID).
+			WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+				for _, sub := range readable {
+					q = q.WhereOr("mv.id IN (?)", sub)
+				}
+				return q
+			}).
+			OrderExpr("mv.model_id, mv.version").
+			Scan(ctx, &versionIDs); err != nil {
+			return nil, errors.Wrap(err, "error filtering versions of checkpoint")
+		}
+	}
+
+	// Only the versions on the requested page are loaded in full.
+	resp := &apiv1.GetCheckpointModelVersionsResponse{ModelVersions: []*modelv1.ModelVersion{}}
+	if err := api.Paginate(&resp.Pagination, &versionIDs, req.Offset, req.Limit); err != nil {
+		return nil, err
+	}
+	if len(versionIDs) > 0 {
+		ids := make([]string, len(versionIDs))
+		for i, id := range versionIDs {
+			ids[i] = strconv.Itoa(int(id))
+		}
+		if err := a.m.db.QueryProto("get_model_versions_by_ids", &resp.ModelVersions,
+			strings.Join(ids, ",")); err != nil {
+			return nil, err
+		}
+	}
+	for _, mv := range resp.ModelVersions {
+		mv.Model = maskModel(ctx, *curUser, mv.Model)
+	}
+	return resp, nil
 }
 
 func (a *apiServer) PostModelVersion(
@@ -617,18 +2111,19 @@ func (a *apiServer) PostModelVersion(
 		return nil, err
 	}
 
-	curUser, _, err := grpcutil.GetUser(ctx)
+	curUser, err := modelRegistryUser(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if err := modelauth.AuthZProvider.Get().CanEditModel(ctx, *curUser, modelResp,
-		modelResp.WorkspaceId); err != nil {
-		return nil, err
+	if err := modelauth.ForWorkspace(modelResp.WorkspaceId).
+		CanCreateModelVersion(ctx, *curUser, modelResp, modelResp.WorkspaceId); err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "create",
+			fmt.Sprintf("versions of model %q", modelResp.Name))
 	}
 
 	if modelResp.Archived {
-		return nil, errors.Errorf("model %q is archived and cannot register new versions",
-			modelResp.Name)
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"model %q is archived and cannot register new versions", modelResp.Name)
 	}
 
 	// make sure the checkpoint exists
@@ -646,6 +2141,19 @@ func (a *apiServer) PostModelVersion(
 		)
 	}
 
+	checkpointWorkspaceID, err := checkpointExperimentWorkspaceID(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if err := modelauth.ForWorkspace(modelResp.WorkspaceId).CanCreateModelVersionFromCheckpoint(
+		ctx, *curUser, modelResp, modelResp.WorkspaceId, c.Uuid, checkpointWorkspaceID); err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "register",
+			fmt.Sprintf("checkpoint %s as a version of model %q", c.Uuid, modelResp.Name))
+	}
+	if err := limitModelAction(ctx, *curUser, config.ModelRateLimitCreate); err != nil {
+		return nil, err
+	}
+
 	user, err := a.CurrentUser(ctx, &apiv1.CurrentUserRequest{})
 	if err != nil {
 		return nil, err
@@ -657,27 +2165,163 @@ func (a *apiServer) PostModelVersion(
 	if err != nil {
 		return nil, errors.Wrap(err, "error marshaling ModelVersion.Metadata")
 	}
+	subject := fmt.Sprintf("the new version of model %q", modelResp.Name)
+	if err := checkModelMetadataSize(subject, mdata); err != nil {
+		return nil, err
+	}
+	if err := checkModelLabelCount(subject, len(req.Labels)); err != nil {
+		return nil, err
+	}
 
 	reqLabels := strings.Join(req.Labels, ",")
 
-	modelVersion, err := db.InsertModelVersion(
-		ctx,
-		modelResp.Id,
-		c.Uuid,
-		req.Name,
-		req.Comment,
-		mdata,
-		reqLabels,
-		req.Notes,
-		model.UserID(user.User.GetId()),
-	)
+	var pruned []int32
+	insert := func(ctx context.Context, idb bun.IDB) (*modelv1.ModelVersion, error) {
+		var err error
+		if pruned, err = makeRoomForModelVersionTx(ctx, idb, modelResp); err != nil {
+			return nil, err
+		}
+		return db.InsertModelVersionTx(
+			ctx,
+			idb,
+			modelResp.Id,
+			c.Uuid,
+			req.Name,
+			req.Comment,
+			mdata,
+			reqLabels,
+			req.Notes,
+			model.UserID(user.User.GetId()),
+		)
+	}
+	var modelVersion *modelv1.ModelVersion
+	created := true
+	// The model is only locked once the permission checks above pass, so that slow checks do not
+	// hold up other registrations.
+	err = db.Bun().RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		// Concurrent registrations wait for each other here, so each is numbered after the ones
+		// before it, and a retry with the same idempotency key sees the version registered first.
+		switch err := db.LockModelTx(ctx, tx, modelResp.Id); {
+		case errors.Is(err, db.ErrNotFound):
+			return status.Errorf(codes.NotFound, "model %q not found", req.ModelName)
+		case err != nil:
+			return err
+		}
+		var err error
+		if req.IdempotencyKey == "" {
+			modelVersion, err = insert(ctx, tx)
+		} else {
+			modelVersion, created, err = a.insertModelVersionIdempotentTx(ctx, tx, modelResp,
+				req.IdempotencyKey, c.Uuid, insert)
+		}
+		return err
+	})
 
 	respModelVersion.ModelVersion = modelVersion
+	if err == nil && len(pruned) > 0 {
+		respModelVersion.PrunedVersions = pruned
+		log.Infof("versions %v of model %q pruned to make room for version %d",
+			pruned, modelResp.Name, modelVersion.Version)
+	}
+	if err == nil && created {
+		notifyModelEvent("model version creation",
+			modelauth.NotifierProvider.Get().ModelVersionCreated(ctx, modelVersion))
+		notifyModelEvent("model version creation to model webhooks",
+			fireModelWebhooks(ctx, model.ModelWebhookVersionCreated, modelVersion))
+	}
+	if modelVersion != nil {
+		// Masked only after notifying, which sees the model in full.
+		modelVersion.Model = maskModel(ctx, *curUser, modelVersion.Model)
+	}
 
 	return respModelVersion, errors.Wrapf(err, "error adding model version to model %q",
 		req.ModelName)
 }
 
+// makeRoomForModelVersionTx checks in idb that the model has fewer versions than it may have
+// before another is registered. If it does not, registering fails with ResourceExhausted, unless
+// the model prunes versions. Then its oldest versions that are not protected from deletion are
+// deleted to make room, and their numbers are returned.
+func makeRoomForModelVersionTx(
+	ctx context.Context, idb bun.IDB, m *modelv1.Model,
+) ([]int32, error) {
+	// Read under the model lock rather than from m, so that concurrent edits and registrations
+	// are counted.
+	var settings struct {
+		MaxVersions   int  `bun:"max_versions"`
+		PruneVersions bool `bun:"prune_versions"`
+		NumVersions   int  `bun:"num_versions"`
+	}
+	if err := idb.NewSelect().
+		TableExpr("models AS m").
+		Column("m.max_versions", "m.prune_versions").
+		ColumnExpr("(SELECT COUNT(*) FROM model_versions AS mv WHERE mv.model_id = m.id) "+
+			"AS num_versions").
+		Where("m.id = ?", m.Id).
+		Scan(ctx, &settings); err != nil {
+		return nil, errors.Wrapf(err, "error getting version limit of model %q", m.Name)
+	}
+
+	registry := config.GetMasterConfig().ModelRegistry
+	limit := registry.VersionsLimit(settings.MaxVersions)
+	if limit == 0 || settings.NumVersions < limit {
+		return nil, nil
+	}
+	if !settings.PruneVersions {
+		return nil, status.Errorf(codes.ResourceExhausted,
+			"model %q has %d versions and may have at most %d; delete versions or set "+
+				"prune_versions on the model to register more", m.Name, settings.NumVersions, limit)
+	}
+	excess := settings.NumVersions - limit + 1
+	pruned, err := db.PruneModelVersionsTx(ctx, idb, m.Id, excess,
+		registry.ProtectedVersionLabels, registry.ProtectLatestVersion)
+	if err != nil {
+		return nil, err
+	}
+	if len(pruned) < excess {
+		return nil, status.Errorf(codes.ResourceExhausted,
+			"model %q has %d versions and may have at most %d, but only %d of the %d that must "+
+				"be pruned to register another are not protected from deletion",
+			m.Name, settings.NumVersions, limit, len(pruned), excess)
+	}
+	return pruned, nil
+}
+
+// insertModelVersionIdempotentTx registers the checkpoint as a version of the model with insert
+// in tx, unless a request with the same idempotency key registered a version of the model within
+// model_registry.idempotency_key_retention. Then that version is returned, and created is false.
+func (a *apiServer) insertModelVersionIdempotentTx(
+	ctx context.Context, tx bun.Tx, m *modelv1.Model, key, checkpointUUID string,
+	insert func(context.Context, bun.IDB) (*modelv1.ModelVersion, error),
+) (mv *modelv1.ModelVersion, created bool, err error) {
+	retention := time.Duration(config.GetMasterConfig().ModelRegistry.IdempotencyKeyRetention)
+	version, registered, err := db.ModelVersionByIdempotencyKeyTx(ctx, tx, m.Id, key,
+		time.Now().Add(-retention))
+	switch {
+	case err == nil && registered != checkpointUUID:
+		return nil, false, status.Errorf(codes.InvalidArgument,
+			"idempotency key %q was used to register checkpoint %s as version %d of model %q",
+			key, registered, version, m.Name)
+	case err == nil:
+		// The version was committed by an earlier request, so it can be read outside tx.
+		mv, err = a.ModelVersionFromID(strconv.Itoa(int(m.Id)), version)
+		return mv, false, err
+	case !errors.Is(err, db.ErrNotFound):
+		return nil, false, err
+	}
+
+	if mv, err = insert(ctx, tx); err != nil {
+		return nil, false, err
+	}
+	return mv, true, db.InsertModelVersionIdempotencyKeyTx(ctx, tx,
+		&model.ModelVersionIdempotencyKey{
+			ModelID:        m.Id,
+			IdempotencyKey: key,
+			ModelVersionID: mv.Id,
+			CheckpointUUID: checkpointUUID,
+		})
+}
+
 func (a *apiServer) PatchModelVersion(
 	ctx context.Context, req *apiv1.PatchModelVersionRequest) (*apiv1.PatchModelVersionResponse,
 	error,
@@ -687,7 +2331,7 @@ func (a *apiServer) PatchModelVersion(
 		return nil, err
 	}
 
-	curUser, _, err := grpcutil.GetUser(ctx)
+	curUser, err := modelRegistryUser(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -695,8 +2339,17 @@ func (a *apiServer) PatchModelVersion(
 	if err != nil {
 		return nil, err
 	}
-	if err := modelauth.AuthZProvider.Get().CanEditModel(ctx, *curUser, currModel,
-		currModel.WorkspaceId); err != nil {
+	if err := modelauth.ForWorkspace(currModel.WorkspaceId).
+		CanEditModelVersion(ctx, *curUser, currModel, currModelVersion,
+			currModel.WorkspaceId); err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "edit",
+			fmt.Sprintf("model version %v:%v", currModel.Name, currModelVersion.Version))
+	}
+	if currModel.Archived {
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"model %q is archived and cannot have versions updated", currModel.Name)
+	}
+	if err := limitModelAction(ctx, *curUser, config.ModelRateLimitEdit); err != nil {
 		return nil, err
 	}
 
@@ -734,6 +2387,9 @@ func (a *apiServer) PatchModelVersion(
 		if err2 != nil {
 			return nil, errors.Wrap(err2, "error marshaling request model version metadata")
 		}
+		if err := checkModelMetadataSize("model version "+modelVersionName, newMeta); err != nil {
+			return nil, err
+		}
 
 		if !bytes.Equal(currMeta, newMeta) {
 			log.Infof("model version (%v) metadata changing from %q to %q",
@@ -752,6 +2408,10 @@ func (a *apiServer) PatchModelVersion(
 				reqLabelSet[el.GetStringValue()] = struct{}{}
 			}
 		}
+		if err := checkModelLabelCount("model version "+modelVersionName,
+			len(reqLabelSet)); err != nil {
+			return nil, err
+		}
 		reqLabelList := make([]string, len(reqLabelSet))
 		i := 0
 		for key := range reqLabelSet {
@@ -768,6 +2428,7 @@ func (a *apiServer) PatchModelVersion(
 	}
 
 	if !madeChanges {
+		currModelVersion.Model = maskModel(ctx, *curUser, currModelVersion.Model)
 		return &apiv1.PatchModelVersionResponse{ModelVersion: currModelVersion}, nil
 	}
 
@@ -775,6 +2436,11 @@ func (a *apiServer) PatchModelVersion(
 	err = a.m.db.QueryProto("update_model_version", finalModelVersion, currModelVersion.Id,
 		parentModel.Id, currModelVersion.Name, currModelVersion.Comment, currModelVersion.Notes,
 		currMeta, currLabels)
+	if err == nil && promotesModelVersion(currModelVersion.Labels, finalModelVersion.Labels) {
+		notifyModelEvent("model version promotion to model webhooks",
+			fireModelWebhooks(ctx, model.ModelWebhookVersionPromoted, finalModelVersion))
+	}
+	finalModelVersion.Model = maskModel(ctx, *curUser, finalModelVersion.Model)
 
 	return &apiv1.PatchModelVersionResponse{ModelVersion: finalModelVersion},
 		errors.Wrapf(err, "error updating model version (%v) in database", modelVersionName)
@@ -789,7 +2455,7 @@ func (a *apiServer) DeleteModelVersion(
 		return nil, err
 	}
 
-	curUser, _, err := grpcutil.GetUser(ctx)
+	curUser, err := modelRegistryUser(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -797,8 +2463,29 @@ func (a *apiServer) DeleteModelVersion(
 	if err != nil {
 		return nil, err
 	}
-	if err := modelauth.AuthZProvider.Get().CanDeleteModelVersion(ctx, *curUser,
-		modelVersion, currModel.WorkspaceId); err != nil {
+	if err := modelauth.ForWorkspace(currModel.WorkspaceId).CanDeleteModelVersion(ctx, *curUser,
+		currModel, modelVersion, currModel.WorkspaceId); err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "delete",
+			fmt.Sprintf("model version %v:%v", currModel.Name, modelVersion.Version))
+	}
+	protection, err := modelVersionDeleteProtection(ctx, currModel, modelVersion)
+	if err != nil {
+		return nil, err
+	}
+	if protection != "" {
+		if !req.Force {
+			return nil, status.Errorf(codes.FailedPrecondition,
+				"model version %v:%v %s; set force to delete it anyway", currModel.Name,
+				modelVersion.Version, protection)
+		}
+		if err := modelauth.ForWorkspace(currModel.WorkspaceId).CanForceDeleteModelVersion(ctx,
+			*curUser, currModel, modelVersion, currModel.WorkspaceId); err != nil {
+			return nil, modelauth.PermissionDenied(err, *curUser, "force delete",
+				fmt.Sprintf("model version %v:%v, which %s", currModel.Name, modelVersion.Version,
+					protection))
+		}
+	}
+	if err := limitModelAction(ctx, *curUser, config.ModelRateLimitDelete); err != nil {
 		return nil, err
 	}
 
@@ -815,6 +2502,46 @@ func (a *apiServer) DeleteModelVersion(
 		errors.Wrapf(err, "error deleting model version %v", modelVersionName)
 }
 
+// promotesModelVersion returns whether changing the labels of a version from before to after
+// adds a protected label.
+func promotesModelVersion(before, after []string) bool {
+	for _, label := range after {
+		if slices.Contains(config.GetMasterConfig().ModelRegistry.ProtectedVersionLabels, label) &&
+			!slices.Contains(before, label) {
+			return true
+		}
+	}
+	return false
+}
+
+// modelVersionDeleteProtection returns why the version may only be deleted by force, such as
+// `carries the protected label "production"`, or an empty string if it is not protected.
+func modelVersionDeleteProtection(
+	ctx context.Context, m *modelv1.Model, mv *modelv1.ModelVersion,
+) (string, error) {
+	registry := config.GetMasterConfig().ModelRegistry
+	for _, label := range mv.Labels {
+		if slices.Contains(registry.ProtectedVersionLabels, label) {
+			return fmt.Sprintf("carries the protected label %q", label), nil
+		}
+	}
+	if !registry.ProtectLatestVersion {
+		return "", nil
+	}
+	var latest int32
+	if err := db.Bun().NewSelect().
+		Table("model_versions").
+		ColumnExpr("MAX(version)").
+		Where("model_id = ?", m.Id).
+		Scan(ctx, &latest); err != nil {
+		return "", errors.Wrapf(err, "error getting the latest version of model %q", m.Name)
+	}
+	if mv.Version == latest {
+		return "is the latest version of the model", nil
+	}
+	return "", nil
+}
+
 // Query for all trials that use a given model_version and return their metrics.
 func (a *apiServer) GetTrialMetricsByModelVersion(
 	ctx context.Context, req *apiv1.GetTrialMetricsByModelVersionRequest,
@@ -825,13 +2552,15 @@ func (a *apiServer) GetTrialMetricsByModelVersion(
 	if err != nil {
 		return nil, err
 	}
-	curUser, _, err := grpcutil.GetUser(ctx)
+	curUser, err := modelRegistryUser(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if err := modelauth.AuthZProvider.Get().CanGetModel(ctx, *curUser, modelVersionResp.Model,
+	if err := modelauth.ForWorkspace(modelVersionResp.Model.WorkspaceId).CanGetModelVersion(
+		ctx, *curUser, modelVersionResp.Model, modelVersionResp,
 		modelVersionResp.Model.WorkspaceId); err != nil {
-		return nil, err
+		return nil, modelauth.PermissionDenied(err, *curUser, "get", fmt.Sprintf("model version %v:%v",
+			modelVersionResp.Model.Name, modelVersionResp.Version))
 	}
 	resp := &apiv1.GetTrialMetricsByModelVersionResponse{}
 	trialIDsQuery := db.Bun().NewSelect().Table("trial_source_infos").
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/api"
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/db"
+	modelauth "github.com/determined-ai/determined/master/internal/model"
+	"github.com/determined-ai/determined/master/internal/user"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+// modelForAccessGrants returns the model and the current user if the user may manage who is
+// granted access to the model, counting the request against the user's rate limit for action.
+func (a *apiServer) modelForAccessGrants(
+	ctx context.Context, modelName string, action string,
+) (*modelv1.Model, *model.User, error) {
+	currModel, err := a.ModelFromIdentifier(modelName)
+	if err != nil {
+		return nil, nil, err
+	}
+	curUser, err := modelRegistryUser(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := modelauth.ForWorkspace(currModel.WorkspaceId).CanManageModelAccess(ctx, *curUser,
+		currModel, currModel.WorkspaceId); err != nil {
+		return nil, nil, modelauth.PermissionDenied(err, *curUser, "manage access to",
+			fmt.Sprintf("model %q", currModel.Name))
+	}
+	if err := limitModelAction(ctx, *curUser, action); err != nil {
+		return nil, nil, err
+	}
+	return currModel, curUser, nil
+}
+
+func (a *apiServer) GetModelAccessGrants(
+	ctx context.Context, req *apiv1.GetModelAccessGrantsRequest,
+) (*apiv1.GetModelAccessGrantsResponse, error) {
+	currModel, _, err := a.modelForAccessGrants(ctx, req.ModelName, config.ModelRateLimitGet)
+	if err != nil {
+		return nil, err
+	}
+	grants, err := db.GetModelAccessGrants(ctx, currModel.Id)
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiv1.GetModelAccessGrantsResponse{
+		Grants: make([]*modelv1.ModelAccessGrant, 0, len(grants)),
+	}
+	for _, g := range grants {
+		resp.Grants = append(resp.Grants, g.Proto())
+	}
+	return resp, nil
+}
+
+func (a *apiServer) PutModelAccessGrant(
+	ctx context.Context, req *apiv1.PutModelAccessGrantRequest,
+) (*apiv1.PutModelAccessGrantResponse, error) {
+	currModel, curUser, err := a.modelForAccessGrants(ctx, req.ModelName,
+		config.ModelRateLimitEdit)
+	if err != nil {
+		return nil, err
+	}
+	if req.Access != modelv1.ModelAccessLevel_MODEL_ACCESS_LEVEL_READ &&
+		req.Access != modelv1.ModelAccessLevel_MODEL_ACCESS_LEVEL_EDIT {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported access %s", req.Access)
+	}
+
+	grantee, err := user.ByID(ctx, model.UserID(req.UserId))
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, status.Errorf(codes.InvalidArgument, "user %d does not exist", req.UserId)
+	} else if err != nil {
+		return nil, err
+	}
+	if !grantee.Active {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"user %q is not active and cannot be granted access to models", grantee.Username)
+	}
+
+	g := &model.ModelAccessGrant{
+		ModelID:     currModel.Id,
+		UserID:      grantee.ID,
+		Access:      model.ModelAccessLevelToDB(req.Access),
+		GrantedByID: &curUser.ID,
+	}
+	if err := db.PutModelAccessGrant(ctx, g); err != nil {
+		return nil, err
+	}
+	// Grants let users list models in workspaces they have no role in.
+	modelauth.InvalidateCanGetModelsCache()
+	log.Infof("user %q granted %s access to model %q by %q",
+		grantee.Username, g.Access, currModel.Name, curUser.Username)
+	return &apiv1.PutModelAccessGrantResponse{Grant: g.Proto()}, nil
+}
+
+func (a *apiServer) DeleteModelAccessGrant(
+	ctx context.Context, req *apiv1.DeleteModelAccessGrantRequest,
+) (*apiv1.DeleteModelAccessGrantResponse, error) {
+	currModel, curUser, err := a.modelForAccessGrants(ctx, req.ModelName,
+		config.ModelRateLimitDelete)
+	if err != nil {
+		return nil, err
+	}
+	switch err := db.DeleteModelAccessGrant(ctx, currModel.Id, model.UserID(req.UserId)); {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, api.NotFoundErrs(fmt.Sprintf("access grant of model %q for user",
+			currModel.Name), strconv.Itoa(int(req.UserId)), true)
+	case err != nil:
+		return nil, err
+	}
+	modelauth.InvalidateCanGetModelsCache()
+	log.Infof("access of user %d to model %q revoked by %q",
+		req.UserId, currModel.Name, curUser.Username)
+	return &apiv1.DeleteModelAccessGrantResponse{}, nil
+}
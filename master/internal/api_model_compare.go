@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/determined-ai/determined/master/internal/authz"
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/db"
+	modelauth "github.com/determined-ai/determined/master/internal/model"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+func (a *apiServer) CompareModelVersions(
+	ctx context.Context, req *apiv1.CompareModelVersionsRequest,
+) (*apiv1.CompareModelVersionsResponse, error) {
+	curUser, err := modelRegistryUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Hidden like in GetModel, so that users who cannot see the workspace cannot tell whether
+	// the model exists.
+	if err := checkModelWorkspaceAccess(ctx, *curUser, req.ModelName); err != nil {
+		return nil, err
+	}
+
+	m, err := a.ModelFromIdentifier(req.ModelName)
+	if err != nil {
+		return nil, err
+	}
+	if err = modelauth.ForWorkspace(m.WorkspaceId).CanGetModel(ctx, *curUser, m,
+		m.WorkspaceId); err != nil {
+		return nil, modelauth.PermissionDenied(err, *curUser, "get", fmt.Sprintf("model %q", m.Name))
+	}
+
+	changes, err := compareModelVersions(ctx, *curUser, m, req.VersionA, req.VersionB)
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1.CompareModelVersionsResponse{Changes: changes}, nil
+}
+
+// compareModelVersions returns how the metadata of versionB of m differs from that of versionA.
+// Versions curUser may not read fail with the same error as versions that do not exist. Only
+// the metadata of the versions is loaded, not their checkpoints.
+func compareModelVersions(
+	ctx context.Context, curUser model.User, m *modelv1.Model, versionA, versionB int32,
+) ([]*modelv1.ModelVersionMetadataChange, error) {
+	metadata, err := db.GetModelVersionsMetadata(ctx, m.Id, versionA, versionB)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int32]db.ModelVersionMetadata, len(metadata))
+	ids := make([]int32, 0, len(metadata))
+	for _, mv := range metadata {
+		byVersion[mv.Version] = mv
+		ids = append(ids, mv.ID)
+	}
+
+	modelAuthZ := modelauth.ForWorkspace(m.WorkspaceId)
+	var readableIDs []int32
+	if len(ids) > 0 {
+		readableQuery, err := modelAuthZ.FilterModelVersionsQuery(ctx, curUser, m,
+			db.Bun().NewSelect().TableExpr("model_versions AS mv").
+				Column("mv.id").
+				Where("mv.id IN (?)", bun.In(ids)))
+		if err != nil {
+			return nil, err
+		}
+		if err = readableQuery.Scan(ctx, &readableIDs); err != nil {
+			return nil, errors.Wrapf(err, "error filtering versions of model %q", m.Name)
+		}
+	}
+	notFound := func(version int32) error {
+		return status.Errorf(codes.NotFound, "version %v for model %q not found", version, m.Name)
+	}
+	for _, version := range []int32{versionA, versionB} {
+		mv, ok := byVersion[version]
+		if !ok || !slices.Contains(readableIDs, mv.ID) {
+			return nil, notFound(version)
+		}
+		err := modelAuthZ.CanGetModelVersion(ctx, curUser, m,
+			&modelv1.ModelVersion{Id: mv.ID, Version: mv.Version}, m.WorkspaceId)
+		if authz.IsPermissionDenied(err) {
+			return nil, notFound(version)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	if err := limitModelAction(ctx, curUser, config.ModelRateLimitGet); err != nil {
+		return nil, err
+	}
+
+	return diffModelVersionMetadata(byVersion[versionA].Metadata, byVersion[versionB].Metadata)
+}
+
+// diffModelVersionMetadata returns the top-level keys of before and after whose values differ,
+// by key. Numbers are compared as numbers, with the change as the delta, and other values,
+// including objects and lists, as a whole.
+func diffModelVersionMetadata(
+	before, after map[string]any,
+) ([]*modelv1.ModelVersionMetadataChange, error) {
+	keys := make([]string, 0, len(before)+len(after))
+	for k := range before {
+		keys = append(keys, k)
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := []*modelv1.ModelVersionMetadataChange{}
+	for _, k := range keys {
+		b, inBefore := before[k]
+		a, inAfter := after[k]
+		change := &modelv1.ModelVersionMetadataChange{Key: k}
+		switch {
+		case !inBefore:
+			change.Type = modelv1.ModelVersionMetadataChangeType_MODEL_VERSION_METADATA_CHANGE_TYPE_ADDED
+		case !inAfter:
+			change.Type = modelv1.ModelVersionMetadataChangeType_MODEL_VERSION_METADATA_CHANGE_TYPE_REMOVED
+		case reflect.DeepEqual(b, a):
+			continue
+		default:
+			change.Type = modelv1.ModelVersionMetadataChangeType_MODEL_VERSION_METADATA_CHANGE_TYPE_CHANGED
+			bn, bIsNumber := b.(float64)
+			an, aIsNumber := a.(float64)
+			if bIsNumber && aIsNumber {
+				change.Delta = ptrs.Ptr(an - bn)
+			}
+		}
+
+		var err error
+		if inBefore {
+			if change.Before, err = structpb.NewValue(b); err != nil {
+				return nil, errors.Wrapf(err, "error converting metadata key %q", k)
+			}
+		}
+		if inAfter {
+			if change.After, err = structpb.NewValue(a); err != nil {
+				return nil, errors.Wrapf(err, "error converting metadata key %q", k)
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+func TestDiffModelVersionMetadata(t *testing.T) {
+	before := map[string]any{
+		"accuracy":  0.5,
+		"framework": "torch",
+		"epochs":    float64(10),
+		"removed":   true,
+		"same":      map[string]any{"a": float64(1)},
+		"nested":    map[string]any{"a": float64(1)},
+		"retyped":   float64(1),
+	}
+	after := map[string]any{
+		"accuracy":  0.75,
+		"framework": "jax",
+		"epochs":    float64(10),
+		"added":     []any{"x"},
+		"same":      map[string]any{"a": float64(1)},
+		"nested":    map[string]any{"a": float64(2)},
+		"retyped":   "1",
+	}
+	changes, err := diffModelVersionMetadata(before, after)
+	require.NoError(t, err)
+
+	value := func(v any) *structpb.Value {
+		pb, err := structpb.NewValue(v)
+		require.NoError(t, err)
+		return pb
+	}
+	delta := 0.25
+	expected := []*modelv1.ModelVersionMetadataChange{
+		{
+			Key:    "accuracy",
+			Type:   modelv1.ModelVersionMetadataChangeType_MODEL_VERSION_METADATA_CHANGE_TYPE_CHANGED,
+			Before: value(0.5),
+			After:  value(0.75),
+			Delta:  &delta,
+		},
+		{
+			Key:   "added",
+			Type:  modelv1.ModelVersionMetadataChangeType_MODEL_VERSION_METADATA_CHANGE_TYPE_ADDED,
+			After: value([]any{"x"}),
+		},
+		{
+			Key:    "framework",
+			Type:   modelv1.ModelVersionMetadataChangeType_MODEL_VERSION_METADATA_CHANGE_TYPE_CHANGED,
+			Before: value("torch"),
+			After:  value("jax"),
+		},
+		{
+			Key:    "nested",
+			Type:   modelv1.ModelVersionMetadataChangeType_MODEL_VERSION_METADATA_CHANGE_TYPE_CHANGED,
+			Before: value(map[string]any{"a": float64(1)}),
+			After:  value(map[string]any{"a": float64(2)}),
+		},
+		{
+			Key:    "removed",
+			Type:   modelv1.ModelVersionMetadataChangeType_MODEL_VERSION_METADATA_CHANGE_TYPE_REMOVED,
+			Before: value(true),
+		},
+		{
+			Key:    "retyped",
+			Type:   modelv1.ModelVersionMetadataChangeType_MODEL_VERSION_METADATA_CHANGE_TYPE_CHANGED,
+			Before: value(float64(1)),
+			After:  value("1"),
+		},
+	}
+	require.Len(t, changes, len(expected))
+	for i := range expected {
+		require.Equal(t, expected[i].Key, changes[i].Key)
+		require.Equal(t, expected[i].Type, changes[i].Type, expected[i].Key)
+		require.Equal(t, expected[i].Before.AsInterface(), changes[i].Before.AsInterface(),
+			expected[i].Key)
+		require.Equal(t, expected[i].After.AsInterface(), changes[i].After.AsInterface(),
+			expected[i].Key)
+		require.Equal(t, expected[i].Delta, changes[i].Delta, expected[i].Key)
+	}
+
+	changes, err = diffModelVersionMetadata(before, before)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	changes, err = diffModelVersionMetadata(nil, nil)
+	require.NoError(t, err)
+	require.NotNil(t, changes)
+}
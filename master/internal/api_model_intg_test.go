@@ -0,0 +1,1092 @@
+//go:build integration
+// +build integration
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/determined-ai/determined/master/internal/authz"
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/db"
+	modelauth "github.com/determined-ai/determined/master/internal/model"
+	"github.com/determined-ai/determined/master/internal/rbac"
+	"github.com/determined-ai/determined/master/internal/user"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/master/pkg/ptrs"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+	"github.com/determined-ai/determined/proto/pkg/rbacv1"
+)
+
+func TestPostModelVersionConcurrent(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t, nil)
+	checkpointUUID := createVersionTwoCheckpoint(ctx, t, api, curUser, map[string]int64{"a": 1})
+	_, err := db.Bun().NewUpdate().Table("checkpoints_v2").
+		Set("state = ?", model.CompletedState).
+		Where("uuid = ?", checkpointUUID).
+		Exec(ctx)
+	require.NoError(t, err)
+
+	const n = 10
+	register := func(modelName, idempotencyKey string) []int32 {
+		var wg sync.WaitGroup
+		versions := make([]int32, n)
+		errs := make([]error, n)
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				resp, err := api.PostModelVersion(ctx, &apiv1.PostModelVersionRequest{
+					ModelName:      modelName,
+					CheckpointUuid: checkpointUUID,
+					IdempotencyKey: idempotencyKey,
+				})
+				if errs[i] = err; err == nil {
+					versions[i] = resp.ModelVersion.Version
+				}
+			}(i)
+		}
+		wg.Wait()
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+		return versions
+	}
+
+	t.Run("each registration gets a distinct number", func(t *testing.T) {
+		modelName := uuid.New().String()
+		_, err := api.PostModel(ctx, &apiv1.PostModelRequest{Name: modelName})
+		require.NoError(t, err)
+
+		expected := make([]int32, n)
+		for i := range expected {
+			expected[i] = int32(i + 1)
+		}
+		require.Equal(t, expected, register(modelName, ""))
+	})
+
+	t.Run("retries with one idempotency key register one version", func(t *testing.T) {
+		modelName := uuid.New().String()
+		_, err := api.PostModel(ctx, &apiv1.PostModelRequest{Name: modelName})
+		require.NoError(t, err)
+
+		for _, v := range register(modelName, uuid.New().String()) {
+			require.Equal(t, int32(1), v)
+		}
+	})
+}
+
+func TestPostModelVersionCap(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t, nil)
+	checkpointUUID := createVersionTwoCheckpoint(ctx, t, api, curUser, map[string]int64{"a": 1})
+	_, err := db.Bun().NewUpdate().Table("checkpoints_v2").
+		Set("state = ?", model.CompletedState).
+		Where("uuid = ?", checkpointUUID).
+		Exec(ctx)
+	require.NoError(t, err)
+
+	registry := &config.GetMasterConfig().ModelRegistry
+	defer func(r config.ModelRegistryConfig) { *registry = r }(*registry)
+
+	register := func(modelName string, labels ...string) (*apiv1.PostModelVersionResponse, error) {
+		return api.PostModelVersion(ctx, &apiv1.PostModelVersionRequest{
+			ModelName:      modelName,
+			CheckpointUuid: checkpointUUID,
+			Labels:         labels,
+		})
+	}
+	versions := func(modelName string) []int32 {
+		resp, err := api.GetModelVersions(ctx, &apiv1.GetModelVersionsRequest{
+			ModelName: modelName,
+			SortBy:    apiv1.GetModelVersionsRequest_SORT_BY_VERSION,
+			OrderBy:   apiv1.OrderBy_ORDER_BY_ASC,
+		})
+		require.NoError(t, err)
+		var nums []int32
+		for _, mv := range resp.ModelVersions {
+			nums = append(nums, mv.Version)
+		}
+		return nums
+	}
+	newModel := func() string {
+		modelName := uuid.New().String()
+		_, err := api.PostModel(ctx, &apiv1.PostModelRequest{Name: modelName})
+		require.NoError(t, err)
+		return modelName
+	}
+
+	t.Run("the default cap applies to models without their own", func(t *testing.T) {
+		registry.MaxVersionsPerModel = 0
+		modelName := newModel()
+		for i := 0; i < 3; i++ {
+			_, err := register(modelName)
+			require.NoError(t, err)
+		}
+
+		registry.MaxVersionsPerModel = 3
+		_, err := register(modelName)
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+		require.ErrorContains(t, err, "has 3 versions and may have at most 3")
+		require.Equal(t, []int32{1, 2, 3}, versions(modelName))
+	})
+
+	t.Run("models cap and prune their versions", func(t *testing.T) {
+		registry.MaxVersionsPerModel = 0
+		registry.ProtectedVersionLabels = []string{"production"}
+		registry.ProtectLatestVersion = false
+		modelName := newModel()
+		_, err := api.PatchModel(ctx, &apiv1.PatchModelRequest{
+			ModelName: modelName,
+			Model:     &modelv1.PatchModel{MaxVersions: ptrs.Ptr(int32(-1))},
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		patched, err := api.PatchModel(ctx, &apiv1.PatchModelRequest{
+			ModelName: modelName,
+			Model:     &modelv1.PatchModel{MaxVersions: ptrs.Ptr(int32(2))},
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(2), patched.Model.MaxVersions)
+
+		_, err = register(modelName, "production")
+		require.NoError(t, err)
+		_, err = register(modelName)
+		require.NoError(t, err)
+		_, err = register(modelName)
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+		_, err = api.PatchModel(ctx, &apiv1.PatchModelRequest{
+			ModelName: modelName,
+			Model:     &modelv1.PatchModel{PruneVersions: ptrs.Ptr(true)},
+		})
+		require.NoError(t, err)
+		resp, err := register(modelName)
+		require.NoError(t, err)
+		require.Equal(t, int32(3), resp.ModelVersion.Version)
+		require.Equal(t, []int32{2}, resp.PrunedVersions)
+		require.Equal(t, []int32{1, 3}, versions(modelName))
+
+		// Neither the labeled version nor the latest may be pruned, so nothing is.
+		registry.ProtectLatestVersion = true
+		_, err = register(modelName)
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+		require.ErrorContains(t, err, "only 0 of the 1")
+		require.Equal(t, []int32{1, 3}, versions(modelName))
+
+		registry.ProtectLatestVersion = false
+		resp, err = register(modelName)
+		require.NoError(t, err)
+		require.Equal(t, []int32{3}, resp.PrunedVersions)
+		require.Equal(t, []int32{1, 4}, versions(modelName))
+	})
+}
+
+func TestExportImportModel(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t, nil)
+	checkpointUUID := createVersionTwoCheckpoint(ctx, t, api, curUser, map[string]int64{"a": 1})
+	_, err := db.Bun().NewUpdate().Table("checkpoints_v2").
+		Set("state = ?", model.CompletedState).
+		Where("uuid = ?", checkpointUUID).
+		Exec(ctx)
+	require.NoError(t, err)
+
+	source := uuid.New().String()
+	_, err = api.PostModel(ctx, &apiv1.PostModelRequest{Name: source, Labels: []string{"vision"}})
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = api.PostModelVersion(ctx, &apiv1.PostModelVersionRequest{
+			ModelName:      source,
+			CheckpointUuid: checkpointUUID,
+		})
+		require.NoError(t, err)
+	}
+	_, err = api.DeleteModelVersion(ctx, &apiv1.DeleteModelVersionRequest{
+		ModelName:       source,
+		ModelVersionNum: 1,
+	})
+	require.NoError(t, err)
+
+	exported, err := api.ExportModel(ctx, &apiv1.ExportModelRequest{ModelName: source})
+	require.NoError(t, err)
+	again, err := api.ExportModel(ctx, &apiv1.ExportModelRequest{ModelName: source})
+	require.NoError(t, err)
+	require.Equal(t, exported.Manifest, again.Manifest)
+
+	t.Run("missing checkpoints import nothing", func(t *testing.T) {
+		missing := uuid.New().String()
+		name := uuid.New().String()
+		_, err := api.ImportModel(ctx, &apiv1.ImportModelRequest{
+			Manifest:        exported.Manifest,
+			WorkspaceId:     1,
+			Name:            &name,
+			CheckpointUuids: map[string]string{checkpointUUID: missing},
+		})
+		require.Equal(t, codes.NotFound, status.Code(err))
+		require.Contains(t, err.Error(), missing)
+		_, err = api.GetModel(ctx, &apiv1.GetModelRequest{ModelName: name})
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("import keeps version numbers", func(t *testing.T) {
+		name := uuid.New().String()
+		imported, err := api.ImportModel(ctx, &apiv1.ImportModelRequest{
+			Manifest:    exported.Manifest,
+			WorkspaceId: 1,
+			Name:        &name,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"vision"}, imported.Model.Labels)
+
+		versions, err := api.GetModelVersions(ctx, &apiv1.GetModelVersionsRequest{ModelName: name})
+		require.NoError(t, err)
+		require.Len(t, versions.ModelVersions, 1)
+		require.Equal(t, int32(2), versions.ModelVersions[0].Version)
+
+		next, err := api.PostModelVersion(ctx, &apiv1.PostModelVersionRequest{
+			ModelName:      name,
+			CheckpointUuid: checkpointUUID,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(3), next.ModelVersion.Version)
+	})
+}
+
+func TestGetCheckpointModelVersionsAuthZ(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t, nil)
+	checkpointUUID := createVersionTwoCheckpoint(ctx, t, api, curUser, map[string]int64{"a": 1})
+
+	// The user is an admin, who may view every model under basic authz, but has no role in the
+	// workspace that uses RBAC.
+	restrictedWorkspaceID, _ := db.RequireMockWorkspaceID(t, api.m.db, "")
+	resolver := modelauth.WorkspaceResolver
+	defer func() { modelauth.WorkspaceResolver = resolver }()
+	modelauth.WorkspaceResolver = func(workspaceID int32) (string, bool) {
+		return "rbac", workspaceID == int32(restrictedWorkspaceID)
+	}
+
+	otherCheckpointUUID := createVersionTwoCheckpoint(ctx, t, api, curUser,
+		map[string]int64{"b": 1})
+	var visible []int32
+	for i, workspaceID := range []int{1, restrictedWorkspaceID, 1} {
+		m, err := db.InsertModelTx(ctx, db.Bun(), uuid.NewString(), "", []byte(`{}`), "", "",
+			curUser.ID, workspaceID, modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE)
+		require.NoError(t, err)
+		for v := 0; v < i+1; v++ {
+			_, err = db.InsertModelVersion(ctx, m.Id, checkpointUUID, "", "", []byte(`{}`), "",
+				"", curUser.ID)
+			require.NoError(t, err)
+			if workspaceID != restrictedWorkspaceID {
+				visible = append(visible, m.Id)
+			}
+		}
+		// Versions of the same models registered from other checkpoints are not returned.
+		_, err = db.InsertModelVersion(ctx, m.Id, otherCheckpointUUID, "", "", []byte(`{}`), "",
+			"", curUser.ID)
+		require.NoError(t, err)
+	}
+
+	resp, err := api.GetCheckpointModelVersions(ctx, &apiv1.GetCheckpointModelVersionsRequest{
+		CheckpointUuid: checkpointUUID,
+	})
+	require.NoError(t, err)
+	var got []int32
+	for _, mv := range resp.ModelVersions {
+		require.Equal(t, checkpointUUID, mv.Checkpoint.Uuid)
+		got = append(got, mv.Model.Id)
+	}
+	require.Equal(t, visible, got, "versions of the restricted model are left out")
+	require.Equal(t, int32(len(visible)), resp.Pagination.Total)
+
+	resp, err = api.GetCheckpointModelVersions(ctx, &apiv1.GetCheckpointModelVersionsRequest{
+		CheckpointUuid: checkpointUUID,
+		Limit:          1,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.ModelVersions, 1)
+	require.Equal(t, int32(len(visible)), resp.Pagination.Total)
+
+	resp, err = api.GetCheckpointModelVersions(ctx, &apiv1.GetCheckpointModelVersionsRequest{
+		CheckpointUuid: checkpointUUID,
+		Offset:         int32(len(visible) - 1),
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.ModelVersions, 1)
+	require.Equal(t, visible[len(visible)-1], resp.ModelVersions[0].Model.Id)
+	require.Equal(t, int32(len(visible)), resp.Pagination.Total)
+
+	resp, err = api.GetCheckpointModelVersions(ctx, &apiv1.GetCheckpointModelVersionsRequest{
+		CheckpointUuid: uuid.NewString(),
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.ModelVersions)
+}
+
+// modelTestUserCtx adds a user who is not an admin, and returns them with a context of their
+// session.
+func modelTestUserCtx(t *testing.T, api *apiServer) (model.User, context.Context) {
+	u := model.User{Username: uuid.NewString(), Active: true}
+	id, err := user.Add(context.TODO(), &u, nil)
+	require.NoError(t, err)
+	u.ID = id
+	resp, err := api.Login(context.TODO(), &apiv1.LoginRequest{Username: u.Username})
+	require.NoError(t, err)
+	return u, metadata.NewIncomingContext(context.TODO(),
+		metadata.Pairs("x-user-token", fmt.Sprintf("Bearer %s", resp.Token)))
+}
+
+func TestPrivateModelVersionsRBAC(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t, nil)
+	checkpointUUID := createVersionTwoCheckpoint(ctx, t, api, curUser, map[string]int64{"a": 1})
+
+	workspaceID, _ := db.RequireMockWorkspaceID(t, api.m.db, "")
+	resolver := modelauth.WorkspaceResolver
+	defer func() { modelauth.WorkspaceResolver = resolver }()
+	modelauth.WorkspaceResolver = func(id int32) (string, bool) {
+		return "rbac", id == int32(workspaceID)
+	}
+
+	// The owner is an admin without roles, so they are made a Viewer, who may also view the
+	// artifacts of the checkpoint.
+	const viewerRoleID, modelRegistryViewerRoleID = 4, 6
+	viewer, viewerCtx := modelTestUserCtx(t, api)
+	require.NoError(t, rbac.AddRoleAssignments(ctx, nil, []*rbacv1.UserRoleAssignment{{
+		UserId: int32(curUser.ID),
+		RoleAssignment: &rbacv1.RoleAssignment{
+			Role:         &rbacv1.Role{RoleId: viewerRoleID},
+			ScopeCluster: true,
+		},
+	}, {
+		UserId: int32(viewer.ID),
+		RoleAssignment: &rbacv1.RoleAssignment{
+			Role:             &rbacv1.Role{RoleId: modelRegistryViewerRoleID},
+			ScopeWorkspaceId: ptrs.Ptr(int32(workspaceID)),
+		},
+	}}))
+
+	insert := func(visibility modelv1.ModelVisibility) *modelv1.Model {
+		m, err := db.InsertModelTx(ctx, db.Bun(), uuid.NewString(), "", []byte(`{}`), "", "",
+			curUser.ID, workspaceID, visibility)
+		require.NoError(t, err)
+		_, err = db.InsertModelVersion(ctx, m.Id, checkpointUUID, "", "", []byte(`{}`), "", "",
+			curUser.ID)
+		require.NoError(t, err)
+		return m
+	}
+	shared := insert(modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE)
+	private := insert(modelv1.ModelVisibility_MODEL_VISIBILITY_PRIVATE)
+
+	_, err := api.GetModelVersions(viewerCtx, &apiv1.GetModelVersionsRequest{
+		ModelName: shared.Name,
+	})
+	require.NoError(t, err)
+
+	_, err = api.GetModelVersions(viewerCtx, &apiv1.GetModelVersionsRequest{
+		ModelName: private.Name,
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = api.GetModelVersion(viewerCtx, &apiv1.GetModelVersionRequest{
+		ModelName:       private.Name,
+		ModelVersionNum: 1,
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = api.GetTrialMetricsByModelVersion(viewerCtx,
+		&apiv1.GetTrialMetricsByModelVersionRequest{ModelName: private.Name, ModelVersionNum: 1})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// The owner still sees the versions of their private model.
+	resp, err := api.GetModelVersions(ctx, &apiv1.GetModelVersionsRequest{
+		ModelName: private.Name,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.ModelVersions, 1)
+}
+
+func TestPrivateModelMutationsRBAC(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t, nil)
+
+	workspaceID, _ := db.RequireMockWorkspaceID(t, api.m.db, "")
+	resolver := modelauth.WorkspaceResolver
+	defer func() { modelauth.WorkspaceResolver = resolver }()
+	modelauth.WorkspaceResolver = func(id int32) (string, bool) {
+		return "rbac", id == int32(workspaceID)
+	}
+
+	const workspaceAdminRoleID = 2
+	editor, editorCtx := modelTestUserCtx(t, api)
+	require.NoError(t, rbac.AddRoleAssignments(ctx, nil, []*rbacv1.UserRoleAssignment{{
+		UserId: int32(editor.ID),
+		RoleAssignment: &rbacv1.RoleAssignment{
+			Role:             &rbacv1.Role{RoleId: workspaceAdminRoleID},
+			ScopeWorkspaceId: ptrs.Ptr(int32(workspaceID)),
+		},
+	}}))
+
+	insert := func(visibility modelv1.ModelVisibility) *modelv1.Model {
+		m, err := db.InsertModelTx(ctx, db.Bun(), uuid.NewString(), "", []byte(`{}`), "", "",
+			curUser.ID, workspaceID, visibility)
+		require.NoError(t, err)
+		return m
+	}
+	shared := insert(modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE)
+	private := insert(modelv1.ModelVisibility_MODEL_VISIBILITY_PRIVATE)
+
+	patch := func(m *modelv1.Model) error {
+		_, err := api.PatchModel(editorCtx, &apiv1.PatchModelRequest{
+			ModelName: m.Name,
+			Model:     &modelv1.PatchModel{Description: wrapperspb.String("edited")},
+		})
+		return err
+	}
+	require.NoError(t, patch(shared))
+
+	// Other users in the workspace can't tell a private model from a missing one.
+	require.Equal(t, codes.NotFound, status.Code(patch(private)))
+	_, err := api.PutModelTag(editorCtx, &apiv1.PutModelTagRequest{
+		ModelName: private.Name, Key: "team", Value: "vision",
+	})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	_, err = api.ArchiveModel(editorCtx, &apiv1.ArchiveModelRequest{ModelName: private.Name})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	_, err = api.DeleteModel(editorCtx, &apiv1.DeleteModelRequest{ModelName: private.Name})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	m, err := api.ModelFromIdentifier(private.Name)
+	require.NoError(t, err)
+	require.Empty(t, m.Description)
+	require.False(t, m.Archived)
+}
+
+func TestGetModelsPerWorkspaceAuthZ(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t, nil)
+
+	workspaceID, _ := db.RequireMockWorkspaceID(t, api.m.db, "")
+	resolver := modelauth.WorkspaceResolver
+	defer func() { modelauth.WorkspaceResolver = resolver }()
+	modelauth.WorkspaceResolver = func(id int32) (string, bool) {
+		return "rbac", id == int32(workspaceID)
+	}
+
+	const modelRegistryViewerRoleID = 6
+	viewer, viewerCtx := modelTestUserCtx(t, api)
+	require.NoError(t, rbac.AddRoleAssignments(ctx, nil, []*rbacv1.UserRoleAssignment{{
+		UserId: int32(viewer.ID),
+		RoleAssignment: &rbacv1.RoleAssignment{
+			Role:             &rbacv1.Role{RoleId: modelRegistryViewerRoleID},
+			ScopeWorkspaceId: ptrs.Ptr(int32(workspaceID)),
+		},
+	}}))
+
+	label := uuid.NewString()
+	privateLabel := uuid.NewString()
+	insert := func(
+		workspaceID int, visibility modelv1.ModelVisibility, labels string,
+	) *modelv1.Model {
+		m, err := db.InsertModelTx(ctx, db.Bun(), uuid.NewString(), "", []byte(`{}`), labels, "",
+			curUser.ID, workspaceID, visibility)
+		require.NoError(t, err)
+		return m
+	}
+	shared := insert(workspaceID, modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE, label)
+	private := insert(workspaceID, modelv1.ModelVisibility_MODEL_VISIBILITY_PRIVATE,
+		label+","+privateLabel)
+	// The default workspace keeps basic authz, which doesn't hide private models.
+	basic := insert(1, modelv1.ModelVisibility_MODEL_VISIBILITY_PRIVATE, label)
+
+	for _, workspaceIDs := range [][]int32{nil, {int32(workspaceID), 1}} {
+		resp, err := api.GetModels(viewerCtx, &apiv1.GetModelsRequest{
+			WorkspaceIds: workspaceIDs,
+			Labels:       []string{label},
+		})
+		require.NoError(t, err)
+		var ids []int32
+		for _, m := range resp.Models {
+			ids = append(ids, m.Id)
+		}
+		require.ElementsMatch(t, []int32{shared.Id, basic.Id}, ids, workspaceIDs)
+	}
+
+	byIDs, err := api.GetModelsByIds(viewerCtx, &apiv1.GetModelsByIdsRequest{
+		ModelIds: []int32{shared.Id, private.Id, basic.Id},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int32{private.Id}, byIDs.MissingModelIds)
+
+	labels, err := api.GetModelLabels(viewerCtx, &apiv1.GetModelLabelsRequest{})
+	require.NoError(t, err)
+	require.Contains(t, labels.Labels, label)
+	require.NotContains(t, labels.Labels, privateLabel)
+}
+
+func TestCopyModelChecksVersions(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t, nil)
+	checkpointUUID := createVersionTwoCheckpoint(ctx, t, api, curUser, map[string]int64{"a": 1})
+
+	registry := &config.GetMasterConfig().ModelRegistry
+	defer func(r config.ModelRegistryConfig) { *registry = r }(*registry)
+
+	source, err := db.InsertModelTx(ctx, db.Bun(), uuid.NewString(), "", []byte(`{}`), "", "",
+		curUser.ID, 1, modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = db.InsertModelVersion(ctx, source.Id, checkpointUUID, "", "", []byte(`{}`), "", "",
+			curUser.ID)
+		require.NoError(t, err)
+	}
+	copyModel := func(workspaceID int32, versions ...int32) (string, error) {
+		name := uuid.NewString()
+		_, err := api.CopyModel(ctx, &apiv1.CopyModelRequest{
+			ModelName:   source.Name,
+			Name:        &name,
+			WorkspaceId: &workspaceID,
+			Versions:    versions,
+		})
+		return name, err
+	}
+
+	registry.MaxVersionsPerModel = 1
+	_, err = copyModel(1)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	name, err := copyModel(1, 2)
+	require.NoError(t, err)
+	resp, err := api.GetModelVersions(ctx, &apiv1.GetModelVersionsRequest{ModelName: name})
+	require.NoError(t, err)
+	require.Len(t, resp.ModelVersions, 1)
+	registry.MaxVersionsPerModel = 0
+
+	// The destination workspace decides whether the checkpoints may be registered there.
+	authZModel := getMockModelAuth()
+	workspaceID, _ := db.RequireMockWorkspaceID(t, api.m.db, "")
+	resolver := modelauth.WorkspaceResolver
+	defer func() { modelauth.WorkspaceResolver = resolver }()
+	modelauth.WorkspaceResolver = func(id int32) (string, bool) {
+		return "mock", id == int32(workspaceID)
+	}
+	authZModel.On("CanCreateModel", mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything).Return(nil).Once()
+	authZModel.On("CanCreateModelVersionFromCheckpoint", mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything, checkpointUUID, mock.Anything).
+		Return(authz.PermissionDeniedError{}).Once()
+	name, err = copyModel(int32(workspaceID))
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = api.ModelFromIdentifier(name)
+	require.Equal(t, codes.NotFound, status.Code(err), "the copy is rolled back")
+}
+
+func TestModelNamesConflictAfterNormalization(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t, nil)
+	suffix := uuid.NewString()
+	_, err := api.PostModel(ctx, &apiv1.PostModelRequest{Name: "Cafe\u0301 " + suffix})
+	require.NoError(t, err)
+
+	name := "caf\u00e9 " + suffix
+	_, err = api.PostModel(ctx, &apiv1.PostModelRequest{Name: name})
+	var nameErr *modelauth.ModelNameError
+	require.ErrorAs(t, err, &nameErr)
+	require.Equal(t, modelauth.NormalizedNameConflictRule, nameErr.Rule)
+
+	// A conflicting model created after the check is rejected by the insert.
+	_, err = db.InsertModelTx(ctx, db.Bun(), name, "", []byte(`{}`), "", "", curUser.ID, 1,
+		modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE)
+	require.ErrorAs(t, modelNameConflictError(name, err), &nameErr)
+}
+
+func TestArchivedModelRejectsChanges(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t, nil)
+	checkpointUUID := createVersionTwoCheckpoint(ctx, t, api, curUser, map[string]int64{"a": 1})
+
+	modelName := uuid.NewString()
+	_, err := api.PostModel(ctx, &apiv1.PostModelRequest{Name: modelName, Labels: []string{"a"}})
+	require.NoError(t, err)
+	_, err = api.PostModelVersion(ctx, &apiv1.PostModelVersionRequest{
+		ModelName:      modelName,
+		CheckpointUuid: checkpointUUID,
+	})
+	require.NoError(t, err)
+	_, err = api.ArchiveModel(ctx, &apiv1.ArchiveModelRequest{ModelName: modelName})
+	require.NoError(t, err)
+
+	_, err = api.PatchModel(ctx, &apiv1.PatchModelRequest{
+		ModelName: modelName,
+		Model:     &modelv1.PatchModel{Description: wrapperspb.String("new")},
+	})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = api.PutModelLabel(ctx, &apiv1.PutModelLabelRequest{ModelName: modelName, Label: "b"})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = api.DeleteModelLabel(ctx, &apiv1.DeleteModelLabelRequest{
+		ModelName: modelName, Label: "a",
+	})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = api.PutModelTag(ctx, &apiv1.PutModelTagRequest{
+		ModelName: modelName, Key: "team", Value: "vision",
+	})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = api.DeleteModelTag(ctx, &apiv1.DeleteModelTagRequest{
+		ModelName: modelName, Key: "team",
+	})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = api.PostModelVersion(ctx, &apiv1.PostModelVersionRequest{
+		ModelName:      modelName,
+		CheckpointUuid: checkpointUUID,
+	})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = api.PatchModelVersion(ctx, &apiv1.PatchModelVersionRequest{
+		ModelName:       modelName,
+		ModelVersionNum: 1,
+		ModelVersion:    &modelv1.PatchModelVersion{Comment: wrapperspb.String("new")},
+	})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestGetModelsFailsClosed(t *testing.T) {
+	api, _, ctx := setupAPITest(t, nil)
+	authZModel := getMockModelAuth()
+
+	workspaceID, _ := db.RequireMockWorkspaceID(t, api.m.db, "")
+	resolver := modelauth.WorkspaceResolver
+	defer func() { modelauth.WorkspaceResolver = resolver }()
+	modelauth.WorkspaceResolver = func(id int32) (string, bool) {
+		return "mock", id == int32(workspaceID)
+	}
+
+	filterErr := fmt.Errorf("permission summary unavailable")
+	authZModel.On("CanGetModelsByLabel", mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything).Return([]int32{int32(workspaceID)}, nil, nil).Once()
+	authZModel.On("FilterReadableModelsQuery", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, filterErr).Once()
+	_, err := api.GetModels(ctx, &apiv1.GetModelsRequest{
+		WorkspaceIds: []int32{int32(workspaceID)},
+	})
+	require.ErrorIs(t, err, filterErr, "models must not be listed by workspace alone")
+}
+
+func TestGetModelsRestrictsToAllowedWorkspaces(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t, nil)
+	authZModel := getMockModelAuth()
+
+	workspaceID, _ := db.RequireMockWorkspaceID(t, api.m.db, "")
+	_, err := db.InsertModelTx(ctx, db.Bun(), uuid.NewString(), "", []byte(`{}`), "", "",
+		curUser.ID, workspaceID, modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE)
+	require.NoError(t, err)
+	resolver := modelauth.WorkspaceResolver
+	defer func() { modelauth.WorkspaceResolver = resolver }()
+	modelauth.WorkspaceResolver = func(id int32) (string, bool) {
+		return "mock", id == int32(workspaceID)
+	}
+
+	getModels := func(allowed []int32) []*modelv1.Model {
+		authZModel.On("CanGetModelsByLabel", mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything).Return(allowed, nil, nil).Once()
+		authZModel.On("FilterReadableModelsQuery", mock.Anything, mock.Anything, mock.Anything).
+			Return(func(_ context.Context, _ model.User, q *bun.SelectQuery) *bun.SelectQuery {
+				return q
+			}, nil).Once()
+		resp, err := api.GetModels(ctx, &apiv1.GetModelsRequest{
+			WorkspaceIds: []int32{int32(workspaceID)},
+		})
+		require.NoError(t, err)
+		return resp.Models
+	}
+	require.Len(t, getModels(nil), 1, "nil allows every workspace")
+	require.Len(t, getModels([]int32{int32(workspaceID)}), 1)
+	require.Empty(t, getModels([]int32{}), "no workspace is allowed")
+}
+
+func TestCompareModelVersions(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t, nil)
+	checkpointUUID := createVersionTwoCheckpoint(ctx, t, api, curUser, map[string]int64{"a": 1})
+	_, err := db.Bun().NewUpdate().Table("checkpoints_v2").
+		Set("state = ?", model.CompletedState).
+		Where("uuid = ?", checkpointUUID).
+		Exec(ctx)
+	require.NoError(t, err)
+
+	modelName := uuid.New().String()
+	_, err = api.PostModel(ctx, &apiv1.PostModelRequest{Name: modelName})
+	require.NoError(t, err)
+	for _, metadata := range []map[string]any{
+		{"accuracy": 0.5, "framework": "torch"},
+		{"accuracy": 0.75, "dataset": "v2"},
+	} {
+		pb, err := structpb.NewStruct(metadata)
+		require.NoError(t, err)
+		_, err = api.PostModelVersion(ctx, &apiv1.PostModelVersionRequest{
+			ModelName:      modelName,
+			CheckpointUuid: checkpointUUID,
+			Metadata:       pb,
+		})
+		require.NoError(t, err)
+	}
+
+	resp, err := api.CompareModelVersions(ctx, &apiv1.CompareModelVersionsRequest{
+		ModelName: modelName,
+		VersionA:  1,
+		VersionB:  2,
+	})
+	require.NoError(t, err)
+	var keys []string
+	for _, c := range resp.Changes {
+		keys = append(keys, c.Key)
+	}
+	require.Equal(t, []string{"accuracy", "dataset", "framework"}, keys)
+	require.Equal(t, 0.25, resp.Changes[0].GetDelta())
+	require.Equal(t, modelv1.ModelVersionMetadataChangeType_MODEL_VERSION_METADATA_CHANGE_TYPE_ADDED,
+		resp.Changes[1].Type)
+	require.Equal(t,
+		modelv1.ModelVersionMetadataChangeType_MODEL_VERSION_METADATA_CHANGE_TYPE_REMOVED,
+		resp.Changes[2].Type)
+
+	_, err = api.CompareModelVersions(ctx, &apiv1.CompareModelVersionsRequest{
+		ModelName: modelName,
+		VersionA:  1,
+		VersionB:  3,
+	})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.ErrorContains(t, err, "version 3")
+}
+
+func TestModelMetadataLimitsOverLimitModel(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t, nil)
+	modelName := uuid.New().String()
+	_, err := db.InsertModelTx(ctx, db.Bun(), modelName, "", []byte(`{"blob":"0123456789"}`),
+		"a,b", "", curUser.ID, 1, modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE)
+	require.NoError(t, err)
+
+	registry := &config.GetMasterConfig().ModelRegistry
+	defer func(r config.ModelRegistryConfig) { *registry = r }(*registry)
+	registry.MaxMetadataBytes, registry.MaxLabels = 10, 1
+
+	// Models stored before the limits were lowered can be read and otherwise edited.
+	_, err = api.GetModel(ctx, &apiv1.GetModelRequest{ModelName: modelName})
+	require.NoError(t, err)
+	_, err = api.PatchModel(ctx, &apiv1.PatchModelRequest{
+		ModelName: modelName,
+		Model:     &modelv1.PatchModel{Description: wrapperspb.String("still editable")},
+	})
+	require.NoError(t, err)
+
+	metadata, err := structpb.NewStruct(map[string]any{"blob": "0123456789"})
+	require.NoError(t, err)
+	_, err = api.PatchModel(ctx, &apiv1.PatchModelRequest{
+		ModelName: modelName,
+		Model:     &modelv1.PatchModel{Metadata: metadata},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = api.PutModelLabel(ctx, &apiv1.PutModelLabelRequest{ModelName: modelName, Label: "c"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = api.PostModel(ctx, &apiv1.PostModelRequest{
+		Name:     uuid.New().String(),
+		Metadata: metadata,
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetModelsTiesAreStable(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t, nil)
+	label := uuid.New().String()
+	var ids []int32
+	for i := 0; i < 4; i++ {
+		m, err := db.InsertModelTx(ctx, db.Bun(), uuid.New().String(), "same", []byte(`{"k":1}`),
+			label, "", curUser.ID, 1, modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE)
+		require.NoError(t, err)
+		ids = append(ids, m.Id)
+	}
+	// Give the models the same timestamps too, so that every sort option ties.
+	_, err := db.Bun().NewUpdate().Table("models").
+		Set("creation_time = '2024-05-01', last_updated_time = '2024-05-01'").
+		Where("id IN (?)", bun.In(ids)).Exec(ctx)
+	require.NoError(t, err)
+	descIDs := slices.Clone(ids)
+	slices.Reverse(descIDs)
+
+	for sortBy := range apiv1.GetModelsRequest_SortBy_name {
+		if sortBy == int32(apiv1.GetModelsRequest_SORT_BY_NAME) {
+			continue // Names are unique.
+		}
+		for _, orderBy := range []apiv1.OrderBy{apiv1.OrderBy_ORDER_BY_ASC, apiv1.OrderBy_ORDER_BY_DESC} {
+			req := &apiv1.GetModelsRequest{
+				SortBy:  apiv1.GetModelsRequest_SortBy(sortBy),
+				OrderBy: orderBy,
+				Labels:  []string{label},
+			}
+			if req.SortBy == apiv1.GetModelsRequest_SORT_BY_METADATA {
+				req.SortByMetadataKey = "k"
+			}
+			want := ids
+			if orderBy == apiv1.OrderBy_ORDER_BY_DESC {
+				want = descIDs
+			}
+
+			var byOffset, byCursor []int32
+			cursor := ""
+			for i := 0; i < len(ids); i++ {
+				req.Offset, req.Limit, req.Cursor = int32(i), 1, nil
+				resp, err := api.GetModels(ctx, req)
+				require.NoError(t, err)
+				require.Len(t, resp.Models, 1)
+				byOffset = append(byOffset, resp.Models[0].Id)
+
+				req.Offset, req.Cursor = 0, &cursor
+				resp, err = api.GetModels(ctx, req)
+				require.NoError(t, err)
+				require.Len(t, resp.Models, 1)
+				byCursor = append(byCursor, resp.Models[0].Id)
+				cursor = resp.NextCursor
+			}
+			require.Equal(t, want, byOffset, "%s %s by offset", req.SortBy, orderBy)
+			require.Equal(t, want, byCursor, "%s %s by cursor", req.SortBy, orderBy)
+			require.Empty(t, cursor, "%s %s", req.SortBy, orderBy)
+		}
+	}
+}
+
+func TestStreamModels(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t, nil)
+	label := uuid.New().String()
+	for _, description := range []string{"c", "a", "e", "b", "d"} {
+		_, err := db.InsertModelTx(ctx, db.Bun(), uuid.New().String(), description, []byte(`{}`),
+			label, "", curUser.ID, 1, modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE)
+		require.NoError(t, err)
+	}
+	filter := &apiv1.GetModelsRequest{
+		SortBy:  apiv1.GetModelsRequest_SORT_BY_DESCRIPTION,
+		OrderBy: apiv1.OrderBy_ORDER_BY_DESC,
+		Labels:  []string{label},
+	}
+	listed, err := api.GetModels(ctx, filter)
+	require.NoError(t, err)
+	var want []int32
+	for _, m := range listed.Models {
+		want = append(want, m.Id)
+	}
+	require.Len(t, want, 5)
+
+	stream := func(filter *apiv1.GetModelsRequest, batchSize int32) (
+		[]*apiv1.StreamModelsResponse, []int32,
+	) {
+		resp := &mockStream[*apiv1.StreamModelsResponse]{ctx: ctx}
+		require.NoError(t, api.StreamModels(&apiv1.StreamModelsRequest{
+			Filter:    filter,
+			BatchSize: batchSize,
+		}, resp))
+		var ids []int32
+		for _, batch := range resp.getData() {
+			for _, m := range batch.Models {
+				ids = append(ids, m.Id)
+			}
+		}
+		return resp.getData(), ids
+	}
+
+	batches, ids := stream(filter, 2)
+	require.Equal(t, want, ids)
+	require.Len(t, batches, 3)
+	require.Len(t, batches[2].Models, 1)
+
+	// A batch that ends the models exactly is not followed by an empty one.
+	batches, ids = stream(filter, 5)
+	require.Equal(t, want, ids)
+	require.Len(t, batches, 1)
+
+	t.Run("streams resume from a cursor", func(t *testing.T) {
+		batches, _ := stream(filter, 2)
+		resumed := proto.Clone(filter).(*apiv1.GetModelsRequest)
+		resumed.Cursor = &batches[0].Cursor
+		_, ids := stream(resumed, 2)
+		require.Equal(t, want[2:], ids)
+
+		resumed.Limit = 10
+		page, err := api.GetModels(ctx, resumed)
+		require.NoError(t, err)
+		require.Len(t, page.Models, 3)
+		require.Equal(t, want[2], page.Models[0].Id)
+	})
+
+	t.Run("pagination and batch sizes are validated", func(t *testing.T) {
+		resp := &mockStream[*apiv1.StreamModelsResponse]{ctx: ctx}
+		err := api.StreamModels(&apiv1.StreamModelsRequest{
+			Filter: &apiv1.GetModelsRequest{Offset: 1},
+		}, resp)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		err = api.StreamModels(&apiv1.StreamModelsRequest{BatchSize: -1}, resp)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		require.Empty(t, resp.getData())
+	})
+}
+
+func TestModelAccessGrantsAPI(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t, nil)
+	m, err := db.InsertModelTx(ctx, db.Bun(), uuid.New().String(), "", []byte(`{}`), "", "",
+		curUser.ID, 1, modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE)
+	require.NoError(t, err)
+	addUser := func(active bool) model.UserID {
+		id, err := user.Add(ctx, &model.User{Username: uuid.New().String(), Active: active}, nil)
+		require.NoError(t, err)
+		return id
+	}
+	grantee, inactive := addUser(true), addUser(false)
+
+	put := func(userID model.UserID, access modelv1.ModelAccessLevel) error {
+		_, err := api.PutModelAccessGrant(ctx, &apiv1.PutModelAccessGrantRequest{
+			ModelName: m.Name, UserId: int32(userID), Access: access,
+		})
+		return err
+	}
+	require.Equal(t, codes.InvalidArgument,
+		status.Code(put(inactive, modelv1.ModelAccessLevel_MODEL_ACCESS_LEVEL_READ)))
+	require.Equal(t, codes.InvalidArgument,
+		status.Code(put(-1, modelv1.ModelAccessLevel_MODEL_ACCESS_LEVEL_READ)))
+	require.Equal(t, codes.InvalidArgument,
+		status.Code(put(grantee, modelv1.ModelAccessLevel_MODEL_ACCESS_LEVEL_UNSPECIFIED)))
+
+	require.NoError(t, put(grantee, modelv1.ModelAccessLevel_MODEL_ACCESS_LEVEL_READ))
+	require.NoError(t, put(grantee, modelv1.ModelAccessLevel_MODEL_ACCESS_LEVEL_EDIT))
+	resp, err := api.GetModelAccessGrants(ctx, &apiv1.GetModelAccessGrantsRequest{
+		ModelName: m.Name,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Grants, 1, "granting again replaces the grant")
+	require.Equal(t, int32(grantee), resp.Grants[0].UserId)
+	require.Equal(t, modelv1.ModelAccessLevel_MODEL_ACCESS_LEVEL_EDIT, resp.Grants[0].Access)
+	require.Equal(t, int32(curUser.ID), resp.Grants[0].GetGrantedById())
+
+	deleteReq := &apiv1.DeleteModelAccessGrantRequest{ModelName: m.Name, UserId: int32(grantee)}
+	_, err = api.DeleteModelAccessGrant(ctx, deleteReq)
+	require.NoError(t, err)
+	_, err = api.DeleteModelAccessGrant(ctx, deleteReq)
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestModelResponsesMaskRestrictedMetadata(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t, nil)
+
+	workspaceID, _ := db.RequireMockWorkspaceID(t, api.m.db, "")
+	resolver := modelauth.WorkspaceResolver
+	defer func() { modelauth.WorkspaceResolver = resolver }()
+	modelauth.WorkspaceResolver = func(id int32) (string, bool) {
+		return "rbac", id == int32(workspaceID)
+	}
+	registry := &config.GetMasterConfig().ModelRegistry
+	prevKeys := registry.RestrictedMetadataKeys
+	defer func() { registry.RestrictedMetadataKeys = prevKeys }()
+	registry.RestrictedMetadataKeys = []string{"owner_email"}
+
+	m, err := db.InsertModelTx(ctx, db.Bun(), uuid.NewString(), "",
+		[]byte(`{"owner_email":"someone@example.com","framework":"torch"}`), "", "",
+		curUser.ID, workspaceID, modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE)
+	require.NoError(t, err)
+
+	// An edit grant lets the user change the model without seeing its restricted metadata.
+	editor, editorCtx := modelTestUserCtx(t, api)
+	require.NoError(t, db.PutModelAccessGrant(ctx, &model.ModelAccessGrant{
+		ModelID: m.Id, UserID: editor.ID, Access: model.ModelAccessEdit,
+	}))
+
+	labeled, err := api.PutModelLabel(editorCtx, &apiv1.PutModelLabelRequest{
+		ModelName: m.Name, Label: "a",
+	})
+	require.NoError(t, err)
+	require.NotContains(t, labeled.Model.Metadata.Fields, "owner_email")
+	require.Contains(t, labeled.Model.Metadata.Fields, "framework")
+
+	unlabeled, err := api.DeleteModelLabel(editorCtx, &apiv1.DeleteModelLabelRequest{
+		ModelName: m.Name, Label: "a",
+	})
+	require.NoError(t, err)
+	require.NotContains(t, unlabeled.Model.Metadata.Fields, "owner_email")
+
+	patched, err := api.PatchModel(editorCtx, &apiv1.PatchModelRequest{
+		ModelName: m.Name,
+		Model:     &modelv1.PatchModel{Description: wrapperspb.String("masked")},
+	})
+	require.NoError(t, err)
+	require.NotContains(t, patched.Model.Metadata.Fields, "owner_email")
+
+	// Users who may edit the model in its workspace still see everything.
+	const workspaceAdminRoleID = 2
+	require.NoError(t, rbac.AddRoleAssignments(ctx, nil, []*rbacv1.UserRoleAssignment{{
+		UserId: int32(curUser.ID),
+		RoleAssignment: &rbacv1.RoleAssignment{
+			Role:             &rbacv1.Role{RoleId: workspaceAdminRoleID},
+			ScopeWorkspaceId: ptrs.Ptr(int32(workspaceID)),
+		},
+	}}))
+	labeled, err = api.PutModelLabel(ctx, &apiv1.PutModelLabelRequest{
+		ModelName: m.Name, Label: "b",
+	})
+	require.NoError(t, err)
+	require.Contains(t, labeled.Model.Metadata.Fields, "owner_email")
+}
+
+func TestModelAccessGrantsListRBAC(t *testing.T) {
+	api, curUser, ctx := setupAPITest(t, nil)
+	checkpointUUID := createVersionTwoCheckpoint(ctx, t, api, curUser, map[string]int64{"a": 1})
+
+	workspaceID, _ := db.RequireMockWorkspaceID(t, api.m.db, "")
+	resolver := modelauth.WorkspaceResolver
+	defer func() { modelauth.WorkspaceResolver = resolver }()
+	modelauth.WorkspaceResolver = func(id int32) (string, bool) {
+		return "rbac", id == int32(workspaceID)
+	}
+
+	m, err := db.InsertModelTx(ctx, db.Bun(), uuid.NewString(), "", []byte(`{}`), "", "",
+		curUser.ID, workspaceID, modelv1.ModelVisibility_MODEL_VISIBILITY_PRIVATE)
+	require.NoError(t, err)
+	_, err = db.InsertModelVersion(ctx, m.Id, checkpointUUID, "", "", []byte(`{}`), "", "",
+		curUser.ID)
+	require.NoError(t, err)
+
+	// The grantee has no role in the workspace of the model.
+	grantee, granteeCtx := modelTestUserCtx(t, api)
+	listed := func() []int32 {
+		resp, err := api.GetModels(granteeCtx, &apiv1.GetModelsRequest{
+			WorkspaceIds: []int32{int32(workspaceID)},
+		})
+		if err != nil {
+			require.Equal(t, codes.PermissionDenied, status.Code(err))
+			return nil
+		}
+		var ids []int32
+		for _, listed := range resp.Models {
+			ids = append(ids, listed.Id)
+		}
+		return ids
+	}
+	require.Empty(t, listed())
+	_, err = api.GetModelVersions(granteeCtx, &apiv1.GetModelVersionsRequest{ModelName: m.Name})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// The owner is an admin without roles, who may not manage grants in the workspace.
+	require.NoError(t, db.PutModelAccessGrant(ctx, &model.ModelAccessGrant{
+		ModelID: m.Id, UserID: grantee.ID, Access: model.ModelAccessRead, GrantedByID: &curUser.ID,
+	}))
+
+	require.Equal(t, []int32{m.Id}, listed())
+	resp, err := api.GetModels(granteeCtx, &apiv1.GetModelsRequest{Id: m.Id})
+	require.NoError(t, err)
+	require.Len(t, resp.Models, 1, "granted models are listed across workspaces")
+	// Versions are still filtered by access to the artifacts of their checkpoints.
+	_, err = api.GetModelVersions(granteeCtx, &apiv1.GetModelVersionsRequest{ModelName: m.Name})
+	require.NoError(t, err)
+	_, err = api.GetModelVersion(granteeCtx, &apiv1.GetModelVersionRequest{
+		ModelName:       m.Name,
+		ModelVersionNum: 1,
+	})
+	require.NoError(t, err)
+}
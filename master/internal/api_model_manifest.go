@@ -0,0 +1,315 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/uptrace/bun"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/db"
+	modelauth "github.com/determined-ai/determined/master/internal/model"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/checkpointv1"
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+// modelManifestFormat is the format of the manifests ExportModel returns. ImportModel rejects
+// manifests of other formats.
+const modelManifestFormat = 1
+
+// modelManifest is the portable definition of a model returned by ExportModel. It leaves out
+// what differs between clusters or over time, such as ids, users and times.
+type modelManifest struct {
+	Format      int                    `json:"format"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Notes       string                 `json:"notes"`
+	Visibility  string                 `json:"visibility"`
+	Labels      []string               `json:"labels"`
+	Tags        map[string]string      `json:"tags"`
+	Metadata    map[string]any         `json:"metadata"`
+	Versions    []modelVersionManifest `json:"versions"`
+}
+
+// modelVersionManifest is a version of a model in a modelManifest.
+type modelVersionManifest struct {
+	Version        int32          `json:"version"`
+	CheckpointUUID string         `json:"checkpoint_uuid"`
+	Name           string         `json:"name"`
+	Comment        string         `json:"comment"`
+	Notes          string         `json:"notes"`
+	Labels         []string       `json:"labels"`
+	Metadata       map[string]any `json:"metadata"`
+}
+
+// newModelManifest returns the manifest of m and its versions. Versions are listed by number,
+// and empty collections are written as such rather than as null, so that the manifest of a
+// model only changes when the model does.
+func newModelManifest(m *modelv1.Model, versions []*modelv1.ModelVersion) *modelManifest {
+	manifest := &modelManifest{
+		Format:      modelManifestFormat,
+		Name:        m.Name,
+		Description: m.Description,
+		Notes:       m.Notes,
+		Visibility:  m.Visibility.String(),
+		Labels:      append([]string{}, m.Labels...),
+		Tags:        map[string]string{},
+		Metadata:    m.GetMetadata().AsMap(),
+		Versions:    make([]modelVersionManifest, 0, len(versions)),
+	}
+	for k, v := range m.Tags {
+		manifest.Tags[k] = v
+	}
+	for _, mv := range versions {
+		manifest.Versions = append(manifest.Versions, modelVersionManifest{
+			Version:        mv.Version,
+			CheckpointUUID: mv.GetCheckpoint().GetUuid(),
+			Name:           mv.Name,
+			Comment:        mv.Comment,
+			Notes:          mv.Notes,
+			Labels:         append([]string{}, mv.Labels...),
+			Metadata:       mv.GetMetadata().AsMap(),
+		})
+	}
+	sort.Slice(manifest.Versions, func(i, j int) bool {
+		return manifest.Versions[i].Version < manifest.Versions[j].Version
+	})
+	return manifest
+}
+
+// parseModelManifest parses and validates a manifest returned by ExportModel.
+func parseModelManifest(manifest string) (*modelManifest, error) {
+	var m modelManifest
+	dec := json.NewDecoder(strings.NewReader(manifest))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid model manifest: %s", err)
+	}
+	if m.Format != modelManifestFormat {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"unsupported model manifest format %d, expected %d", m.Format, modelManifestFormat)
+	}
+	if m.Visibility == "" {
+		m.Visibility = modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE.String()
+	} else if _, ok := modelv1.ModelVisibility_value[m.Visibility]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"invalid model manifest: unknown visibility %q", m.Visibility)
+	}
+	seen := make(map[int32]bool, len(m.Versions))
+	for _, v := range m.Versions {
+		switch {
+		case v.Version < 1:
+			return nil, status.Errorf(codes.InvalidArgument,
+				"invalid model manifest: version number %d is not positive", v.Version)
+		case seen[v.Version]:
+			return nil, status.Errorf(codes.InvalidArgument,
+				"invalid model manifest: version %d is listed more than once", v.Version)
+		case v.CheckpointUUID == "":
+			return nil, status.Errorf(codes.InvalidArgument,
+				"invalid model manifest: version %d has no checkpoint", v.Version)
+		}
+		seen[v.Version] = true
+	}
+	return &m, nil
+}
+
+func (a *apiServer) ExportModel(
+	ctx context.Context, req *apiv1.ExportModelRequest,
+) (*apiv1.ExportModelResponse, error) {
+	// Exported like it is read, with the versions the user may not view left out and metadata
+	// masked.
+	modelResp, err := a.GetModel(ctx, &apiv1.GetModelRequest{ModelName: req.ModelName})
+	if err != nil {
+		return nil, err
+	}
+	versionsResp, err := a.GetModelVersions(ctx, &apiv1.GetModelVersionsRequest{
+		ModelName: req.ModelName,
+		SortBy:    apiv1.GetModelVersionsRequest_SORT_BY_VERSION,
+		OrderBy:   apiv1.OrderBy_ORDER_BY_ASC,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(newModelManifest(modelResp.Model, versionsResp.ModelVersions)); err != nil {
+		return nil, errors.Wrapf(err, "error exporting model %q", modelResp.Model.Name)
+	}
+	return &apiv1.ExportModelResponse{Manifest: buf.String()}, nil
+}
+
+// modelManifestCheckpoints returns the checkpoints of the versions of manifest, after replacing
+// the UUIDs in remap, in the order of the versions. It fails with every checkpoint that does
+// not exist, so that none of the model is imported.
+func modelManifestCheckpoints(
+	ctx context.Context, manifest *modelManifest, remap map[string]string,
+) ([]*checkpointv1.Checkpoint, error) {
+	checkpoints := make([]*checkpointv1.Checkpoint, len(manifest.Versions))
+	var missing []string
+	for i, v := range manifest.Versions {
+		uuid := v.CheckpointUUID
+		if to, ok := remap[uuid]; ok {
+			uuid = to
+		}
+		c, err := db.GetCheckpoint(ctx, uuid)
+		if errors.Is(err, db.ErrNotFound) {
+			missing = append(missing, uuid)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if c.State != checkpointv1.State_STATE_COMPLETED {
+			return nil, status.Errorf(codes.FailedPrecondition,
+				"checkpoint %s of version %d is in %s state. checkpoints for model versions "+
+					"must be in a COMPLETED state", c.Uuid, v.Version, c.State)
+		}
+		checkpoints[i] = c
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, status.Errorf(codes.NotFound,
+			"checkpoints of the model manifest do not exist: %s", strings.Join(missing, ", "))
+	}
+	return checkpoints, nil
+}
+
+// checkModelManifestLimits checks the metadata, labels and tags of a model imported as name, and
+// of its versions, against the model_registry limits.
+func checkModelManifestLimits(
+	name string, manifest *modelManifest, metadata []byte, versions []db.ImportedModelVersion,
+) error {
+	subject := fmt.Sprintf("model %q", name)
+	if err := checkModelMetadataSize(subject, metadata); err != nil {
+		return err
+	}
+	if err := checkModelLabelCount(subject, len(manifest.Labels)); err != nil {
+		return err
+	}
+	if err := checkModelTagCount(subject, len(manifest.Tags)); err != nil {
+		return err
+	}
+	for _, v := range versions {
+		subject := fmt.Sprintf("version %d of model %q", v.Version, name)
+		if err := checkModelMetadataSize(subject, v.Metadata); err != nil {
+			return err
+		}
+		if err := checkModelLabelCount(subject, len(v.Labels)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *apiServer) ImportModel(
+	ctx context.Context, req *apiv1.ImportModelRequest,
+) (*apiv1.ImportModelResponse, error) {
+	curUser, err := modelRegistryUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := parseModelManifest(req.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	name := manifest.Name
+	if req.Name != nil {
+		name = *req.Name
+	}
+	if err := modelauth.NamePolicyProvider.Get().Validate(name, req.WorkspaceId); err != nil {
+		return nil, err
+	}
+	checkpoints, err := modelManifestCheckpoints(ctx, manifest, req.CheckpointUuids)
+	if err != nil {
+		return nil, err
+	}
+	checkpointWorkspaceIDs := make([]*int32, len(checkpoints))
+	for i, c := range checkpoints {
+		if checkpointWorkspaceIDs[i], err = checkpointExperimentWorkspaceID(ctx, c); err != nil {
+			return nil, err
+		}
+	}
+
+	metadata, err := json.Marshal(manifest.Metadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshaling model metadata")
+	}
+	versions := make([]db.ImportedModelVersion, len(manifest.Versions))
+	for i, v := range manifest.Versions {
+		versionMetadata, err := json.Marshal(v.Metadata)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error marshaling metadata of version %d", v.Version)
+		}
+		versions[i] = db.ImportedModelVersion{
+			Version:        v.Version,
+			CheckpointUUID: checkpoints[i].Uuid,
+			Name:           v.Name,
+			Comment:        v.Comment,
+			Metadata:       versionMetadata,
+			Labels:         v.Labels,
+			Notes:          v.Notes,
+		}
+	}
+
+	var m *modelv1.Model
+	// Authorize within the insert so that a workspace's model quota holds under concurrent creates.
+	err = db.Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		authz := modelauth.ForWorkspace(req.WorkspaceId)
+		if err := authz.CanCreateModel(ctx, tx, *curUser, req.WorkspaceId); err != nil {
+			return modelauth.PermissionDenied(err, *curUser, "create",
+				fmt.Sprintf("models in workspace %d", req.WorkspaceId))
+		}
+		if err := limitModelAction(ctx, *curUser, config.ModelRateLimitCreate); err != nil {
+			return err
+		}
+		if err := checkModelManifestLimits(name, manifest, metadata, versions); err != nil {
+			return err
+		}
+		if err := a.checkModelNameAvailable(ctx, tx, name, 0); err != nil {
+			return err
+		}
+
+		var insertErr error
+		m, insertErr = db.InsertModelTx(ctx, tx, name, manifest.Description, metadata,
+			strings.Join(manifest.Labels, ","), manifest.Notes, curUser.ID, int(req.WorkspaceId),
+			modelv1.ModelVisibility(modelv1.ModelVisibility_value[manifest.Visibility]))
+		if insertErr != nil {
+			return errors.Wrapf(insertErr, "error creating model %q in database", name)
+		}
+		for i, c := range checkpoints {
+			if err := authz.CanCreateModelVersionFromCheckpoint(ctx, *curUser, m,
+				m.WorkspaceId, c.Uuid, checkpointWorkspaceIDs[i]); err != nil {
+				return modelauth.PermissionDenied(err, *curUser, "register",
+					fmt.Sprintf("checkpoint %s as a version of model %q", c.Uuid, name))
+			}
+		}
+		return db.ImportModelVersionsTx(ctx, tx, m.Id, manifest.Tags, versions, curUser.ID)
+	})
+	if err != nil && strings.Contains(err.Error(), db.CodeUniqueViolation) {
+		return nil, modelNameConflictError(name, err)
+	} else if err != nil {
+		return nil, err
+	}
+	modelauth.InvalidateCanGetModelsCache()
+
+	m, err = a.ModelFromIdentifier(strconv.Itoa(int(m.Id)))
+	if err != nil {
+		return nil, err
+	}
+	notifyModelEvent("model creation", modelauth.NotifierProvider.Get().ModelCreated(ctx, m))
+	log.Infof("model %q imported with %d versions by %q", m.Name, len(versions),
+		curUser.Username)
+	return &apiv1.ImportModelResponse{Model: maskModel(ctx, *curUser, m)}, nil
+}
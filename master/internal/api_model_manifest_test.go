@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/determined-ai/determined/proto/pkg/checkpointv1"
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+func TestModelManifest(t *testing.T) {
+	metadata, err := structpb.NewStruct(map[string]any{"b": 1, "a": map[string]any{"d": "x", "c": 2}})
+	require.NoError(t, err)
+	m := &modelv1.Model{
+		Id:         7,
+		Name:       "resnet",
+		Metadata:   metadata,
+		Labels:     []string{"vision"},
+		Tags:       map[string]string{"team": "a", "stage": "b"},
+		Visibility: modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE,
+	}
+	versions := []*modelv1.ModelVersion{
+		{Version: 3, Checkpoint: &checkpointv1.Checkpoint{Uuid: "c3"}, Name: "three"},
+		{Version: 1, Checkpoint: &checkpointv1.Checkpoint{Uuid: "c1"}, Labels: []string{"prod"}},
+	}
+
+	first, err := json.Marshal(newModelManifest(m, versions))
+	require.NoError(t, err)
+	versions[0], versions[1] = versions[1], versions[0]
+	second, err := json.Marshal(newModelManifest(m, versions))
+	require.NoError(t, err)
+	require.Equal(t, string(first), string(second), "manifests are stable")
+	require.JSONEq(t, `{
+		"format": 1, "name": "resnet", "description": "", "notes": "",
+		"visibility": "MODEL_VISIBILITY_WORKSPACE", "labels": ["vision"],
+		"tags": {"stage": "b", "team": "a"}, "metadata": {"a": {"c": 2, "d": "x"}, "b": 1},
+		"versions": [
+			{"version": 1, "checkpoint_uuid": "c1", "name": "", "comment": "", "notes": "",
+			 "labels": ["prod"], "metadata": {}},
+			{"version": 3, "checkpoint_uuid": "c3", "name": "three", "comment": "", "notes": "",
+			 "labels": [], "metadata": {}}
+		]
+	}`, string(first))
+
+	parsed, err := parseModelManifest(string(first))
+	require.NoError(t, err)
+	require.Equal(t, newModelManifest(m, versions), parsed)
+
+	for _, invalid := range []string{
+		`not json`,
+		`{"format": 2}`,
+		`{"format": 1, "unknown": true}`,
+		`{"format": 1, "visibility": "MODEL_VISIBILITY_SECRET"}`,
+		`{"format": 1, "versions": [{"version": 0, "checkpoint_uuid": "c"}]}`,
+		`{"format": 1, "versions": [{"version": 1, "checkpoint_uuid": "c"},
+			{"version": 1, "checkpoint_uuid": "d"}]}`,
+		`{"format": 1, "versions": [{"version": 1}]}`,
+	} {
+		_, err := parseModelManifest(invalid)
+		require.Equal(t, codes.InvalidArgument, status.Code(err), invalid)
+	}
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/uptrace/bun"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+const (
+	// defaultModelStreamBatchSize is how many models StreamModels sends per response by default.
+	defaultModelStreamBatchSize = 1000
+	// maxModelStreamBatchSize is the most models StreamModels may send per response.
+	maxModelStreamBatchSize = 10000
+)
+
+func (a *apiServer) StreamModels(
+	req *apiv1.StreamModelsRequest, resp apiv1.Determined_StreamModelsServer,
+) error {
+	ctx := resp.Context()
+	filter := req.Filter
+	if filter == nil {
+		filter = &apiv1.GetModelsRequest{}
+	}
+	if filter.Offset != 0 || filter.Limit != 0 {
+		return status.Error(codes.InvalidArgument,
+			"offset and limit cannot be used when streaming models; resume from a cursor instead")
+	}
+	batchSize := int(req.BatchSize)
+	if batchSize == 0 {
+		batchSize = defaultModelStreamBatchSize
+	} else if batchSize < 0 || batchSize > maxModelStreamBatchSize {
+		return status.Errorf(codes.InvalidArgument, "batch_size must be between 1 and %d",
+			maxModelStreamBatchSize)
+	}
+
+	// The query is authorized and filtered once. Each batch reruns it from after the last model
+	// sent, in its own transaction, so that no transaction is held open while a slow client
+	// receives a batch.
+	var batch []*modelv1.Model
+	curUser, query, sortKey, err := readableModelsQuery(ctx, filter, &batch)
+	if err != nil {
+		return err
+	}
+	after, err := parseModelCursor(filter, sortKey)
+	if err != nil {
+		return err
+	}
+	cond := &modelsAfterCursor{key: sortKey, after: after}
+	query = query.Where("?", cond).Limit(batchSize)
+
+	for {
+		batch = nil
+		var next *modelCursor
+		if err := db.Bun().RunInTx(ctx,
+			&sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true},
+			func(ctx context.Context, tx bun.Tx) error {
+				if err := query.Conn(tx).Scan(ctx); err != nil || len(batch) == 0 {
+					return err
+				}
+				// Read in the same snapshot as the batch, in case its last model is edited.
+				var err error
+				next, err = modelCursorAt(ctx, tx, filter, sortKey, batch[len(batch)-1].Id)
+				return err
+			}); err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		cursor, err := encodeModelCursor(*next)
+		if err != nil {
+			return err
+		}
+		maskModels(ctx, *curUser, batch)
+		if err := resp.Send(&apiv1.StreamModelsResponse{Models: batch, Cursor: cursor}); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+		cond.after = next
+	}
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+func TestBulkModelAction(t *testing.T) {
+	var acted []string
+	results := bulkModelAction([]int32{3, 1, 3, 2}, func(identifier string) error {
+		acted = append(acted, identifier)
+		switch identifier {
+		case "1":
+			return errors.Wrap(status.Error(codes.PermissionDenied, "denied"), "archiving")
+		case "2":
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	require.Equal(t, []string{"3", "1", "2"}, acted, "each model is acted on once, in order")
+	require.Equal(t, []*apiv1.ModelActionResult{
+		{Id: 3},
+		{Id: 1, Error: "archiving: rpc error: code = PermissionDenied desc = denied",
+			Code: int32(codes.PermissionDenied)},
+		{Id: 2, Error: "boom", Code: int32(codes.Internal)},
+	}, results)
+}
+
+func TestModelCursorRoundTrip(t *testing.T) {
+	key := "2024-05-01 12:00:00.123456+00"
+	c := modelCursor{
+		SortBy:  apiv1.GetModelsRequest_SORT_BY_CREATION_TIME,
+		OrderBy: apiv1.OrderBy_ORDER_BY_DESC,
+		Key:     &key,
+		ID:      7,
+	}
+	encoded, err := encodeModelCursor(c)
+	require.NoError(t, err)
+	decoded, err := decodeModelCursor(encoded)
+	require.NoError(t, err)
+	require.Equal(t, c, *decoded)
+
+	_, err = decodeModelCursor("not a cursor")
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestModelSortKeyAfterCursor(t *testing.T) {
+	key := "b"
+	ascending, err := modelSortKeyFor(&apiv1.GetModelsRequest{
+		SortBy: apiv1.GetModelsRequest_SORT_BY_DESCRIPTION,
+	})
+	require.NoError(t, err)
+	cond, args := ascending.afterCursor(&modelCursor{Key: &key, ID: 3})
+	require.Equal(t, "(m.description) > ?::text OR ((m.description) = ?::text AND m.id > ?) "+
+		"OR (m.description) IS NULL", cond)
+	require.Equal(t, []any{"b", "b", int32(3)}, args)
+	cond, args = ascending.afterCursor(&modelCursor{ID: 3})
+	require.Equal(t, "(m.description) IS NULL AND m.id > ?", cond, "NULLs sort last")
+	require.Equal(t, []any{int32(3)}, args)
+
+	descending, err := modelSortKeyFor(&apiv1.GetModelsRequest{
+		SortBy:  apiv1.GetModelsRequest_SORT_BY_DESCRIPTION,
+		OrderBy: apiv1.OrderBy_ORDER_BY_DESC,
+	})
+	require.NoError(t, err)
+	cond, _ = descending.afterCursor(&modelCursor{ID: 3})
+	require.Equal(t, "(m.description) IS NOT NULL OR ((m.description) IS NULL AND m.id < ?)",
+		cond, "NULLs sort first")
+
+	ranked, err := modelSortKeyFor(&apiv1.GetModelsRequest{Search: "resnet"})
+	require.NoError(t, err)
+	require.True(t, ranked.ranked)
+	_, args = ranked.afterCursor(&modelCursor{Key: &key, ID: 3})
+	require.Equal(t, []any{"resnet", "b", "resnet", "b", int32(3)}, args)
+
+	byID, err := modelSortKeyFor(&apiv1.GetModelsRequest{})
+	require.NoError(t, err)
+	cond, args = byID.afterCursor(&modelCursor{ID: 3})
+	require.Equal(t, "m.id > ?", cond)
+	require.Equal(t, []any{int32(3)}, args)
+
+	byMetadata, err := modelSortKeyFor(&apiv1.GetModelsRequest{
+		SortBy:            apiv1.GetModelsRequest_SORT_BY_METADATA,
+		SortByMetadataKey: "accuracy",
+		OrderBy:           apiv1.OrderBy_ORDER_BY_DESC,
+	})
+	require.NoError(t, err)
+	key = "0.5"
+	cond, args = byMetadata.afterCursor(&modelCursor{Key: &key, ID: 3})
+	metadata := "(NULLIF(m.metadata -> ?, 'null'::jsonb))"
+	require.Equal(t, metadata+" < ?::jsonb OR ("+metadata+" = ?::jsonb AND m.id < ?) OR "+
+		metadata+" IS NULL", cond, "NULLs sort last when descending too")
+	require.Equal(t, []any{"accuracy", "0.5", "accuracy", "0.5", int32(3), "accuracy"}, args)
+	cond, _ = byMetadata.afterCursor(&modelCursor{ID: 3})
+	require.Equal(t, metadata+" IS NULL AND m.id < ?", cond)
+
+	_, err = modelSortKeyFor(&apiv1.GetModelsRequest{
+		SortBy: apiv1.GetModelsRequest_SORT_BY_METADATA,
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestModelMetadataLimits(t *testing.T) {
+	registry := &config.GetMasterConfig().ModelRegistry
+	defer func(r config.ModelRegistryConfig) { *registry = r }(*registry)
+
+	// Unset limits are the defaults, not unlimited.
+	registry.MaxMetadataBytes, registry.MaxLabels, registry.MaxTags = 0, 0, 0
+	require.NoError(t, checkModelMetadataSize("model", make([]byte,
+		config.DefaultMaxModelMetadataBytes)))
+	require.Error(t, checkModelMetadataSize("model", make([]byte,
+		config.DefaultMaxModelMetadataBytes+1)))
+	require.NoError(t, checkModelLabelCount("model", config.DefaultMaxModelLabels))
+	require.Error(t, checkModelLabelCount("model", config.DefaultMaxModelLabels+1))
+	require.NoError(t, checkModelTagCount("model", config.DefaultMaxModelTags))
+	require.Error(t, checkModelTagCount("model", config.DefaultMaxModelTags+1))
+
+	registry.MaxMetadataBytes, registry.MaxLabels, registry.MaxTags = 10, 2, 1
+	err := checkModelMetadataSize(`model "m"`, []byte(`{"a":"12345"}`))
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.ErrorContains(t, err, `metadata of model "m" is 13 bytes, more than the allowed 10`)
+	require.NoError(t, checkModelMetadataSize(`model "m"`, []byte(`{"a":"1"}`)))
+	err = checkModelLabelCount(`model "m"`, 3)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.ErrorContains(t, err, "3 labels, more than the allowed 2")
+	err = checkModelTagCount(`model "m"`, 2)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.ErrorContains(t, err, "2 tags, more than the allowed 1")
+}
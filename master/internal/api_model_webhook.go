@@ -0,0 +1,222 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/api"
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/db"
+	modelauth "github.com/determined-ai/determined/master/internal/model"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+// modelForWebhooks returns the model and the current user if the user may manage the webhooks
+// of the model, counting the request against the user's rate limit for action.
+func (a *apiServer) modelForWebhooks(
+	ctx context.Context, modelName string, action string,
+) (*modelv1.Model, *model.User, error) {
+	currModel, err := a.ModelFromIdentifier(modelName)
+	if err != nil {
+		return nil, nil, err
+	}
+	curUser, err := modelRegistryUser(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := modelauth.ForWorkspace(currModel.WorkspaceId).CanEditModelWebhooks(ctx, *curUser,
+		currModel, currModel.WorkspaceId); err != nil {
+		return nil, nil, modelauth.PermissionDenied(err, *curUser, "manage webhooks of",
+			fmt.Sprintf("model %q", currModel.Name))
+	}
+	if err := limitModelAction(ctx, *curUser, action); err != nil {
+		return nil, nil, err
+	}
+	return currModel, curUser, nil
+}
+
+// modelWebhookByID returns a webhook of a model, or a not found error.
+func modelWebhookByID(
+	ctx context.Context, m *modelv1.Model, id int32,
+) (*model.ModelWebhook, error) {
+	w, err := db.GetModelWebhook(ctx, m.Id, id)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, api.NotFoundErrs(fmt.Sprintf("webhook of model %q", m.Name),
+			strconv.Itoa(int(id)), true)
+	}
+	return w, err
+}
+
+func validateModelWebhookURL(u string) error {
+	parsed, err := url.ParseRequestURI(u)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return status.Errorf(codes.InvalidArgument, "valid http or https url required")
+	}
+	return nil
+}
+
+// modelWebhookEvents returns how requested webhook events are stored, rejecting unspecified
+// events.
+func modelWebhookEvents(events []modelv1.ModelWebhookEvent) ([]string, error) {
+	if len(events) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one event is required")
+	}
+	var out []string
+	seen := make(map[modelv1.ModelWebhookEvent]bool)
+	for _, e := range events {
+		if _, ok := modelv1.ModelWebhookEvent_name[int32(e)]; !ok ||
+			e == modelv1.ModelWebhookEvent_MODEL_WEBHOOK_EVENT_UNSPECIFIED {
+			return nil, status.Errorf(codes.InvalidArgument, "unsupported event %s", e)
+		}
+		if !seen[e] {
+			seen[e] = true
+			out = append(out, model.ModelWebhookEventToDB(e))
+		}
+	}
+	return out, nil
+}
+
+func (a *apiServer) GetModelWebhooks(
+	ctx context.Context, req *apiv1.GetModelWebhooksRequest,
+) (*apiv1.GetModelWebhooksResponse, error) {
+	currModel, _, err := a.modelForWebhooks(ctx, req.ModelName, config.ModelRateLimitGet)
+	if err != nil {
+		return nil, err
+	}
+	webhooks, err := db.GetModelWebhooks(ctx, currModel.Id)
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiv1.GetModelWebhooksResponse{Webhooks: make([]*modelv1.ModelWebhook, 0,
+		len(webhooks))}
+	for _, w := range webhooks {
+		resp.Webhooks = append(resp.Webhooks, w.Proto())
+	}
+	return resp, nil
+}
+
+func (a *apiServer) PostModelWebhook(
+	ctx context.Context, req *apiv1.PostModelWebhookRequest,
+) (*apiv1.PostModelWebhookResponse, error) {
+	currModel, curUser, err := a.modelForWebhooks(ctx, req.ModelName, config.ModelRateLimitEdit)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateModelWebhookURL(req.Url); err != nil {
+		return nil, err
+	}
+	events, err := modelWebhookEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &model.ModelWebhook{
+		ModelID: currModel.Id,
+		URL:     req.Url,
+		Events:  events,
+		Secret:  req.Secret,
+		UserID:  &curUser.ID,
+	}
+	if err := db.InsertModelWebhook(ctx, w); err != nil {
+		return nil, err
+	}
+	return &apiv1.PostModelWebhookResponse{Webhook: w.Proto()}, nil
+}
+
+func (a *apiServer) PatchModelWebhook(
+	ctx context.Context, req *apiv1.PatchModelWebhookRequest,
+) (*apiv1.PatchModelWebhookResponse, error) {
+	currModel, _, err := a.modelForWebhooks(ctx, req.ModelName, config.ModelRateLimitEdit)
+	if err != nil {
+		return nil, err
+	}
+	w, err := modelWebhookByID(ctx, currModel, req.WebhookId)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []string
+	if req.Url != nil {
+		if err := validateModelWebhookURL(*req.Url); err != nil {
+			return nil, err
+		}
+		w.URL = *req.Url
+		columns = append(columns, "url")
+	}
+	if len(req.Events) > 0 {
+		if w.Events, err = modelWebhookEvents(req.Events); err != nil {
+			return nil, err
+		}
+		columns = append(columns, "events")
+	}
+	if req.Secret != nil {
+		w.Secret = *req.Secret
+		columns = append(columns, "secret")
+	}
+	if len(columns) > 0 {
+		if err := db.UpdateModelWebhook(ctx, w, columns...); err != nil {
+			return nil, err
+		}
+	}
+	return &apiv1.PatchModelWebhookResponse{Webhook: w.Proto()}, nil
+}
+
+func (a *apiServer) DeleteModelWebhook(
+	ctx context.Context, req *apiv1.DeleteModelWebhookRequest,
+) (*apiv1.DeleteModelWebhookResponse, error) {
+	currModel, _, err := a.modelForWebhooks(ctx, req.ModelName, config.ModelRateLimitDelete)
+	if err != nil {
+		return nil, err
+	}
+	switch err := db.DeleteModelWebhook(ctx, currModel.Id, req.WebhookId); {
+	case errors.Is(err, db.ErrNotFound):
+		return nil, api.NotFoundErrs(fmt.Sprintf("webhook of model %q", currModel.Name),
+			strconv.Itoa(int(req.WebhookId)), true)
+	case err != nil:
+		return nil, err
+	}
+	return &apiv1.DeleteModelWebhookResponse{}, nil
+}
+
+func (a *apiServer) GetModelWebhookDeliveries(
+	ctx context.Context, req *apiv1.GetModelWebhookDeliveriesRequest,
+) (*apiv1.GetModelWebhookDeliveriesResponse, error) {
+	currModel, _, err := a.modelForWebhooks(ctx, req.ModelName, config.ModelRateLimitGet)
+	if err != nil {
+		return nil, err
+	}
+	if req.Offset < 0 || req.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset and limit cannot be negative")
+	}
+	if _, err := modelWebhookByID(ctx, currModel, req.WebhookId); err != nil {
+		return nil, err
+	}
+
+	deliveries, total, err := db.GetModelWebhookDeliveries(ctx, req.WebhookId,
+		int(req.Offset), int(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiv1.GetModelWebhookDeliveriesResponse{
+		Deliveries: make([]*modelv1.ModelWebhookDelivery, 0, len(deliveries)),
+		Pagination: &apiv1.Pagination{
+			Offset:     req.Offset,
+			Limit:      req.Limit,
+			StartIndex: req.Offset,
+			EndIndex:   req.Offset + int32(len(deliveries)),
+			Total:      int32(total),
+		},
+	}
+	for _, d := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, d.Proto())
+	}
+	return resp, nil
+}
@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"context"
+
+	modelauth "github.com/determined-ai/determined/master/internal/model"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+// AssignRoles grants roles and drops cached model authz decisions that they may change.
+func (a *apiServer) AssignRoles(ctx context.Context, req *apiv1.AssignRolesRequest,
+) (*apiv1.AssignRolesResponse, error) {
+	resp, err := a.RBACAPIServerWrapper.AssignRoles(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	modelauth.InvalidateCanGetModelsCache()
+	return resp, nil
+}
+
+// RemoveAssignments removes roles and drops cached model authz decisions that they may change.
+func (a *apiServer) RemoveAssignments(ctx context.Context, req *apiv1.RemoveAssignmentsRequest,
+) (*apiv1.RemoveAssignmentsResponse, error) {
+	resp, err := a.RBACAPIServerWrapper.RemoveAssignments(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	modelauth.InvalidateCanGetModelsCache()
+	return resp, nil
+}
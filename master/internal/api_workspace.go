@@ -122,6 +122,7 @@ func (a *apiServer) getWorkspaceAndCheckCanDoActions(
 func (a *apiServer) workspaceHasModels(ctx context.Context, workspaceID int32) (bool, error) {
 	exists, err := db.Bun().NewSelect().Table("models").
 		Where("workspace_id=?", workspaceID).
+		Where("deleted_at IS NULL").
 		Exists(ctx)
 	if err != nil {
 		return false, fmt.Errorf("checking workspace for models: %w", err)
@@ -576,6 +577,10 @@ func (a *apiServer) DeleteWorkspace(
 	if err != nil || holder.Id == 0 {
 		return nil, fmt.Errorf("workspace (%d) does not exist or not deletable by this user: %w", req.Id, err)
 	}
+	// Deleted models cannot be restored without their workspace.
+	if _, err := db.PurgeWorkspaceDeletedModels(ctx, req.Id); err != nil {
+		return nil, err
+	}
 
 	projects := []*projectv1.Project{}
 	err = a.m.db.QueryProtof(
@@ -600,6 +600,13 @@ func TestWorkspaceHasModels(t *testing.T) {
 	exists, err = api.workspaceHasModels(ctx, resp.Workspace.Id)
 	require.NoError(t, err)
 	assert.True(t, exists)
+
+	// deleted models do not keep the workspace from being deleted
+	_, err = api.DeleteModel(ctx, &apiv1.DeleteModelRequest{ModelName: modelName})
+	require.NoError(t, err)
+	exists, err = api.workspaceHasModels(ctx, resp.Workspace.Id)
+	require.NoError(t, err)
+	assert.False(t, exists)
 }
 
 func TestDeleteWorkspace(t *testing.T) {
@@ -3,6 +3,7 @@ package authz
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 
@@ -34,6 +35,19 @@ func (p *AuthZProviderType[T]) string() string {
 	return reflect.TypeOf((*T)(nil)).Elem().String()
 }
 
+// GetType returns the implementation registered as authZType, if there is one.
+func (p *AuthZProviderType[T]) GetType(authZType string) (T, bool) {
+	res, ok := p.registry[authZType]
+	return res, ok
+}
+
+// Types returns the authz types that have an implementation registered, sorted.
+func (p *AuthZProviderType[T]) Types() []string {
+	types := maps.Keys(p.registry)
+	sort.Strings(types)
+	return types
+}
+
 // Get returns the selected implementation.
 func (p *AuthZProviderType[T]) Get() T {
 	if len(p.registry) == 0 {
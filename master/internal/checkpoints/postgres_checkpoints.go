@@ -46,7 +46,8 @@ func GetModelIDsAssociatedWithCheckpoint(ctx context.Context, ckptUUID uuid.UUID
 	var modelIDs []int32
 	if err := db.Bun().NewRaw(`
 	SELECT DISTINCT(model_id) as ID FROM model_versions m INNER JOIN checkpoints_view c
-	ON m.checkpoint_uuid = c.uuid WHERE c.uuid = ?`,
+	ON m.checkpoint_uuid = c.uuid INNER JOIN models ON models.id = m.model_id
+	WHERE c.uuid = ? AND models.deleted_at IS NULL`,
 		ckptUUID.String()).Scan(ctx, &modelIDs); err != nil {
 		return nil, fmt.Errorf("getting model ids associated with checkpoint uuid: %w", err)
 	}
@@ -129,6 +129,7 @@ func DefaultConfig() *Config {
 		},
 		FeatureSwitches: []string{},
 		ResourceConfig:  *DefaultResourceConfig(),
+		ModelRegistry:   *DefaultModelRegistryConfig(),
 		Observability: ObservabilityConfig{
 			EnablePrometheus: true,
 		},
@@ -164,6 +165,7 @@ type Config struct {
 	Observability         ObservabilityConfig               `json:"observability"`
 	Cache                 CacheConfig                       `json:"cache"`
 	Webhooks              WebhooksConfig                    `json:"webhooks"`
+	ModelRegistry         ModelRegistryConfig               `json:"model_registry"`
 	FeatureSwitches       []string                          `json:"feature_switches"`
 	ReservedPorts         []int                             `json:"reserved_ports"`
 	ResourceConfig
@@ -0,0 +1,312 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/maps"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+const (
+	// DefaultDeletedModelRetention is how long deleted models can be restored by default.
+	DefaultDeletedModelRetention = 30 * 24 * time.Hour
+	// DefaultIdempotencyKeyRetention is how long model version idempotency keys are kept by
+	// default.
+	DefaultIdempotencyKeyRetention = 24 * time.Hour
+	// DefaultProtectedVersionLabel is the model version label protected from deletion by default.
+	DefaultProtectedVersionLabel = "production"
+	// DefaultMaxModelMetadataBytes is the default limit on the size of the serialized metadata of
+	// a model or model version.
+	DefaultMaxModelMetadataBytes = 256 << 10
+	// DefaultMaxModelLabels is the default limit on the labels of a model or model version.
+	DefaultMaxModelLabels = 100
+	// DefaultMaxModelTags is the default limit on the tags of a model.
+	DefaultMaxModelTags = 100
+
+	// NoopModelNotifierType is the default model notifier string id.
+	NoopModelNotifierType = "noop"
+	// WebhookModelNotifierType is the string id of the notifier that posts events to a URL.
+	WebhookModelNotifierType = "webhook"
+
+	// DefaultModelNamePolicyType is the default model name policy string id.
+	DefaultModelNamePolicyType = "default"
+
+	// NoopModelRateLimiterType is the default model rate limiter string id.
+	NoopModelRateLimiterType = "noop"
+	// TokenBucketModelRateLimiterType is the string id of the rate limiter that gives each user
+	// a token bucket per action.
+	TokenBucketModelRateLimiterType = "token_bucket"
+)
+
+// Model registry actions that rate limits are configured for. Each has its own budget, so that
+// reads, like polling, do not use up what is left for writes.
+const (
+	ModelRateLimitGet    = "get"
+	ModelRateLimitCreate = "create"
+	ModelRateLimitEdit   = "edit"
+	ModelRateLimitDelete = "delete"
+)
+
+var modelRateLimitActions = []string{
+	ModelRateLimitGet, ModelRateLimitCreate, ModelRateLimitEdit, ModelRateLimitDelete,
+}
+
+var (
+	knownModelNotifierTypes      = map[string]bool{NoopModelNotifierType: true}
+	knownModelNotifierTypesMutex sync.Mutex
+
+	knownModelNamePolicyTypes      = map[string]bool{DefaultModelNamePolicyType: true}
+	knownModelNamePolicyTypesMutex sync.Mutex
+
+	knownModelRateLimiterTypes      = map[string]bool{NoopModelRateLimiterType: true}
+	knownModelRateLimiterTypesMutex sync.Mutex
+)
+
+// RegisterModelNotifierType adds new known model notifier type.
+func RegisterModelNotifierType(notifierType string) {
+	knownModelNotifierTypesMutex.Lock()
+	defer knownModelNotifierTypesMutex.Unlock()
+
+	knownModelNotifierTypes[notifierType] = true
+}
+
+// RegisterModelNamePolicyType adds new known model name policy type.
+func RegisterModelNamePolicyType(policyType string) {
+	knownModelNamePolicyTypesMutex.Lock()
+	defer knownModelNamePolicyTypesMutex.Unlock()
+
+	knownModelNamePolicyTypes[policyType] = true
+}
+
+// RegisterModelRateLimiterType adds new known model rate limiter type.
+func RegisterModelRateLimiterType(limiterType string) {
+	knownModelRateLimiterTypesMutex.Lock()
+	defer knownModelRateLimiterTypesMutex.Unlock()
+
+	knownModelRateLimiterTypes[limiterType] = true
+}
+
+// ModelRegistryConfig hosts configuration fields for the model registry.
+type ModelRegistryConfig struct {
+	// DeletedModelRetention is how long a deleted model can be restored before it and its
+	// versions are permanently deleted.
+	DeletedModelRetention model.Duration      `json:"deleted_model_retention"`
+	Notifier              ModelNotifierConfig `json:"notifier"`
+	// NamePolicy selects the rules that model names are validated against.
+	NamePolicy string `json:"name_policy"`
+	// MaxModelsPerWorkspace limits how many models a workspace can have under RBAC. Zero means
+	// no limit.
+	MaxModelsPerWorkspace int `json:"max_models_per_workspace"`
+	// IdempotencyKeyRetention is how long the idempotency key of a model version registration
+	// is remembered. Retries within it return the version already registered.
+	IdempotencyKeyRetention model.Duration `json:"idempotency_key_retention"`
+	// WorkspaceAuthZ maps workspace IDs to the authz type used for models in them. Workspaces
+	// that are not listed use authz.type.
+	WorkspaceAuthZ map[int32]string `json:"workspace_authz"`
+	// RestrictedMetadataKeys are model metadata keys that RBAC hides from users who cannot edit
+	// the model.
+	RestrictedMetadataKeys []string `json:"restricted_metadata_keys"`
+	// ProtectedVersionLabels are labels of model versions that may only be deleted by force.
+	ProtectedVersionLabels []string `json:"protected_version_labels"`
+	// ProtectLatestVersion makes the latest version of each model deletable only by force.
+	ProtectLatestVersion bool                   `json:"protect_latest_version"`
+	RateLimiter          ModelRateLimiterConfig `json:"rate_limiter"`
+	// MaxMetadataBytes limits the size of the serialized metadata of a model or model version.
+	// Zero means DefaultMaxModelMetadataBytes.
+	MaxMetadataBytes int `json:"max_metadata_bytes"`
+	// MaxLabels limits the labels of a model or model version. Zero means DefaultMaxModelLabels.
+	MaxLabels int `json:"max_labels"`
+	// MaxTags limits the tags of a model. Zero means DefaultMaxModelTags.
+	MaxTags int `json:"max_tags"`
+	// MaxVersionsPerModel limits the versions of models that do not set their own limit. Zero
+	// means no limit.
+	MaxVersionsPerModel int `json:"max_versions_per_model"`
+}
+
+// MetadataBytesLimit returns the limit on the size of the metadata of a model or model version.
+func (m *ModelRegistryConfig) MetadataBytesLimit() int {
+	if m.MaxMetadataBytes == 0 {
+		return DefaultMaxModelMetadataBytes
+	}
+	return m.MaxMetadataBytes
+}
+
+// LabelsLimit returns the limit on the labels of a model or model version.
+func (m *ModelRegistryConfig) LabelsLimit() int {
+	if m.MaxLabels == 0 {
+		return DefaultMaxModelLabels
+	}
+	return m.MaxLabels
+}
+
+// TagsLimit returns the limit on the tags of a model.
+func (m *ModelRegistryConfig) TagsLimit() int {
+	if m.MaxTags == 0 {
+		return DefaultMaxModelTags
+	}
+	return m.MaxTags
+}
+
+// VersionsLimit returns the limit on the versions of a model with the given max_versions, or zero
+// if its versions are not limited.
+func (m *ModelRegistryConfig) VersionsLimit(modelMaxVersions int) int {
+	if modelMaxVersions > 0 {
+		return modelMaxVersions
+	}
+	return m.MaxVersionsPerModel
+}
+
+// ModelNotifierConfig configures how model registry events are sent to external systems.
+type ModelNotifierConfig struct {
+	Type string `json:"type"`
+	// URL is where the webhook notifier posts events.
+	URL string `json:"url"`
+	// Timeout bounds how long a request may wait to queue an event.
+	Timeout model.Duration `json:"timeout"`
+	// QueueSize is how many events may wait to be sent.
+	QueueSize int `json:"queue_size"`
+	// MaxRetries is how many times delivering an event is retried.
+	MaxRetries int `json:"max_retries"`
+}
+
+// ModelRateLimiterConfig configures how often each user may take model registry actions.
+type ModelRateLimiterConfig struct {
+	Type string `json:"type"`
+	// Limits maps actions to their limit. Actions that are not listed are not limited.
+	Limits map[string]ModelRateLimit `json:"limits"`
+}
+
+// ModelRateLimit is the budget of a user for one model registry action.
+type ModelRateLimit struct {
+	// RequestsPerSecond is the sustained rate the action may be taken at.
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	// Burst is how many requests may be made at once after a quiet period.
+	Burst int `json:"burst"`
+}
+
+// DefaultModelRegistryConfig returns the default model registry configuration.
+func DefaultModelRegistryConfig() *ModelRegistryConfig {
+	return &ModelRegistryConfig{
+		DeletedModelRetention: model.Duration(DefaultDeletedModelRetention),
+		Notifier: ModelNotifierConfig{
+			Type:       NoopModelNotifierType,
+			Timeout:    model.Duration(time.Second),
+			QueueSize:  1000,
+			MaxRetries: 5,
+		},
+		NamePolicy:              DefaultModelNamePolicyType,
+		IdempotencyKeyRetention: model.Duration(DefaultIdempotencyKeyRetention),
+		ProtectedVersionLabels:  []string{DefaultProtectedVersionLabel},
+		RateLimiter:             ModelRateLimiterConfig{Type: NoopModelRateLimiterType},
+	}
+}
+
+// Validate implements the check.Validatable interface.
+func (m *ModelRegistryConfig) Validate() []error {
+	var errs []error
+	if m.DeletedModelRetention <= 0 {
+		errs = append(errs, errors.New("deleted_model_retention must be greater than 0"))
+	}
+	if m.MaxModelsPerWorkspace < 0 {
+		errs = append(errs, errors.New("max_models_per_workspace must be at least 0"))
+	}
+	if m.IdempotencyKeyRetention <= 0 {
+		errs = append(errs, errors.New("idempotency_key_retention must be greater than 0"))
+	}
+	if m.MaxMetadataBytes < 0 {
+		errs = append(errs, errors.New("max_metadata_bytes must be at least 0"))
+	}
+	if m.MaxLabels < 0 {
+		errs = append(errs, errors.New("max_labels must be at least 0"))
+	}
+	if m.MaxTags < 0 {
+		errs = append(errs, errors.New("max_tags must be at least 0"))
+	}
+	if m.MaxVersionsPerModel < 0 {
+		errs = append(errs, errors.New("max_versions_per_model must be at least 0"))
+	}
+
+	initAuthZTypes()
+	authZConfigMutex.Lock()
+	for workspaceID, authZType := range m.WorkspaceAuthZ {
+		if _, ok := knownAuthZTypes[authZType]; !ok {
+			errs = append(errs, fmt.Errorf(
+				"\"%s\" is not a known authz type for workspace %d in workspace_authz, must be "+
+					"one of: %s", authZType, workspaceID, strings.Join(maps.Keys(knownAuthZTypes), ", ")))
+		}
+	}
+	authZConfigMutex.Unlock()
+
+	knownModelNamePolicyTypesMutex.Lock()
+	_, ok := knownModelNamePolicyTypes[m.NamePolicy]
+	okTypes := strings.Join(maps.Keys(knownModelNamePolicyTypes), ", ")
+	knownModelNamePolicyTypesMutex.Unlock()
+	if !ok {
+		errs = append(errs, fmt.Errorf(
+			"\"%s\" is not a known model name policy, must be one of: %s", m.NamePolicy, okTypes))
+	}
+	return errs
+}
+
+// Validate implements the check.Validatable interface.
+func (n *ModelNotifierConfig) Validate() []error {
+	var errs []error
+
+	knownModelNotifierTypesMutex.Lock()
+	_, ok := knownModelNotifierTypes[n.Type]
+	okTypes := strings.Join(maps.Keys(knownModelNotifierTypes), ", ")
+	knownModelNotifierTypesMutex.Unlock()
+	if !ok {
+		errs = append(errs, fmt.Errorf(
+			"\"%s\" is not a known model notifier type, must be one of: %s", n.Type, okTypes))
+	}
+	if n.Type == WebhookModelNotifierType && n.URL == "" {
+		errs = append(errs, errors.New("url must be set for the webhook model notifier"))
+	}
+	if n.Timeout <= 0 {
+		errs = append(errs, errors.New("timeout must be greater than 0"))
+	}
+	if n.QueueSize <= 0 {
+		errs = append(errs, errors.New("queue_size must be greater than 0"))
+	}
+	if n.MaxRetries < 0 {
+		errs = append(errs, errors.New("max_retries must be at least 0"))
+	}
+	return errs
+}
+
+// Validate implements the check.Validatable interface.
+func (r *ModelRateLimiterConfig) Validate() []error {
+	var errs []error
+
+	knownModelRateLimiterTypesMutex.Lock()
+	_, ok := knownModelRateLimiterTypes[r.Type]
+	okTypes := strings.Join(maps.Keys(knownModelRateLimiterTypes), ", ")
+	knownModelRateLimiterTypesMutex.Unlock()
+	if !ok {
+		errs = append(errs, fmt.Errorf(
+			"\"%s\" is not a known model rate limiter type, must be one of: %s", r.Type, okTypes))
+	}
+	for action, limit := range r.Limits {
+		if !slices.Contains(modelRateLimitActions, action) {
+			errs = append(errs, fmt.Errorf(
+				"\"%s\" is not a model rate limit action, must be one of: %s", action,
+				strings.Join(modelRateLimitActions, ", ")))
+			continue
+		}
+		if limit.RequestsPerSecond <= 0 {
+			errs = append(errs, fmt.Errorf(
+				"requests_per_second of the %s rate limit must be greater than 0", action))
+		}
+		if limit.Burst < 1 {
+			errs = append(errs, fmt.Errorf("burst of the %s rate limit must be at least 1", action))
+		}
+	}
+	return errs
+}
@@ -1057,23 +1057,37 @@ func GetCheckpoint(ctx context.Context, checkpointUUID string) (*checkpointv1.Ch
 // InsertModel inserts the model into the database.
 func InsertModel(ctx context.Context, name string, description string, metadata []byte,
 	labels string, notes string, userID model.UserID, workspaceID int,
+) (*modelv1.Model, error) {
+	return InsertModelTx(ctx, Bun(), name, description, metadata, labels, notes, userID, workspaceID,
+		modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE)
+}
+
+// InsertModelTx inserts the model into the database using the given transaction.
+func InsertModelTx(ctx context.Context, idb bun.IDB, name string, description string,
+	metadata []byte, labels string, notes string, userID model.UserID, workspaceID int,
+	visibility modelv1.ModelVisibility,
 ) (*modelv1.Model, error) {
 	mod := modelv1.Model{}
-	q := Bun().NewInsert().
+	q := idb.NewInsert().
 		Model(&mod).
-		ExcludeColumn("num_versions", "username", "archived", "id").
+		ExcludeColumn(
+			"num_versions", "username", "archived", "id", "tags", "visibility", "version",
+			"max_versions", "prune_versions").
 		Value("name", "?", name).
+		Value("normalized_name", "?", model.NormalizeModelName(name)).
 		Value("description", "?", description).
 		Value("metadata", "?::json", string(metadata)).
 		Value("labels", "string_to_array(?, ',')", labels).
 		Value("notes", "?", notes).
 		Value("user_id", "?", userID).
+		Value("owner_id", "?", userID).
 		Value("workspace_id", "?", workspaceID).
+		Value("visibility", "?", ModelVisibilityToDB(visibility)).
 		Value("creation_time", "current_timestamp").
 		Value("last_updated_time", "current_timestamp").
 		Returning("*")
 
-	err := Bun().NewSelect().
+	err := idb.NewSelect().
 		With("m", q).
 		Table("m").
 		Column("m.name").
@@ -1083,6 +1097,14 @@ func InsertModel(ctx context.Context, name string, description string, metadata
 		Column("m.metadata").
 		ColumnExpr("array_to_json(m.labels) AS labels").
 		Column("u.username").
+		Column("m.user_id").
+		Column("m.owner_id").
+		Column("m.tags").
+		Column("m.version").
+		Column("m.max_versions").
+		Column("m.prune_versions").
+		ColumnExpr(bunutils.ProtoStateDBCaseString(modelv1.ModelVisibility_value, "m.visibility",
+			"visibility", "MODEL_VISIBILITY_")).
 		ColumnExpr("proto_time(m.creation_time) as creation_time").
 		ColumnExpr("proto_time(m.last_updated_time) as last_updated_time").
 		Column("m.id").
@@ -1096,13 +1118,29 @@ func InsertModel(ctx context.Context, name string, description string, metadata
 // InsertModelVersion inserts the model version into the database.
 func InsertModelVersion(ctx context.Context, id int32, ckptID string, name string, comment string,
 	metadata []byte, labels string, notes string, userID model.UserID,
+) (*modelv1.ModelVersion, error) {
+	return InsertModelVersionTx(ctx, Bun(), id, ckptID, name, comment, metadata, labels, notes,
+		userID)
+}
+
+// InsertModelVersionTx inserts the model version into the database using the given transaction.
+func InsertModelVersionTx(ctx context.Context, idb bun.IDB, id int32, ckptID string, name string,
+	comment string, metadata []byte, labels string, notes string, userID model.UserID,
 ) (*modelv1.ModelVersion, error) {
 	modVer := modelv1.ModelVersion{}
-	mv := Bun().NewInsert().
+	// Numbers come from a counter on the model so that numbers of deleted versions are not
+	// reused. Versions may also have been inserted without it, as copies are.
+	next := idb.NewUpdate().
+		Table("models").
+		Set("last_model_version = GREATEST(last_model_version, "+
+			"(SELECT COALESCE(MAX(version), 0) FROM model_versions WHERE model_id = ?)) + 1", id).
+		Where("id = ?", id).
+		Returning("last_model_version")
+	mv := idb.NewInsert().
 		Model(&modVer).
 		ExcludeColumn("model", "checkpoint", "username", "id").
 		Value("model_id", "?", id).
-		Value("version", "(SELECT COALESCE(MAX(version), 0) + 1 FROM model_versions WHERE model_id = ?)", id).
+		Value("version", "(SELECT last_model_version FROM next_version)").
 		Value("checkpoint_uuid", "?::uuid", ckptID).
 		Value("name", "?", name).
 		Value("comment", "?", comment).
@@ -1115,13 +1153,13 @@ func InsertModelVersion(ctx context.Context, id int32, ckptID string, name strin
 		Returning("*")
 	log.Print(mv)
 
-	u := Bun().NewSelect().
+	u := idb.NewSelect().
 		Table("users").
 		Column("username").
 		Where("id = ?", userID)
 	log.Print(u)
 
-	m := Bun().NewSelect().
+	m := idb.NewSelect().
 		TableExpr("models as m").
 		Column("m.id").
 		Column("m.name").
@@ -1132,6 +1170,14 @@ func InsertModelVersion(ctx context.Context, id int32, ckptID string, name strin
 		ColumnExpr("proto_time(m.last_updated_time) as last_updated_time").
 		ColumnExpr("array_to_json(m.labels) AS labels").
 		Column("u.username").
+		Column("m.user_id").
+		Column("m.owner_id").
+		Column("m.tags").
+		Column("m.version").
+		Column("m.max_versions").
+		Column("m.prune_versions").
+		ColumnExpr(bunutils.ProtoStateDBCaseString(modelv1.ModelVisibility_value, "m.visibility",
+			"visibility", "MODEL_VISIBILITY_")).
 		Column("m.archived").
 		ColumnExpr("COUNT(mv.version) AS num_versions").
 		Join("JOIN users AS u ON u.id = m.user_id").
@@ -1140,7 +1186,7 @@ func InsertModelVersion(ctx context.Context, id int32, ckptID string, name strin
 		Group("m.id", "u.id")
 	log.Print(m)
 
-	c := Bun().NewSelect().
+	c := idb.NewSelect().
 		TableExpr("proto_checkpoints_view as c").
 		ColumnExpr("proto_time(c.report_time) as report_time").
 		Column("c.task_id").
@@ -1155,7 +1201,8 @@ func InsertModelVersion(ctx context.Context, id int32, ckptID string, name strin
 		Where("c.uuid IN (SELECT checkpoint_uuid FROM mv)")
 	log.Print(c)
 
-	err := Bun().NewSelect().
+	err := idb.NewSelect().
+		With("next_version", next).
 		With("mv", mv).
 		With("u", u).
 		With("m", m).
@@ -0,0 +1,490 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+// ModelVisibilityToDB returns how a model visibility is stored in the database. Unspecified
+// visibility is stored as workspace-wide.
+func ModelVisibilityToDB(visibility modelv1.ModelVisibility) string {
+	if visibility == modelv1.ModelVisibility_MODEL_VISIBILITY_UNSPECIFIED {
+		visibility = modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE
+	}
+	return strings.TrimPrefix(visibility.String(), "MODEL_VISIBILITY_")
+}
+
+// TransferModelOwnership makes newOwnerID the owner of a model and records the previous owner
+// in model_ownership_transfers. It returns ErrNotFound if the model does not exist.
+func TransferModelOwnership(
+	ctx context.Context, modelID int32, newOwnerID model.UserID, transferredBy model.UserID,
+) error {
+	return Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var previousOwnerID model.UserID
+		err := tx.NewSelect().
+			Table("models").
+			Column("owner_id").
+			Where("id = ?", modelID).
+			Where("deleted_at IS NULL").
+			For("UPDATE").
+			Scan(ctx, &previousOwnerID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		} else if err != nil {
+			return errors.Wrapf(err, "error getting owner of model %d", modelID)
+		}
+		if previousOwnerID == newOwnerID {
+			return nil
+		}
+
+		if _, err := tx.NewUpdate().
+			Table("models").
+			Set("owner_id = ?", newOwnerID).
+			Set("last_updated_time = current_timestamp").
+			Where("id = ?", modelID).
+			Exec(ctx); err != nil {
+			return errors.Wrapf(err, "error updating owner of model %d", modelID)
+		}
+		if _, err := tx.NewInsert().Model(&model.ModelOwnershipTransfer{
+			ModelID:         modelID,
+			PreviousOwnerID: previousOwnerID,
+			NewOwnerID:      newOwnerID,
+			TransferredBy:   transferredBy,
+		}).Exec(ctx); err != nil {
+			return errors.Wrapf(err, "error recording ownership transfer of model %d", modelID)
+		}
+		return nil
+	})
+}
+
+// LockModelTagsTx locks a model that is not deleted like LockModelTx and returns its tags, which
+// then cannot change until the transaction ends. It returns ErrNotFound if there is no such model.
+func LockModelTagsTx(ctx context.Context, idb bun.IDB, modelID int32) (map[string]string, error) {
+	var b []byte
+	err := idb.NewSelect().
+		Table("models").
+		Column("tags").
+		Where("id = ?", modelID).
+		Where("deleted_at IS NULL").
+		For("UPDATE").
+		Scan(ctx, &b)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "error locking model %d", modelID)
+	}
+	tags := map[string]string{}
+	if err := json.Unmarshal(b, &tags); err != nil {
+		return nil, errors.Wrapf(err, "error parsing tags of model %d", modelID)
+	}
+	return tags, nil
+}
+
+// SetModelTagTx sets a tag on a model that is not deleted using the given transaction, replacing
+// the tag's value if it is already set.
+func SetModelTagTx(ctx context.Context, idb bun.IDB, modelID int32, key, value string) error {
+	_, err := idb.NewUpdate().
+		Table("models").
+		Set("tags = tags || jsonb_build_object(?::text, ?::text)", key, value).
+		Set("version = version + 1").
+		Set("last_updated_time = current_timestamp").
+		Where("id = ?", modelID).
+		Where("deleted_at IS NULL").
+		Exec(ctx)
+	return errors.Wrapf(err, "error setting tag %q on model %d", key, modelID)
+}
+
+// DeleteModelTag deletes a tag from a model that is not deleted. It does nothing if the tag is not
+// set.
+func DeleteModelTag(ctx context.Context, modelID int32, key string) error {
+	_, err := Bun().NewUpdate().
+		Table("models").
+		Set("tags = tags - ?::text", key).
+		Set("version = version + 1").
+		Set("last_updated_time = current_timestamp").
+		Where("id = ?", modelID).
+		Where("deleted_at IS NULL").
+		Where("jsonb_exists(tags, ?)", key).
+		Exec(ctx)
+	return errors.Wrapf(err, "error deleting tag %q from model %d", key, modelID)
+}
+
+// AddModelLabel appends a label to the labels of a model. It does nothing if the model already
+// has the label.
+func AddModelLabel(ctx context.Context, modelID int32, label string) error {
+	_, err := Bun().NewUpdate().
+		Table("models").
+		Set("labels = array_append(coalesce(labels, '{}'), ?::text)", label).
+		Set("version = version + 1").
+		Set("last_updated_time = current_timestamp").
+		Where("id = ?", modelID).
+		Where("NOT coalesce(labels, '{}') @> ARRAY[?::text]", label).
+		Exec(ctx)
+	return errors.Wrapf(err, "error adding label %q to model %d", label, modelID)
+}
+
+// RemoveModelLabel removes a label from the labels of a model, keeping the order of the others.
+// It does nothing if the model does not have the label.
+func RemoveModelLabel(ctx context.Context, modelID int32, label string) error {
+	_, err := Bun().NewUpdate().
+		Table("models").
+		Set("labels = array_remove(labels, ?::text)", label).
+		Set("version = version + 1").
+		Set("last_updated_time = current_timestamp").
+		Where("id = ?", modelID).
+		Where("labels @> ARRAY[?::text]", label).
+		Exec(ctx)
+	return errors.Wrapf(err, "error removing label %q from model %d", label, modelID)
+}
+
+// MoveModelTx moves a model that is not deleted into another workspace using the given
+// transaction. It returns ErrNotFound if there is no such model.
+func MoveModelTx(ctx context.Context, idb bun.IDB, modelID int32, workspaceID int32) error {
+	res, err := idb.NewUpdate().
+		Table("models").
+		Set("workspace_id = ?", workspaceID).
+		Set("last_updated_time = current_timestamp").
+		Where("id = ?", modelID).
+		Where("deleted_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "error moving model %d to workspace %d", modelID, workspaceID)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RestoreModelTx restores a deleted model using the given transaction. It returns ErrNotFound if
+// there is no such deleted model.
+func RestoreModelTx(ctx context.Context, idb bun.IDB, modelID int32) error {
+	res, err := idb.NewUpdate().
+		Table("models").
+		Set("deleted_at = NULL").
+		Where("id = ?", modelID).
+		Where("deleted_at IS NOT NULL").
+		Exec(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "error restoring model %d", modelID)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CopyModelTx copies the tags and versions of a model into another model using the given
+// transaction. The copied versions are numbered from 1 in the order of the original versions and
+// share their checkpoints. If versions is nil, every version is copied, and if it is empty, none
+// are. It returns the number of versions copied.
+func CopyModelTx(
+	ctx context.Context, idb bun.IDB, fromModelID, toModelID int32, versions []int32,
+) (int, error) {
+	if _, err := idb.NewUpdate().
+		Table("models").
+		Set("tags = (SELECT tags FROM models WHERE id = ?)", fromModelID).
+		Where("id = ?", toModelID).
+		Exec(ctx); err != nil {
+		return 0, errors.Wrapf(err, "error copying tags of model %d", fromModelID)
+	}
+
+	if versions != nil && len(versions) == 0 {
+		return 0, nil
+	}
+	q := idb.NewSelect().
+		Table("model_versions").
+		ColumnExpr("?", toModelID).
+		ColumnExpr("row_number() OVER (ORDER BY version)").
+		Column("checkpoint_uuid", "name", "comment", "metadata", "labels", "notes", "user_id",
+			"creation_time").
+		ColumnExpr("current_timestamp").
+		Where("model_id = ?", fromModelID)
+	if versions != nil {
+		q = q.Where("version IN (?)", bun.In(versions))
+	}
+	res, err := idb.NewRaw(`INSERT INTO model_versions (model_id, version, checkpoint_uuid, name,
+	comment, metadata, labels, notes, user_id, creation_time, last_updated_time) ?`, q).Exec(ctx)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error copying versions of model %d", fromModelID)
+	}
+	copied, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := idb.NewUpdate().
+		Table("models").
+		Set("last_model_version = ?", copied).
+		Where("id = ?", toModelID).
+		Exec(ctx); err != nil {
+		return 0, errors.Wrapf(err, "error numbering versions of model %d", toModelID)
+	}
+	return int(copied), nil
+}
+
+// ImportedModelVersion is a version of a model recreated from an exported manifest.
+type ImportedModelVersion struct {
+	Version        int32
+	CheckpointUUID string
+	Name           string
+	Comment        string
+	Metadata       []byte
+	Labels         []string
+	Notes          string
+}
+
+// ImportModelVersionsTx sets the tags of a model and adds versions to it under their own numbers
+// using the given transaction. Versions registered later are numbered after the highest of them.
+func ImportModelVersionsTx(ctx context.Context, idb bun.IDB, modelID int32,
+	tags map[string]string, versions []ImportedModelVersion, userID model.UserID,
+) error {
+	if len(tags) > 0 {
+		b, err := json.Marshal(tags)
+		if err != nil {
+			return errors.Wrap(err, "error marshaling model tags")
+		}
+		if _, err := idb.NewUpdate().
+			Table("models").
+			Set("tags = ?::jsonb", string(b)).
+			Where("id = ?", modelID).
+			Exec(ctx); err != nil {
+			return errors.Wrapf(err, "error setting tags of model %d", modelID)
+		}
+	}
+
+	var last int32
+	for _, v := range versions {
+		labels := v.Labels
+		if labels == nil {
+			labels = []string{}
+		}
+		if _, err := idb.NewInsert().
+			Table("model_versions").
+			Value("model_id", "?", modelID).
+			Value("version", "?", v.Version).
+			Value("checkpoint_uuid", "?::uuid", v.CheckpointUUID).
+			Value("name", "?", v.Name).
+			Value("comment", "?", v.Comment).
+			Value("metadata", "?::json", string(v.Metadata)).
+			Value("labels", "?", pgdialect.Array(labels)).
+			Value("notes", "?", v.Notes).
+			Value("user_id", "?", userID).
+			Value("creation_time", "current_timestamp").
+			Value("last_updated_time", "current_timestamp").
+			Exec(ctx); err != nil {
+			return errors.Wrapf(err, "error importing version %d of model %d", v.Version, modelID)
+		}
+		if v.Version > last {
+			last = v.Version
+		}
+	}
+	_, err := idb.NewUpdate().
+		Table("models").
+		Set("last_model_version = ?", last).
+		Where("id = ?", modelID).
+		Exec(ctx)
+	return errors.Wrapf(err, "error numbering versions of model %d", modelID)
+}
+
+// ModelVersionByIdempotencyKeyTx returns the number and checkpoint of the version of a model
+// registered with the idempotency key at or after notBefore, or ErrNotFound. Keys of the model
+// recorded before notBefore are deleted. The model stays locked until the transaction ends, so
+// that requests with the same key register at most one version.
+func ModelVersionByIdempotencyKeyTx(
+	ctx context.Context, idb bun.IDB, modelID int32, key string, notBefore time.Time,
+) (version int32, checkpointUUID string, err error) {
+	if _, err := idb.NewSelect().
+		Table("models").
+		Column("id").
+		Where("id = ?", modelID).
+		For("UPDATE").
+		Exec(ctx); err != nil {
+		return 0, "", errors.Wrapf(err, "error locking model %d", modelID)
+	}
+	if _, err := idb.NewDelete().
+		Model((*model.ModelVersionIdempotencyKey)(nil)).
+		Where("model_id = ?", modelID).
+		Where("creation_time < ?", notBefore).
+		Exec(ctx); err != nil {
+		return 0, "", errors.Wrapf(err, "error deleting expired idempotency keys of model %d",
+			modelID)
+	}
+
+	err = idb.NewSelect().
+		TableExpr("model_version_idempotency_keys AS k").
+		Join("JOIN model_versions AS mv ON mv.id = k.model_version_id").
+		Column("mv.version", "k.checkpoint_uuid").
+		Where("k.model_id = ?", modelID).
+		Where("k.idempotency_key = ?", key).
+		Scan(ctx, &version, &checkpointUUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, "", ErrNotFound
+	} else if err != nil {
+		return 0, "", errors.Wrapf(err, "error getting idempotency key %q of model %d", key,
+			modelID)
+	}
+	return version, checkpointUUID, nil
+}
+
+// ModelMetadataKeyExists returns whether key is a top-level key of the metadata of a model that
+// is not deleted.
+func ModelMetadataKeyExists(ctx context.Context, key string) (bool, error) {
+	exists, err := Bun().NewSelect().
+		Table("models").
+		Where("deleted_at IS NULL").
+		Where("metadata -> ? IS NOT NULL", key).
+		Exists(ctx)
+	return exists, errors.Wrapf(err, "error checking for model metadata key %q", key)
+}
+
+// ModelVersionMetadata is the metadata of a model version.
+type ModelVersionMetadata struct {
+	ID       int32          `bun:"id"`
+	Version  int32          `bun:"version"`
+	Metadata map[string]any `bun:"metadata,type:jsonb"`
+}
+
+// GetModelVersionsMetadata returns the metadata of versions of a model, without loading their
+// checkpoints. Versions that do not exist are left out.
+func GetModelVersionsMetadata(
+	ctx context.Context, modelID int32, versions ...int32,
+) ([]ModelVersionMetadata, error) {
+	var metadata []ModelVersionMetadata
+	err := Bun().NewSelect().
+		Table("model_versions").
+		Column("id", "version", "metadata").
+		Where("model_id = ?", modelID).
+		Where("version IN (?)", bun.In(versions)).
+		Scan(ctx, &metadata)
+	return metadata, errors.Wrapf(err, "error getting metadata of versions of model %d", modelID)
+}
+
+// LockModelTx locks a model that is not deleted until the transaction ends, so that concurrent
+// registrations of its versions run one at a time. It returns ErrNotFound if there is no such
+// model.
+func LockModelTx(ctx context.Context, idb bun.IDB, modelID int32) error {
+	var id int32
+	err := idb.NewSelect().
+		Table("models").
+		Column("id").
+		Where("id = ?", modelID).
+		Where("deleted_at IS NULL").
+		For("UPDATE").
+		Scan(ctx, &id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return errors.Wrapf(err, "error locking model %d", modelID)
+}
+
+// PruneModelVersionsTx deletes up to n of the oldest versions of a model that carry none of the
+// protected labels using the given transaction. If keepLatest, the latest version of the model is
+// not deleted either. It returns the numbers of the deleted versions, oldest first, which are
+// fewer than n if not enough versions may be deleted.
+func PruneModelVersionsTx(ctx context.Context, idb bun.IDB, modelID int32, n int,
+	protectedLabels []string, keepLatest bool,
+) ([]int32, error) {
+	if protectedLabels == nil {
+		protectedLabels = []string{}
+	}
+	prunable := idb.NewSelect().
+		Table("model_versions").
+		Column("id").
+		Where("model_id = ?", modelID).
+		Where("NOT (COALESCE(labels, '{}') && ?::text[])", pgdialect.Array(protectedLabels)).
+		Order("version").
+		Limit(n)
+	if keepLatest {
+		prunable.Where(
+			"version < (SELECT MAX(version) FROM model_versions WHERE model_id = ?)", modelID)
+	}
+	var pruned []int32
+	if _, err := idb.NewDelete().
+		Table("model_versions").
+		Where("id IN (?)", prunable).
+		Returning("version").
+		Exec(ctx, &pruned); err != nil {
+		return nil, errors.Wrapf(err, "error pruning versions of model %d", modelID)
+	}
+	slices.Sort(pruned)
+	return pruned, nil
+}
+
+// InsertModelVersionIdempotencyKeyTx records the model version registered by a request with an
+// idempotency key using the given transaction.
+func InsertModelVersionIdempotencyKeyTx(
+	ctx context.Context, idb bun.IDB, k *model.ModelVersionIdempotencyKey,
+) error {
+	_, err := idb.NewInsert().Model(k).Exec(ctx)
+	return errors.Wrapf(err, "error recording idempotency key %q of model %d", k.IdempotencyKey,
+		k.ModelID)
+}
+
+// PurgeDeletedModels permanently deletes models, and all of their versions, that were soft
+// deleted before deletedBefore. It returns the number of models deleted.
+func PurgeDeletedModels(ctx context.Context, deletedBefore time.Time) (int, error) {
+	return purgeDeletedModels(ctx, func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Where("deleted_at < ?", deletedBefore)
+	})
+}
+
+// PurgeWorkspaceDeletedModels permanently deletes every soft deleted model, and all of its
+// versions, in a workspace. It returns the number of models deleted.
+func PurgeWorkspaceDeletedModels(ctx context.Context, workspaceID int32) (int, error) {
+	return purgeDeletedModels(ctx, func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Where("deleted_at IS NOT NULL").Where("workspace_id = ?", workspaceID)
+	})
+}
+
+func purgeDeletedModels(
+	ctx context.Context, filter func(q *bun.SelectQuery) *bun.SelectQuery,
+) (int, error) {
+	var purged []int32
+	err := Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		// Lock the expired models so a concurrent restore cannot lose its versions.
+		if err := tx.NewSelect().
+			Table("models").
+			Column("id").
+			Apply(filter).
+			For("UPDATE").
+			Scan(ctx, &purged); err != nil {
+			return errors.Wrap(err, "error finding deleted models to purge")
+		}
+		if len(purged) == 0 {
+			return nil
+		}
+
+		if _, err := tx.NewDelete().
+			Table("model_versions").
+			Where("model_id IN (?)", bun.In(purged)).
+			Exec(ctx); err != nil {
+			return errors.Wrap(err, "error purging versions of deleted models")
+		}
+		if _, err := tx.NewDelete().
+			Table("models").
+			Where("id IN (?)", bun.In(purged)).
+			Exec(ctx); err != nil {
+			return errors.Wrap(err, "error purging deleted models")
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(purged), nil
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// PutModelAccessGrant grants a user access to a model, replacing the access they were already
+// granted. The grant is timestamped when it is saved.
+func PutModelAccessGrant(ctx context.Context, g *model.ModelAccessGrant) error {
+	_, err := Bun().NewInsert().
+		Model(g).
+		On("CONFLICT (model_id, user_id) DO UPDATE").
+		Set("access = EXCLUDED.access").
+		Set("granted_by_id = EXCLUDED.granted_by_id").
+		Set("creation_time = EXCLUDED.creation_time").
+		Returning("*").
+		Exec(ctx)
+	return errors.Wrapf(err, "error granting user %d access to model %d", g.UserID, g.ModelID)
+}
+
+// GetModelAccessGrants returns the access grants of a model, by user id.
+func GetModelAccessGrants(ctx context.Context, modelID int32) ([]*model.ModelAccessGrant, error) {
+	grants := []*model.ModelAccessGrant{}
+	err := Bun().NewSelect().
+		Model(&grants).
+		Where("model_id = ?", modelID).
+		Order("user_id").
+		Scan(ctx)
+	return grants, errors.Wrapf(err, "error getting access grants of model %d", modelID)
+}
+
+// GetModelAccessGrant returns the access to a model granted to a user. It returns ErrNotFound if
+// the user was granted no access to the model.
+func GetModelAccessGrant(
+	ctx context.Context, modelID int32, userID model.UserID,
+) (*model.ModelAccessGrant, error) {
+	var g model.ModelAccessGrant
+	err := Bun().NewSelect().
+		Model(&g).
+		Where("model_id = ?", modelID).
+		Where("user_id = ?", userID).
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "error getting access of user %d to model %d",
+			userID, modelID)
+	}
+	return &g, nil
+}
+
+// DeleteModelAccessGrant revokes the access to a model granted to a user. It returns ErrNotFound
+// if the user was granted no access to the model.
+func DeleteModelAccessGrant(ctx context.Context, modelID int32, userID model.UserID) error {
+	res, err := Bun().NewDelete().
+		Table("model_access_grants").
+		Where("model_id = ?", modelID).
+		Where("user_id = ?", userID).
+		Exec(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "error revoking access of user %d to model %d", userID, modelID)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetModelAccessGrantWorkspaceIDs returns the workspaces of the models that a user was granted
+// access to.
+func GetModelAccessGrantWorkspaceIDs(ctx context.Context, userID model.UserID) ([]int32, error) {
+	var workspaceIDs []int32
+	err := Bun().NewSelect().
+		TableExpr("model_access_grants AS g").
+		Join("JOIN models AS m ON m.id = g.model_id").
+		ColumnExpr("DISTINCT m.workspace_id").
+		Where("g.user_id = ?", userID).
+		Where("m.deleted_at IS NULL").
+		Scan(ctx, &workspaceIDs)
+	return workspaceIDs, errors.Wrapf(err,
+		"error getting the workspaces of models user %d was granted access to", userID)
+}
+
+// GetModelAccessGrantModelIDs returns which of modelIDs a user was granted access to.
+func GetModelAccessGrantModelIDs(
+	ctx context.Context, userID model.UserID, modelIDs []int32,
+) ([]int32, error) {
+	var granted []int32
+	err := Bun().NewSelect().
+		Table("model_access_grants").
+		Column("model_id").
+		Where("user_id = ?", userID).
+		Where("model_id IN (?)", bun.In(modelIDs)).
+		Scan(ctx, &granted)
+	return granted, errors.Wrapf(err, "error getting the model access grants of user %d", userID)
+}
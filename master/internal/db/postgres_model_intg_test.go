@@ -11,6 +11,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
 	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/determined-ai/determined/master/pkg/etc"
@@ -158,3 +159,293 @@ func TestModels(t *testing.T) {
 		})
 	}
 }
+
+func TestDeletedModels(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+
+	user := RequireMockUser(t, db)
+	pmdl, err := InsertModel(ctx, uuid.NewString(), "", emptyMetadata, "", "", user.ID, 1)
+	require.NoError(t, err)
+
+	// Deleted models are hidden but can still be found for a restore.
+	holder := &modelv1.Model{}
+	require.NoError(t, db.QueryProto("delete_model", holder, pmdl.Name))
+	require.Equal(t, pmdl.Id, holder.Id)
+	require.ErrorIs(t, db.QueryProto("get_model", &modelv1.Model{}, pmdl.Name), ErrNotFound)
+	deleted := &modelv1.Model{}
+	require.NoError(t, db.QueryProto("get_deleted_model", deleted, pmdl.Name))
+	require.Equal(t, pmdl.Id, deleted.Id)
+
+	// Models deleted within the retention window are not purged.
+	_, err = PurgeDeletedModels(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, RestoreModelTx(ctx, Bun(), pmdl.Id))
+	require.ErrorIs(t, RestoreModelTx(ctx, Bun(), pmdl.Id), ErrNotFound)
+	restored := &modelv1.Model{}
+	require.NoError(t, db.QueryProto("get_model", restored, pmdl.Name))
+	require.Equal(t, pmdl.Id, restored.Id)
+
+	// Models deleted before the cutoff are purged for good.
+	require.NoError(t, db.QueryProto("delete_model", &modelv1.Model{}, pmdl.Name))
+	purged, err := PurgeDeletedModels(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, purged, 1)
+	require.ErrorIs(t, db.QueryProto("get_deleted_model", &modelv1.Model{}, pmdl.Name),
+		ErrNotFound)
+}
+
+func TestTransferModelOwnership(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+
+	creator := RequireMockUser(t, db)
+	newOwner := RequireMockUser(t, db)
+	pmdl, err := InsertModel(ctx, uuid.NewString(), "", emptyMetadata, "", "", creator.ID, 1)
+	require.NoError(t, err)
+	require.Equal(t, int32(creator.ID), pmdl.OwnerId)
+
+	require.NoError(t, TransferModelOwnership(ctx, pmdl.Id, newOwner.ID, creator.ID))
+	transferred := &modelv1.Model{}
+	require.NoError(t, db.QueryProto("get_model", transferred, pmdl.Name))
+	require.Equal(t, int32(newOwner.ID), transferred.OwnerId)
+	require.Equal(t, int32(creator.ID), transferred.UserId, "the creator is kept")
+
+	// Transferring to the current owner records nothing.
+	require.NoError(t, TransferModelOwnership(ctx, pmdl.Id, newOwner.ID, creator.ID))
+	var history []model.ModelOwnershipTransfer
+	require.NoError(t, Bun().NewSelect().Model(&history).
+		Where("model_id = ?", pmdl.Id).Scan(ctx))
+	require.Len(t, history, 1)
+	require.Equal(t, creator.ID, history[0].PreviousOwnerID)
+	require.Equal(t, newOwner.ID, history[0].NewOwnerID)
+	require.Equal(t, creator.ID, history[0].TransferredBy)
+
+	require.ErrorIs(t, TransferModelOwnership(ctx, -1, newOwner.ID, creator.ID), ErrNotFound)
+}
+
+func TestModelTags(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+
+	user := RequireMockUser(t, db)
+	pmdl, err := InsertModel(ctx, uuid.NewString(), "", emptyMetadata, "", "", user.ID, 1)
+	require.NoError(t, err)
+
+	getTags := func() map[string]string {
+		m := &modelv1.Model{}
+		require.NoError(t, db.QueryProto("get_model", m, pmdl.Name))
+		return m.Tags
+	}
+	require.Empty(t, getTags())
+
+	require.NoError(t, SetModelTagTx(ctx, Bun(), pmdl.Id, "team", "vision"))
+	require.NoError(t, SetModelTagTx(ctx, Bun(), pmdl.Id, "sla", "tier2"))
+	require.NoError(t, SetModelTagTx(ctx, Bun(), pmdl.Id, "sla", "tier1"))
+	require.Equal(t, map[string]string{"team": "vision", "sla": "tier1"}, getTags())
+	tags, err := LockModelTagsTx(ctx, Bun(), pmdl.Id)
+	require.NoError(t, err)
+	require.Equal(t, getTags(), tags)
+
+	require.NoError(t, DeleteModelTag(ctx, pmdl.Id, "sla"))
+	require.NoError(t, DeleteModelTag(ctx, pmdl.Id, "sla"), "deleting a missing tag succeeds")
+	require.Equal(t, map[string]string{"team": "vision"}, getTags())
+}
+
+func TestModelLabels(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+
+	user := RequireMockUser(t, db)
+	pmdl, err := InsertModel(ctx, uuid.NewString(), "", emptyMetadata, "b", "", user.ID, 1)
+	require.NoError(t, err)
+
+	get := func() *modelv1.Model {
+		m := &modelv1.Model{}
+		require.NoError(t, db.QueryProto("get_model", m, pmdl.Name))
+		return m
+	}
+
+	require.NoError(t, AddModelLabel(ctx, pmdl.Id, "a"))
+	require.NoError(t, AddModelLabel(ctx, pmdl.Id, "c"))
+	require.NoError(t, AddModelLabel(ctx, pmdl.Id, "a"), "adding a present label succeeds")
+	require.Equal(t, []string{"b", "a", "c"}, get().Labels)
+
+	version := get().Version
+	require.NoError(t, RemoveModelLabel(ctx, pmdl.Id, "a"))
+	require.NoError(t, RemoveModelLabel(ctx, pmdl.Id, "a"), "removing a missing label succeeds")
+	require.Equal(t, []string{"b", "c"}, get().Labels)
+	require.Equal(t, version+1, get().Version, "only the change bumps the version")
+}
+
+func TestCopyModel(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+
+	user := RequireMockUser(t, db)
+	exp := RequireMockExperiment(t, db, user)
+	tr, task := RequireMockTrial(t, db, exp)
+	a := RequireMockAllocation(t, db, task.TaskID)
+
+	source, err := InsertModel(ctx, uuid.NewString(), "", emptyMetadata, "", "", user.ID, 1)
+	require.NoError(t, err)
+	require.NoError(t, SetModelTagTx(ctx, Bun(), source.Id, "team", "vision"))
+	var ckptUUIDs []string
+	for i := 0; i < 3; i++ {
+		ckpt := MockModelCheckpoint(uuid.New(), a)
+		require.NoError(t, AddCheckpointMetadata(ctx, &ckpt, tr.ID))
+		_, err := InsertModelVersion(ctx, source.Id, ckpt.UUID.String(), uuid.NewString(), "",
+			emptyMetadata, "", "", user.ID)
+		require.NoError(t, err)
+		ckptUUIDs = append(ckptUUIDs, ckpt.UUID.String())
+	}
+
+	copied := func(versions []int32) (*modelv1.Model, []string) {
+		m, err := InsertModel(ctx, uuid.NewString(), "", emptyMetadata, "", "", user.ID, 1)
+		require.NoError(t, err)
+		n, err := CopyModelTx(ctx, Bun(), source.Id, m.Id, versions)
+		require.NoError(t, err)
+
+		var copies []struct {
+			Version        int32
+			CheckpointUUID string
+		}
+		require.NoError(t, Bun().NewSelect().Table("model_versions").
+			Column("version", "checkpoint_uuid").
+			Where("model_id = ?", m.Id).
+			Order("version").
+			Scan(ctx, &copies))
+		require.Len(t, copies, n)
+		var uuids []string
+		for i, c := range copies {
+			require.Equal(t, int32(i+1), c.Version, "copies are renumbered from 1")
+			uuids = append(uuids, c.CheckpointUUID)
+		}
+		require.NoError(t, db.QueryProto("get_model", m, m.Name))
+		return m, uuids
+	}
+
+	m, uuids := copied(nil)
+	require.Equal(t, ckptUUIDs, uuids, "copies share the checkpoints")
+	require.Equal(t, map[string]string{"team": "vision"}, m.Tags)
+
+	_, uuids = copied([]int32{1, 3})
+	require.Equal(t, []string{ckptUUIDs[0], ckptUUIDs[2]}, uuids)
+}
+
+func TestModelVisibility(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+
+	user := RequireMockUser(t, db)
+	pmdl, err := InsertModel(ctx, uuid.NewString(), "", emptyMetadata, "", "", user.ID, 1)
+	require.NoError(t, err)
+	require.Equal(t, modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE, pmdl.Visibility)
+
+	private, err := InsertModelTx(ctx, Bun(), uuid.NewString(), "", emptyMetadata, "", "",
+		user.ID, 1, modelv1.ModelVisibility_MODEL_VISIBILITY_PRIVATE)
+	require.NoError(t, err)
+	require.Equal(t, modelv1.ModelVisibility_MODEL_VISIBILITY_PRIVATE, private.Visibility)
+	m := &modelv1.Model{}
+	require.NoError(t, db.QueryProto("get_model", m, private.Name))
+	require.Equal(t, modelv1.ModelVisibility_MODEL_VISIBILITY_PRIVATE, m.Visibility)
+
+	require.Equal(t, "WORKSPACE",
+		ModelVisibilityToDB(modelv1.ModelVisibility_MODEL_VISIBILITY_UNSPECIFIED))
+}
+
+func TestModelVersionIdempotencyKeys(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+
+	user := RequireMockUser(t, db)
+	exp := RequireMockExperiment(t, db, user)
+	tr, task := RequireMockTrial(t, db, exp)
+	a := RequireMockAllocation(t, db, task.TaskID)
+
+	m, err := InsertModel(ctx, uuid.NewString(), "", emptyMetadata, "", "", user.ID, 1)
+	require.NoError(t, err)
+	ckpt := MockModelCheckpoint(uuid.New(), a)
+	require.NoError(t, AddCheckpointMetadata(ctx, &ckpt, tr.ID))
+	mv, err := InsertModelVersion(ctx, m.Id, ckpt.UUID.String(), uuid.NewString(), "",
+		emptyMetadata, "", "", user.ID)
+	require.NoError(t, err)
+
+	lookup := func(notBefore time.Time) (int32, string, error) {
+		var version int32
+		var checkpointUUID string
+		err := Bun().RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			var err error
+			version, checkpointUUID, err = ModelVersionByIdempotencyKeyTx(ctx, tx, m.Id, "key",
+				notBefore)
+			return err
+		})
+		return version, checkpointUUID, err
+	}
+
+	_, _, err = lookup(time.Now().Add(-time.Hour))
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, InsertModelVersionIdempotencyKeyTx(ctx, Bun(),
+		&model.ModelVersionIdempotencyKey{
+			ModelID:        m.Id,
+			IdempotencyKey: "key",
+			ModelVersionID: mv.Id,
+			CheckpointUUID: ckpt.UUID.String(),
+		}))
+	version, checkpointUUID, err := lookup(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, mv.Version, version)
+	require.Equal(t, ckpt.UUID.String(), checkpointUUID)
+
+	// Expired keys are forgotten.
+	_, _, err = lookup(time.Now().Add(time.Hour))
+	require.ErrorIs(t, err, ErrNotFound)
+	_, _, err = lookup(time.Now().Add(-time.Hour))
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestModelVersionNumbersNotReused(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+
+	user := RequireMockUser(t, db)
+	exp := RequireMockExperiment(t, db, user)
+	tr, task := RequireMockTrial(t, db, exp)
+	a := RequireMockAllocation(t, db, task.TaskID)
+
+	m, err := InsertModel(ctx, uuid.NewString(), "", emptyMetadata, "", "", user.ID, 1)
+	require.NoError(t, err)
+	ckpt := MockModelCheckpoint(uuid.New(), a)
+	require.NoError(t, AddCheckpointMetadata(ctx, &ckpt, tr.ID))
+	insert := func() *modelv1.ModelVersion {
+		mv, err := InsertModelVersion(ctx, m.Id, ckpt.UUID.String(), uuid.NewString(), "",
+			emptyMetadata, "", "", user.ID)
+		require.NoError(t, err)
+		return mv
+	}
+
+	require.Equal(t, int32(1), insert().Version)
+	latest := insert()
+	require.Equal(t, int32(2), latest.Version)
+
+	_, err = Bun().NewDelete().Table("model_versions").Where("id = ?", latest.Id).Exec(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int32(3), insert().Version, "the deleted latest number is not reused")
+}
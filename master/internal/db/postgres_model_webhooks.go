@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// InsertModelWebhook adds a webhook to a model.
+func InsertModelWebhook(ctx context.Context, w *model.ModelWebhook) error {
+	_, err := Bun().NewInsert().Model(w).Returning("*").Exec(ctx)
+	return errors.Wrapf(err, "error adding webhook to model %d", w.ModelID)
+}
+
+// GetModelWebhooks returns the webhooks of a model, oldest first.
+func GetModelWebhooks(ctx context.Context, modelID int32) ([]*model.ModelWebhook, error) {
+	webhooks := []*model.ModelWebhook{}
+	err := Bun().NewSelect().
+		Model(&webhooks).
+		Where("model_id = ?", modelID).
+		Order("id").
+		Scan(ctx)
+	return webhooks, errors.Wrapf(err, "error getting webhooks of model %d", modelID)
+}
+
+// GetModelWebhook returns a webhook of a model. It returns ErrNotFound if the model has no
+// webhook with the id.
+func GetModelWebhook(ctx context.Context, modelID, webhookID int32) (*model.ModelWebhook, error) {
+	var w model.ModelWebhook
+	err := Bun().NewSelect().
+		Model(&w).
+		Where("id = ?", webhookID).
+		Where("model_id = ?", modelID).
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "error getting webhook %d of model %d", webhookID, modelID)
+	}
+	return &w, nil
+}
+
+// UpdateModelWebhook saves the given columns of a webhook.
+func UpdateModelWebhook(ctx context.Context, w *model.ModelWebhook, columns ...string) error {
+	_, err := Bun().NewUpdate().
+		Model(w).
+		Column(columns...).
+		WherePK().
+		Exec(ctx)
+	return errors.Wrapf(err, "error updating webhook %d", w.ID)
+}
+
+// DeleteModelWebhook removes a webhook from a model, along with its delivery log. It returns
+// ErrNotFound if the model has no webhook with the id.
+func DeleteModelWebhook(ctx context.Context, modelID, webhookID int32) error {
+	res, err := Bun().NewDelete().
+		Table("model_webhooks").
+		Where("id = ?", webhookID).
+		Where("model_id = ?", modelID).
+		Exec(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "error deleting webhook %d of model %d", webhookID, modelID)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// EnqueueModelWebhookEvent queues a delivery of payload to every webhook of a model that is sent
+// event. It returns the number of deliveries queued.
+func EnqueueModelWebhookEvent(
+	ctx context.Context, modelID int32, event string, payload []byte,
+) (int, error) {
+	res, err := Bun().NewRaw(`
+INSERT INTO model_webhook_deliveries (webhook_id, event, payload)
+SELECT id, ?, ? FROM model_webhooks WHERE model_id = ? AND ? = ANY(events)`,
+		event, string(payload), modelID, event).Exec(ctx)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error queueing %s event of model %d", event, modelID)
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// GetModelWebhookDeliveries returns a page of the delivery log of a webhook, most recent first,
+// and the total number of deliveries. A limit of 0 returns every delivery after offset.
+func GetModelWebhookDeliveries(
+	ctx context.Context, webhookID int32, offset, limit int,
+) ([]*model.ModelWebhookDelivery, int, error) {
+	deliveries := []*model.ModelWebhookDelivery{}
+	query := Bun().NewSelect().
+		Model(&deliveries).
+		ExcludeColumn("payload").
+		Where("webhook_id = ?", webhookID).
+		Order("creation_time DESC", "id DESC").
+		Offset(offset)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	total, err := query.ScanAndCount(ctx)
+	return deliveries, total, errors.Wrapf(err, "error getting deliveries of webhook %d",
+		webhookID)
+}
+
+// ModelWebhookAttempt is a pending delivery claimed for an attempt, with where to send it.
+type ModelWebhookAttempt struct {
+	model.ModelWebhookDelivery `bun:",extend"`
+	URL                        string `bun:"url"`
+	Secret                     string `bun:"secret" json:"-"`
+}
+
+// ClaimModelWebhookDeliveries claims up to limit pending deliveries that are due. Claimed
+// deliveries are not claimed again until lease passes, so that a delivery whose worker dies is
+// eventually retried.
+func ClaimModelWebhookDeliveries(
+	ctx context.Context, limit int, lease time.Duration,
+) ([]*ModelWebhookAttempt, error) {
+	attempts := []*ModelWebhookAttempt{}
+	err := Bun().NewRaw(`
+WITH claimed AS (
+    UPDATE model_webhook_deliveries AS d
+    SET next_attempt_time = now() + ? * interval '1 microsecond'
+    FROM (
+        SELECT id FROM model_webhook_deliveries
+        WHERE state = ? AND next_attempt_time <= now()
+        ORDER BY next_attempt_time
+        LIMIT ?
+        FOR UPDATE SKIP LOCKED
+    ) AS q
+    WHERE d.id = q.id
+    RETURNING d.*
+)
+SELECT claimed.*, w.url, w.secret
+FROM claimed JOIN model_webhooks AS w ON w.id = claimed.webhook_id`,
+		lease.Microseconds(), model.ModelWebhookDeliveryPending, limit).Scan(ctx, &attempts)
+	return attempts, errors.Wrap(err, "error claiming model webhook deliveries")
+}
+
+// FinishModelWebhookAttempt records the outcome of an attempt to deliver. A failed attempt is
+// retried at retryAt, or marked as failed for good if retryAt is nil.
+func FinishModelWebhookAttempt(
+	ctx context.Context, deliveryID int32, attemptErr error, retryAt *time.Time,
+) error {
+	query := Bun().NewUpdate().
+		Table("model_webhook_deliveries").
+		Set("attempts = attempts + 1").
+		Set("last_attempt_time = now()").
+		Where("id = ?", deliveryID)
+	switch {
+	case attemptErr == nil:
+		query = query.
+			Set("state = ?", model.ModelWebhookDeliveryDelivered).
+			Set("last_error = ''")
+	case retryAt != nil:
+		query = query.
+			Set("last_error = ?", attemptErr.Error()).
+			Set("next_attempt_time = ?", *retryAt)
+	default:
+		query = query.
+			Set("state = ?", model.ModelWebhookDeliveryFailed).
+			Set("last_error = ?", attemptErr.Error())
+	}
+	_, err := query.Exec(ctx)
+	return errors.Wrapf(err, "error recording attempt of model webhook delivery %d", deliveryID)
+}
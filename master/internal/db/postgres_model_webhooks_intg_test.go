@@ -0,0 +1,99 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestModelWebhookDeliveries(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, etc.SetRootPath(RootFromDB))
+	db := MustResolveTestPostgres(t)
+	MustMigrateTestPostgres(t, db, MigrationsFromDB)
+	user := RequireMockUser(t, db)
+
+	m, err := InsertModel(ctx, uuid.NewString(), "", emptyMetadata, "", "", user.ID, 1)
+	require.NoError(t, err)
+	created := &model.ModelWebhook{
+		ModelID: m.Id,
+		URL:     "http://localhost/created",
+		Events:  []string{model.ModelWebhookVersionCreated, model.ModelWebhookVersionPromoted},
+		Secret:  "secret",
+	}
+	require.NoError(t, InsertModelWebhook(ctx, created))
+	promoted := &model.ModelWebhook{
+		ModelID: m.Id,
+		URL:     "http://localhost/promoted",
+		Events:  []string{model.ModelWebhookVersionPromoted},
+	}
+	require.NoError(t, InsertModelWebhook(ctx, promoted))
+	webhooks, err := GetModelWebhooks(ctx, m.Id)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 2)
+
+	queued, err := EnqueueModelWebhookEvent(ctx, m.Id, model.ModelWebhookVersionCreated,
+		[]byte(`{"event_type":"MODEL_VERSION_CREATED"}`))
+	require.NoError(t, err)
+	require.Equal(t, 1, queued, "only webhooks sent the event get a delivery")
+
+	claim := func() *ModelWebhookAttempt {
+		attempts, err := ClaimModelWebhookDeliveries(ctx, 100, time.Hour)
+		require.NoError(t, err)
+		for _, a := range attempts {
+			if a.WebhookID == created.ID {
+				return a
+			}
+		}
+		return nil
+	}
+	a := claim()
+	require.NotNil(t, a)
+	require.Equal(t, created.URL, a.URL)
+	require.Equal(t, "secret", a.Secret)
+	require.Nil(t, claim(), "claimed deliveries are not claimed again during their lease")
+
+	retryAt := time.Now().Add(-time.Second)
+	require.NoError(t, FinishModelWebhookAttempt(ctx, a.ID, fmt.Errorf("returned 503"), &retryAt))
+	a = claim()
+	require.NotNil(t, a, "failed deliveries are retried")
+	require.Equal(t, int32(1), a.Attempts)
+	require.NoError(t, FinishModelWebhookAttempt(ctx, a.ID, nil, nil))
+
+	deliveries, total, err := GetModelWebhookDeliveries(ctx, created.ID, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, model.ModelWebhookDeliveryDelivered, deliveries[0].State)
+	require.Equal(t, int32(2), deliveries[0].Attempts)
+	require.Empty(t, deliveries[0].LastError)
+
+	require.ErrorIs(t, DeleteModelWebhook(ctx, m.Id, promoted.ID+1000), ErrNotFound)
+	require.NoError(t, DeleteModelWebhook(ctx, m.Id, promoted.ID))
+	_, err = GetModelWebhook(ctx, m.Id, promoted.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// Permanently deleting the model deletes its webhooks and their deliveries.
+	_, err = Bun().NewUpdate().Table("models").
+		Set("deleted_at = now() - interval '1 hour'").
+		Where("id = ?", m.Id).
+		Exec(ctx)
+	require.NoError(t, err)
+	_, err = PurgeDeletedModels(ctx, time.Now())
+	require.NoError(t, err)
+	webhooks, err = GetModelWebhooks(ctx, m.Id)
+	require.NoError(t, err)
+	require.Empty(t, webhooks)
+	_, total, err = GetModelWebhookDeliveries(ctx, created.ID, 0, 0)
+	require.NoError(t, err)
+	require.Zero(t, total)
+}
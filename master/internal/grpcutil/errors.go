@@ -3,10 +3,13 @@ package grpcutil
 import (
 	"context"
 	"encoding/json"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -49,6 +52,8 @@ func errorHandler(
 		}
 	}
 
+	setRetryAfter(w, s)
+
 	response := errorBody{
 		Error: errorMessage{
 			Code:    s.Code(),
@@ -65,6 +70,18 @@ func errorHandler(
 	}
 }
 
+// setRetryAfter sets the Retry-After header, in whole seconds, of errors whose status has a
+// RetryInfo detail.
+func setRetryAfter(w http.ResponseWriter, s *status.Status) {
+	for _, d := range s.Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok && info.RetryDelay != nil {
+			seconds := math.Ceil(info.RetryDelay.AsDuration().Seconds())
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Max(seconds, 1))))
+			return
+		}
+	}
+}
+
 // ConnectionIsClosed returns whether the connection has been closed from the client's side.
 func ConnectionIsClosed(stream grpc.ServerStream) bool {
 	return stream.Context().Err() != nil
@@ -0,0 +1,429 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uptrace/bun"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+// AuditAuthZType is the authz string id of the audit decorator.
+const AuditAuthZType = "audit"
+
+// AuditWrappedAuthZTypeEnv names the environment variable that selects which registered
+// implementation the audit decorator wraps. When unset, the authz fallback type is used.
+const AuditWrappedAuthZTypeEnv = "DET_MODEL_AUTHZ_AUDIT_WRAPPED"
+
+// ModelAuthZAudit wraps another ModelAuthZ and logs every authz decision it makes.
+type ModelAuthZAudit struct {
+	// ModelAuthZ is the wrapped implementation. If nil, it is resolved on each call from
+	// AuditWrappedAuthZTypeEnv or the configured fallback type.
+	ModelAuthZ
+}
+
+func (a *ModelAuthZAudit) wrapped() ModelAuthZ {
+	impl, err := a.resolveWrapped()
+	if err != nil {
+		// Validate fails master startup in this case, like AuthZProvider.Get() does for an
+		// unknown type, so that decisions never fall back to a more permissive type.
+		panic(err)
+	}
+	return impl
+}
+
+// resolveWrapped returns the wrapped implementation, looking up the registered type named by
+// AuditWrappedAuthZTypeEnv or the fallback type if none was given.
+func (a *ModelAuthZAudit) resolveWrapped() (ModelAuthZ, error) {
+	if a.ModelAuthZ != nil {
+		return a.ModelAuthZ, nil
+	}
+
+	authZType := os.Getenv(AuditWrappedAuthZTypeEnv)
+	if authZType == "" {
+		if fallback := config.GetAuthZConfig().FallbackType; fallback != nil {
+			authZType = *fallback
+		}
+	}
+	if authZType == AuditAuthZType {
+		return nil, fmt.Errorf("model authz provider %q can't wrap itself, set %s",
+			AuditAuthZType, AuditWrappedAuthZTypeEnv)
+	}
+	impl, ok := AuthZProvider.GetType(authZType)
+	if !ok {
+		return nil, fmt.Errorf("model authz provider %q wraps %q, which is not registered, "+
+			"must be one of: %s", AuditAuthZType, authZType,
+			strings.Join(AuthZProvider.Types(), ", "))
+	}
+	return impl, nil
+}
+
+func logDecision(curUser model.User, method string, fields log.Fields, err error) {
+	entry := log.WithFields(fields).WithFields(log.Fields{
+		"userID":  curUser.ID,
+		"method":  method,
+		"allowed": err == nil,
+	})
+	if err != nil {
+		entry.WithError(err).Info("model authz decision")
+		return
+	}
+	entry.Debug("model authz decision")
+}
+
+func modelFields(m *modelv1.Model, workspaceID int32) log.Fields {
+	fields := log.Fields{"workspaceID": workspaceID}
+	if m != nil {
+		fields["modelID"] = m.Id
+	}
+	return fields
+}
+
+func modelVersionFields(
+	m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32,
+) log.Fields {
+	fields := modelFields(m, workspaceID)
+	if modelVersion != nil {
+		fields["modelVersionID"] = modelVersion.Id
+	}
+	return fields
+}
+
+// CanGetModels calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanGetModels(ctx context.Context,
+	curUser model.User, workspaceIDs []int32,
+) (workspaceIDsWithPermsFilter []int32, serverError error) {
+	workspaceIDsWithPermsFilter, serverError = a.wrapped().CanGetModels(ctx, curUser, workspaceIDs)
+	logDecision(curUser, "CanGetModels", log.Fields{"workspaceIDs": workspaceIDs}, serverError)
+	return workspaceIDsWithPermsFilter, serverError
+}
+
+// CanGetModelsByLabel calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanGetModelsByLabel(ctx context.Context,
+	curUser model.User, workspaceIDs []int32, labels []string,
+) (workspaceIDsWithPermsFilter []int32, labelsFilter []string, serverError error) {
+	workspaceIDsWithPermsFilter, labelsFilter, serverError = a.wrapped().CanGetModelsByLabel(
+		ctx, curUser, workspaceIDs, labels)
+	logDecision(curUser, "CanGetModelsByLabel",
+		log.Fields{"workspaceIDs": workspaceIDs, "labels": labels}, serverError)
+	return workspaceIDsWithPermsFilter, labelsFilter, serverError
+}
+
+// Validate checks that the wrapped implementation is registered and validates it. It is not an
+// authz decision, so it is not logged.
+func (a *ModelAuthZAudit) Validate(ctx context.Context) error {
+	impl, err := a.resolveWrapped()
+	if err != nil {
+		return err
+	}
+	return impl.Validate(ctx)
+}
+
+// CanAccessModelWorkspace calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanAccessModelWorkspace(ctx context.Context, curUser model.User,
+	workspaceID int32,
+) (bool, error) {
+	ok, err := a.wrapped().CanAccessModelWorkspace(ctx, curUser, workspaceID)
+	fields := modelFields(nil, workspaceID)
+	fields["accessible"] = ok
+	logDecision(curUser, "CanAccessModelWorkspace", fields, err)
+	return ok, err
+}
+
+// CanGetModel calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanGetModel(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	err := a.wrapped().CanGetModel(ctx, curUser, m, workspaceID)
+	logDecision(curUser, "CanGetModel", modelFields(m, workspaceID), err)
+	return err
+}
+
+// ExplainGetModel calls the wrapped implementation and logs the decision and its reason.
+func (a *ModelAuthZAudit) ExplainGetModel(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) (allowed bool, reason string, serverError error) {
+	allowed, reason, serverError = a.wrapped().ExplainGetModel(ctx, curUser, m, workspaceID)
+	fields := modelFields(m, workspaceID)
+	fields["explainedAllowed"] = allowed
+	fields["reason"] = reason
+	logDecision(curUser, "ExplainGetModel", fields, serverError)
+	return allowed, reason, serverError
+}
+
+// CanGetModelsByIDs calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanGetModelsByIDs(ctx context.Context, curUser model.User,
+	modelIDs []int32, workspaceID int32,
+) (allowed map[int32]bool, serverError error) {
+	allowed, serverError = a.wrapped().CanGetModelsByIDs(ctx, curUser, modelIDs, workspaceID)
+	fields := modelFields(nil, workspaceID)
+	fields["modelIDs"] = modelIDs
+	fields["allowed"] = allowed
+	logDecision(curUser, "CanGetModelsByIDs", fields, serverError)
+	return allowed, serverError
+}
+
+// CanEditModel calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanEditModel(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	err := a.wrapped().CanEditModel(ctx, curUser, m, workspaceID)
+	logDecision(curUser, "CanEditModel", modelFields(m, workspaceID), err)
+	return err
+}
+
+// CanManageModelAccess calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanManageModelAccess(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	err := a.wrapped().CanManageModelAccess(ctx, curUser, m, workspaceID)
+	logDecision(curUser, "CanManageModelAccess", modelFields(m, workspaceID), err)
+	return err
+}
+
+// CanEditModelWebhooks calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanEditModelWebhooks(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	err := a.wrapped().CanEditModelWebhooks(ctx, curUser, m, workspaceID)
+	logDecision(curUser, "CanEditModelWebhooks", modelFields(m, workspaceID), err)
+	return err
+}
+
+// CanEditModelMetadata calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanEditModelMetadata(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	err := a.wrapped().CanEditModelMetadata(ctx, curUser, m, workspaceID)
+	logDecision(curUser, "CanEditModelMetadata", modelFields(m, workspaceID), err)
+	return err
+}
+
+// CanEditModelTags calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanEditModelTags(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	err := a.wrapped().CanEditModelTags(ctx, curUser, m, workspaceID)
+	logDecision(curUser, "CanEditModelTags", modelFields(m, workspaceID), err)
+	return err
+}
+
+// CanArchiveModel calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanArchiveModel(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	err := a.wrapped().CanArchiveModel(ctx, curUser, m, workspaceID)
+	logDecision(curUser, "CanArchiveModel", modelFields(m, workspaceID), err)
+	return err
+}
+
+// CanUnarchiveModel calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanUnarchiveModel(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	err := a.wrapped().CanUnarchiveModel(ctx, curUser, m, workspaceID)
+	logDecision(curUser, "CanUnarchiveModel", modelFields(m, workspaceID), err)
+	return err
+}
+
+// CanCreateModel calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanCreateModel(ctx context.Context, idb bun.IDB,
+	curUser model.User, workspaceID int32,
+) error {
+	err := a.wrapped().CanCreateModel(ctx, idb, curUser, workspaceID)
+	logDecision(curUser, "CanCreateModel", modelFields(nil, workspaceID), err)
+	return err
+}
+
+// GetModelCreationQuota calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) GetModelCreationQuota(ctx context.Context, curUser model.User,
+	workspaceID int32,
+) (*model.ModelCreationQuota, error) {
+	quota, err := a.wrapped().GetModelCreationQuota(ctx, curUser, workspaceID)
+	fields := modelFields(nil, workspaceID)
+	if quota != nil {
+		fields["allowed"] = quota.Allowed
+	}
+	logDecision(curUser, "GetModelCreationQuota", fields, err)
+	return quota, err
+}
+
+// CanDeleteModel calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanDeleteModel(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	err := a.wrapped().CanDeleteModel(ctx, curUser, m, workspaceID)
+	logDecision(curUser, "CanDeleteModel", modelFields(m, workspaceID), err)
+	return err
+}
+
+// CanRestoreModel calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanRestoreModel(ctx context.Context, idb bun.IDB,
+	curUser model.User, m *modelv1.Model, workspaceID int32,
+) error {
+	err := a.wrapped().CanRestoreModel(ctx, idb, curUser, m, workspaceID)
+	logDecision(curUser, "CanRestoreModel", modelFields(m, workspaceID), err)
+	return err
+}
+
+// CanTransferModelOwnership calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanTransferModelOwnership(ctx context.Context, curUser model.User,
+	m *modelv1.Model, newOwnerID int32,
+) error {
+	err := a.wrapped().CanTransferModelOwnership(ctx, curUser, m, newOwnerID)
+	fields := modelFields(m, m.GetWorkspaceId())
+	fields["newOwnerID"] = newOwnerID
+	logDecision(curUser, "CanTransferModelOwnership", fields, err)
+	return err
+}
+
+// CanGetModelVersions calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanGetModelVersions(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	err := a.wrapped().CanGetModelVersions(ctx, curUser, m, workspaceID)
+	logDecision(curUser, "CanGetModelVersions", modelFields(m, workspaceID), err)
+	return err
+}
+
+// CanGetModelVersion calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanGetModelVersion(ctx context.Context, curUser model.User,
+	m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32,
+) error {
+	err := a.wrapped().CanGetModelVersion(ctx, curUser, m, modelVersion, workspaceID)
+	logDecision(curUser, "CanGetModelVersion",
+		modelVersionFields(m, modelVersion, workspaceID), err)
+	return err
+}
+
+// CanDownloadModelVersionCheckpoint calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanDownloadModelVersionCheckpoint(ctx context.Context,
+	curUser model.User, modelVersion *modelv1.ModelVersion, workspaceID int32,
+	checkpointWorkspaceID *int32,
+) (bool, error) {
+	ok, err := a.wrapped().CanDownloadModelVersionCheckpoint(ctx, curUser, modelVersion,
+		workspaceID, checkpointWorkspaceID)
+	fields := modelVersionFields(modelVersion.GetModel(), modelVersion, workspaceID)
+	if checkpointWorkspaceID != nil {
+		fields["checkpointWorkspaceID"] = *checkpointWorkspaceID
+	}
+	fields["downloadable"] = ok
+	logDecision(curUser, "CanDownloadModelVersionCheckpoint", fields, err)
+	return ok, err
+}
+
+// CanCreateModelVersion calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanCreateModelVersion(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	err := a.wrapped().CanCreateModelVersion(ctx, curUser, m, workspaceID)
+	logDecision(curUser, "CanCreateModelVersion", modelFields(m, workspaceID), err)
+	return err
+}
+
+// CanCreateModelVersionFromCheckpoint calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanCreateModelVersionFromCheckpoint(ctx context.Context,
+	curUser model.User, m *modelv1.Model, workspaceID int32, checkpointUUID string,
+	checkpointWorkspaceID *int32,
+) error {
+	err := a.wrapped().CanCreateModelVersionFromCheckpoint(ctx, curUser, m, workspaceID,
+		checkpointUUID, checkpointWorkspaceID)
+	fields := modelFields(m, workspaceID)
+	fields["checkpointUUID"] = checkpointUUID
+	if checkpointWorkspaceID != nil {
+		fields["checkpointWorkspaceID"] = *checkpointWorkspaceID
+	}
+	logDecision(curUser, "CanCreateModelVersionFromCheckpoint", fields, err)
+	return err
+}
+
+// CanEditModelVersion calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanEditModelVersion(ctx context.Context, curUser model.User,
+	m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32,
+) error {
+	err := a.wrapped().CanEditModelVersion(ctx, curUser, m, modelVersion, workspaceID)
+	logDecision(curUser, "CanEditModelVersion",
+		modelVersionFields(m, modelVersion, workspaceID), err)
+	return err
+}
+
+// CanDeleteModelVersion calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanDeleteModelVersion(ctx context.Context, curUser model.User,
+	m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32,
+) error {
+	err := a.wrapped().CanDeleteModelVersion(ctx, curUser, m, modelVersion, workspaceID)
+	logDecision(curUser, "CanDeleteModelVersion",
+		modelVersionFields(m, modelVersion, workspaceID), err)
+	return err
+}
+
+// CanForceDeleteModelVersion calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanForceDeleteModelVersion(ctx context.Context, curUser model.User,
+	m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32,
+) error {
+	err := a.wrapped().CanForceDeleteModelVersion(ctx, curUser, m, modelVersion, workspaceID)
+	logDecision(curUser, "CanForceDeleteModelVersion",
+		modelVersionFields(m, modelVersion, workspaceID), err)
+	return err
+}
+
+// CanMoveModel calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CanMoveModel(ctx context.Context, idb bun.IDB,
+	curUser model.User, m *modelv1.Model, fromWorkspaceID int32, toWorkspaceID int32,
+) error {
+	err := a.wrapped().CanMoveModel(ctx, idb, curUser, m, fromWorkspaceID, toWorkspaceID)
+	fields := modelFields(m, fromWorkspaceID)
+	fields["toWorkspaceID"] = toWorkspaceID
+	logDecision(curUser, "CanMoveModel", fields, err)
+	return err
+}
+
+// MaskModelFields calls the wrapped implementation and logs whether anything was masked.
+func (a *ModelAuthZAudit) MaskModelFields(ctx context.Context, curUser model.User,
+	m *modelv1.Model,
+) *modelv1.Model {
+	masked := a.wrapped().MaskModelFields(ctx, curUser, m)
+	fields := modelFields(m, m.GetWorkspaceId())
+	fields["masked"] = masked != m
+	logDecision(curUser, "MaskModelFields", fields, nil)
+	return masked
+}
+
+// FilterReadableModelsQuery calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) FilterReadableModelsQuery(
+	ctx context.Context, curUser model.User, query *bun.SelectQuery,
+) (*bun.SelectQuery, error) {
+	query, err := a.wrapped().FilterReadableModelsQuery(ctx, curUser, query)
+	logDecision(curUser, "FilterReadableModelsQuery", log.Fields{}, err)
+	return query, err
+}
+
+// CountModels calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) CountModels(
+	ctx context.Context, curUser model.User, workspaceID int32, query *bun.SelectQuery,
+) (int, error) {
+	count, err := a.wrapped().CountModels(ctx, curUser, workspaceID, query)
+	fields := modelFields(nil, workspaceID)
+	fields["count"] = count
+	logDecision(curUser, "CountModels", fields, err)
+	return count, err
+}
+
+// FilterModelVersionsQuery calls the wrapped implementation and logs the decision.
+func (a *ModelAuthZAudit) FilterModelVersionsQuery(
+	ctx context.Context, curUser model.User, m *modelv1.Model, query *bun.SelectQuery,
+) (*bun.SelectQuery, error) {
+	query, err := a.wrapped().FilterModelVersionsQuery(ctx, curUser, m, query)
+	logDecision(curUser, "FilterModelVersionsQuery", modelFields(m, m.GetWorkspaceId()), err)
+	return query, err
+}
+
+func init() {
+	AuthZProvider.Register(AuditAuthZType, &ModelAuthZAudit{})
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/authz"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+func TestModelAuthZAuditPreservesDecisions(t *testing.T) {
+	hook := test.NewGlobal()
+	defer hook.Reset()
+	prevLevel := log.GetLevel()
+	log.SetLevel(log.DebugLevel)
+	defer log.SetLevel(prevLevel)
+
+	ctx := context.Background()
+	a := &ModelAuthZAudit{ModelAuthZ: &ModelAuthZBasic{}}
+	owner := model.User{ID: 1}
+	other := model.User{ID: 2}
+	m := &modelv1.Model{Id: 7, UserId: 1, OwnerId: 1}
+
+	require.NoError(t, a.CanDeleteModel(ctx, owner, m, 3))
+	entry := hook.LastEntry()
+	require.Equal(t, log.DebugLevel, entry.Level)
+	require.Equal(t, "CanDeleteModel", entry.Data["method"])
+	require.Equal(t, true, entry.Data["allowed"])
+	require.Equal(t, int32(7), entry.Data["modelID"])
+	require.Equal(t, int32(3), entry.Data["workspaceID"])
+
+	err := a.CanDeleteModel(ctx, other, m, 3)
+	require.Equal(t, (&ModelAuthZBasic{}).CanDeleteModel(ctx, other, m, 3), err)
+	require.True(t, authz.IsPermissionDenied(err))
+	entry = hook.LastEntry()
+	require.Equal(t, log.InfoLevel, entry.Level)
+	require.Equal(t, false, entry.Data["allowed"])
+	require.Equal(t, model.UserID(2), entry.Data["userID"])
+}
+
+func TestModelAuthZAuditCanGetModelsByIDs(t *testing.T) {
+	hook := test.NewGlobal()
+	defer hook.Reset()
+	prevLevel := log.GetLevel()
+	log.SetLevel(log.DebugLevel)
+	defer log.SetLevel(prevLevel)
+
+	a := &ModelAuthZAudit{ModelAuthZ: &ModelAuthZBasic{}}
+	allowed, err := a.CanGetModelsByIDs(context.Background(), model.User{ID: 1}, []int32{4, 5}, 0)
+	require.NoError(t, err)
+	require.Equal(t, map[int32]bool{4: true, 5: true}, allowed)
+	entry := hook.LastEntry()
+	require.Equal(t, "CanGetModelsByIDs", entry.Data["method"])
+	require.Equal(t, []int32{4, 5}, entry.Data["modelIDs"])
+}
+
+func TestModelAuthZAuditWrappedType(t *testing.T) {
+	t.Setenv(AuditWrappedAuthZTypeEnv, "permissive")
+	impl, err := (&ModelAuthZAudit{}).resolveWrapped()
+	require.NoError(t, err)
+	permissive, _ := AuthZProvider.GetType("permissive")
+	require.Same(t, permissive, impl)
+
+	// Unknown types must not fall back to a more permissive implementation.
+	t.Setenv(AuditWrappedAuthZTypeEnv, "not-registered")
+	require.Panics(t, func() { (&ModelAuthZAudit{}).wrapped() })
+}
@@ -20,6 +20,25 @@ func (a *ModelAuthZBasic) CanGetModels(ctx context.Context,
 	return workspaceIDs, nil
 }
 
+// CanGetModelsByLabel returns the given workspaces and labels unchanged and a nil error.
+func (a *ModelAuthZBasic) CanGetModelsByLabel(ctx context.Context,
+	curUser model.User, workspaceIDs []int32, labels []string,
+) (workspaceIDsWithPermsFilter []int32, labelsFilter []string, serverError error) {
+	return workspaceIDs, labels, nil
+}
+
+// Validate always returns a nil error.
+func (a *ModelAuthZBasic) Validate(ctx context.Context) error {
+	return nil
+}
+
+// CanAccessModelWorkspace always returns true and a nil error.
+func (a *ModelAuthZBasic) CanAccessModelWorkspace(ctx context.Context, curUser model.User,
+	workspaceID int32,
+) (bool, error) {
+	return true, nil
+}
+
 // CanGetModel always returns true and a nil error.
 func (a *ModelAuthZBasic) CanGetModel(ctx context.Context, curUser model.User,
 	m *modelv1.Model, workspaceID int32,
@@ -27,6 +46,24 @@ func (a *ModelAuthZBasic) CanGetModel(ctx context.Context, curUser model.User,
 	return nil
 }
 
+// ExplainGetModel always allows.
+func (a *ModelAuthZBasic) ExplainGetModel(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) (bool, string, error) {
+	return true, "OSS basic auth allows all", nil
+}
+
+// CanGetModelsByIDs allows every model and returns a nil error.
+func (a *ModelAuthZBasic) CanGetModelsByIDs(ctx context.Context, curUser model.User,
+	modelIDs []int32, workspaceID int32,
+) (map[int32]bool, error) {
+	allowed := make(map[int32]bool, len(modelIDs))
+	for _, id := range modelIDs {
+		allowed[id] = true
+	}
+	return allowed, nil
+}
+
 // CanEditModel always returns true and a nil error.
 func (a *ModelAuthZBasic) CanEditModel(ctx context.Context, curUser model.User,
 	m *modelv1.Model, workspaceID int32,
@@ -34,20 +71,70 @@ func (a *ModelAuthZBasic) CanEditModel(ctx context.Context, curUser model.User,
 	return nil
 }
 
+// CanManageModelAccess always returns a nil error. Access grants have no effect under basic
+// authz, where everyone may already get and edit every model.
+func (a *ModelAuthZBasic) CanManageModelAccess(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	return nil
+}
+
+// CanEditModelWebhooks always returns a nil error.
+func (a *ModelAuthZBasic) CanEditModelWebhooks(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	return nil
+}
+
+// CanEditModelMetadata always returns a nil error.
+func (a *ModelAuthZBasic) CanEditModelMetadata(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	return nil
+}
+
+// CanEditModelTags always returns a nil error.
+func (a *ModelAuthZBasic) CanEditModelTags(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	return nil
+}
+
+// CanArchiveModel always returns a nil error.
+func (a *ModelAuthZBasic) CanArchiveModel(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	return nil
+}
+
+// CanUnarchiveModel always returns a nil error.
+func (a *ModelAuthZBasic) CanUnarchiveModel(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	return nil
+}
+
 // CanCreateModel always returns true and a nil error.
-func (a *ModelAuthZBasic) CanCreateModel(ctx context.Context,
+func (a *ModelAuthZBasic) CanCreateModel(ctx context.Context, idb bun.IDB,
 	curUser model.User, workspaceID int32,
 ) error {
 	return nil
 }
 
+// GetModelCreationQuota always reports creation as allowed with no limit.
+func (a *ModelAuthZBasic) GetModelCreationQuota(ctx context.Context, curUser model.User,
+	workspaceID int32,
+) (*model.ModelCreationQuota, error) {
+	return &model.ModelCreationQuota{Allowed: true}, nil
+}
+
 // CanDeleteModel returns an error if the model
 // is not owned by the current user and the current user is not an admin.
 func (a *ModelAuthZBasic) CanDeleteModel(ctx context.Context, curUser model.User,
 	m *modelv1.Model, workspaceID int32,
 ) error {
 	// TODO: Modify model UserID to use UserID
-	curUserIsOwner := m.UserId == int32(curUser.ID)
+	curUserIsOwner := m.OwnerId == int32(curUser.ID)
 	if !curUser.Admin && !curUserIsOwner {
 		return authz.PermissionDeniedError{}.WithPrefix(
 			"non-admin users may not delete other users' models",
@@ -56,13 +143,79 @@ func (a *ModelAuthZBasic) CanDeleteModel(ctx context.Context, curUser model.User
 	return nil
 }
 
+// CanRestoreModel always returns a nil error.
+func (a *ModelAuthZBasic) CanRestoreModel(ctx context.Context, idb bun.IDB,
+	curUser model.User, m *modelv1.Model, workspaceID int32,
+) error {
+	return nil
+}
+
+// CanTransferModelOwnership returns an error if a non-admin user is not the model's owner.
+// Otherwise, it returns nil. Since owners may delete their models, letting anyone transfer a
+// model to themselves would let anyone delete it.
+func (a *ModelAuthZBasic) CanTransferModelOwnership(ctx context.Context, curUser model.User,
+	m *modelv1.Model, newOwnerID int32,
+) error {
+	curUserIsOwner := m.OwnerId == int32(curUser.ID)
+	if !curUser.Admin && !curUserIsOwner {
+		return authz.PermissionDeniedError{}.WithPrefix(
+			"non-admin users may not transfer other users' models",
+		)
+	}
+	return nil
+}
+
+// CanGetModelVersions always returns a nil error.
+func (a *ModelAuthZBasic) CanGetModelVersions(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	return nil
+}
+
+// CanGetModelVersion always returns a nil error.
+func (a *ModelAuthZBasic) CanGetModelVersion(ctx context.Context, curUser model.User,
+	m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32,
+) error {
+	return nil
+}
+
+// CanDownloadModelVersionCheckpoint always returns true and a nil error.
+func (a *ModelAuthZBasic) CanDownloadModelVersionCheckpoint(ctx context.Context,
+	curUser model.User, modelVersion *modelv1.ModelVersion, workspaceID int32,
+	checkpointWorkspaceID *int32,
+) (bool, error) {
+	return true, nil
+}
+
+// CanCreateModelVersion always returns a nil error.
+func (a *ModelAuthZBasic) CanCreateModelVersion(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	return nil
+}
+
+// CanCreateModelVersionFromCheckpoint always returns a nil error.
+func (a *ModelAuthZBasic) CanCreateModelVersionFromCheckpoint(ctx context.Context,
+	curUser model.User, m *modelv1.Model, workspaceID int32, checkpointUUID string,
+	checkpointWorkspaceID *int32,
+) error {
+	return nil
+}
+
+// CanEditModelVersion always returns a nil error.
+func (a *ModelAuthZBasic) CanEditModelVersion(ctx context.Context, curUser model.User,
+	m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32,
+) error {
+	return nil
+}
+
 // CanDeleteModelVersion returns an error if the model/model version
 // is not owned by the current user and the current user is not an admin.
 func (a *ModelAuthZBasic) CanDeleteModelVersion(ctx context.Context, curUser model.User,
-	modelVersion *modelv1.ModelVersion, workspaceID int32,
+	m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32,
 ) error {
 	curUserIsOwner := modelVersion.UserId == int32(curUser.ID) ||
-		modelVersion.Model.UserId == int32(curUser.ID)
+		m.OwnerId == int32(curUser.ID)
 	if !curUser.Admin && !curUserIsOwner {
 		return authz.PermissionDeniedError{}.WithPrefix(
 			"non-admin users may not delete other users' model versions",
@@ -71,9 +224,17 @@ func (a *ModelAuthZBasic) CanDeleteModelVersion(ctx context.Context, curUser mod
 	return nil
 }
 
+// CanForceDeleteModelVersion always returns a nil error.
+func (a *ModelAuthZBasic) CanForceDeleteModelVersion(ctx context.Context, curUser model.User,
+	m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32,
+) error {
+	return nil
+}
+
 // CanMoveModel always returns true and a nil error.
 func (a *ModelAuthZBasic) CanMoveModel(
 	ctx context.Context,
+	idb bun.IDB,
 	curUser model.User,
 	modelRegister *modelv1.Model,
 	fromWorkspaceID int32,
@@ -82,6 +243,13 @@ func (a *ModelAuthZBasic) CanMoveModel(
 	return nil
 }
 
+// MaskModelFields returns the model unchanged.
+func (a *ModelAuthZBasic) MaskModelFields(ctx context.Context, curUser model.User,
+	m *modelv1.Model,
+) *modelv1.Model {
+	return m
+}
+
 // FilterReadableModelsQuery returns the query unmodified and a nil error.
 func (a *ModelAuthZBasic) FilterReadableModelsQuery(
 	ctx context.Context, curUser model.User, query *bun.SelectQuery,
@@ -89,6 +257,20 @@ func (a *ModelAuthZBasic) FilterReadableModelsQuery(
 	return query, nil
 }
 
+// CountModels counts the models of the query in the workspace.
+func (a *ModelAuthZBasic) CountModels(
+	ctx context.Context, curUser model.User, workspaceID int32, query *bun.SelectQuery,
+) (int, error) {
+	return query.Where("m.workspace_id = ?", workspaceID).Count(ctx)
+}
+
+// FilterModelVersionsQuery returns the query unmodified and a nil error.
+func (a *ModelAuthZBasic) FilterModelVersionsQuery(
+	ctx context.Context, curUser model.User, m *modelv1.Model, query *bun.SelectQuery,
+) (*bun.SelectQuery, error) {
+	return query, nil
+}
+
 func init() {
 	AuthZProvider.Register("basic", &ModelAuthZBasic{})
 }
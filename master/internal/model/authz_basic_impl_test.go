@@ -0,0 +1,34 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/authz"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+func TestModelAuthZBasicOwnership(t *testing.T) {
+	ctx := context.Background()
+	basic := &ModelAuthZBasic{}
+	owner := model.User{ID: 1}
+	admin := model.User{ID: 2, Admin: true}
+	other := model.User{ID: 3}
+	m := &modelv1.Model{Id: 7, UserId: int32(owner.ID), OwnerId: int32(owner.ID)}
+	mv := &modelv1.ModelVersion{UserId: int32(owner.ID)}
+
+	for _, u := range []model.User{owner, admin} {
+		require.NoError(t, basic.CanTransferModelOwnership(ctx, u, m, int32(other.ID)))
+		require.NoError(t, basic.CanDeleteModel(ctx, u, m, 1))
+		require.NoError(t, basic.CanDeleteModelVersion(ctx, u, m, mv, 1))
+	}
+
+	// Otherwise other could take the model over and then delete it.
+	require.True(t, authz.IsPermissionDenied(
+		basic.CanTransferModelOwnership(ctx, other, m, int32(other.ID))))
+	require.True(t, authz.IsPermissionDenied(basic.CanDeleteModel(ctx, other, m, 1)))
+	require.True(t, authz.IsPermissionDenied(basic.CanDeleteModelVersion(ctx, other, m, mv, 1)))
+}
@@ -0,0 +1,200 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/determined-ai/determined/master/internal/authz"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// DefaultCanGetModelsCacheTTL is how long CanGetModels decisions are cached by default.
+const DefaultCanGetModelsCacheTTL = 5 * time.Second
+
+// CanGetModelsCacheTTLEnv names the environment variable that enables caching of CanGetModels
+// decisions for the RBAC implementation. Its value is a duration; unset or zero disables it.
+const CanGetModelsCacheTTLEnv = "DET_MODEL_AUTHZ_CACHE_TTL"
+
+var canGetModelsCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Subsystem: "det",
+	Name:      "model_authz_can_get_models_cache_requests_total",
+	Help:      "number of CanGetModels calls served from (hit) or missing (miss) the authz cache",
+}, []string{"result"})
+
+type canGetModelsKey struct {
+	userID       model.UserID
+	workspaceIDs string
+	// byLabel keeps CanGetModels and CanGetModelsByLabel decisions apart.
+	byLabel bool
+	labels  string
+}
+
+type canGetModelsEntry struct {
+	workspaceIDs []int32
+	labels       []string
+	err          error
+	expiresAt    time.Time
+}
+
+// ModelAuthZCache wraps another ModelAuthZ and memoizes its CanGetModels and
+// CanGetModelsByLabel decisions per user and requested workspaces for a short TTL. All other
+// methods call straight through. Role assignment changes drop every entry, but other changes
+// such as group membership are only picked up once the TTL expires.
+type ModelAuthZCache struct {
+	ModelAuthZ
+
+	ttl       time.Duration
+	now       func() time.Time
+	mu        sync.Mutex
+	entries   map[canGetModelsKey]canGetModelsEntry
+	lastSweep time.Time
+}
+
+// NewModelAuthZCache returns a cache around wrapped. A non-positive ttl uses
+// DefaultCanGetModelsCacheTTL.
+func NewModelAuthZCache(wrapped ModelAuthZ, ttl time.Duration) *ModelAuthZCache {
+	if ttl <= 0 {
+		ttl = DefaultCanGetModelsCacheTTL
+	}
+	return &ModelAuthZCache{
+		ModelAuthZ: wrapped,
+		ttl:        ttl,
+		now:        time.Now,
+		entries:    make(map[canGetModelsKey]canGetModelsEntry),
+		lastSweep:  time.Now(),
+	}
+}
+
+func newCanGetModelsKey(curUser model.User, workspaceIDs []int32) canGetModelsKey {
+	// A nil list means every workspace and must not share an entry with an empty list.
+	if workspaceIDs == nil {
+		return canGetModelsKey{userID: curUser.ID, workspaceIDs: "*"}
+	}
+	sorted := append([]int32{}, workspaceIDs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return canGetModelsKey{userID: curUser.ID, workspaceIDs: fmt.Sprint(sorted)}
+}
+
+// cached returns a fresh entry for key, or calls compute and caches its result. Server errors
+// are never cached.
+func (c *ModelAuthZCache) cached(
+	key canGetModelsKey, compute func() ([]int32, []string, error),
+) ([]int32, []string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+	if ok {
+		canGetModelsCacheRequests.WithLabelValues("hit").Inc()
+		return copySlice(entry.workspaceIDs), copySlice(entry.labels), entry.err
+	}
+	canGetModelsCacheRequests.WithLabelValues("miss").Inc()
+
+	workspaceIDs, labels, err := compute()
+	if err == nil || authz.IsPermissionDenied(err) {
+		now := c.now()
+		c.mu.Lock()
+		if now.Sub(c.lastSweep) >= c.ttl {
+			c.sweep(now)
+		}
+		c.entries[key] = canGetModelsEntry{
+			workspaceIDs: copySlice(workspaceIDs),
+			labels:       copySlice(labels),
+			err:          err,
+			expiresAt:    now.Add(c.ttl),
+		}
+		c.mu.Unlock()
+	}
+	return workspaceIDs, labels, err
+}
+
+// sweep drops the expired entries of keys that are no longer asked for. c.mu must be held.
+func (c *ModelAuthZCache) sweep(now time.Time) {
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	c.lastSweep = now
+}
+
+// CanGetModels returns a cached decision if one is fresh, otherwise it calls the wrapped
+// implementation.
+func (c *ModelAuthZCache) CanGetModels(ctx context.Context,
+	curUser model.User, workspaceIDs []int32,
+) (workspaceIDsWithPermsFilter []int32, serverError error) {
+	workspaceIDsWithPermsFilter, _, serverError = c.cached(
+		newCanGetModelsKey(curUser, workspaceIDs),
+		func() ([]int32, []string, error) {
+			ids, err := c.ModelAuthZ.CanGetModels(ctx, curUser, workspaceIDs)
+			return ids, nil, err
+		})
+	return workspaceIDsWithPermsFilter, serverError
+}
+
+// CanGetModelsByLabel returns a cached decision if one is fresh, otherwise it calls the
+// wrapped implementation.
+func (c *ModelAuthZCache) CanGetModelsByLabel(ctx context.Context,
+	curUser model.User, workspaceIDs []int32, labels []string,
+) (workspaceIDsWithPermsFilter []int32, labelsFilter []string, serverError error) {
+	key := newCanGetModelsKey(curUser, workspaceIDs)
+	key.byLabel = true
+	key.labels = fmt.Sprintf("%q", labels)
+	return c.cached(key, func() ([]int32, []string, error) {
+		return c.ModelAuthZ.CanGetModelsByLabel(ctx, curUser, workspaceIDs, labels)
+	})
+}
+
+// InvalidateCanGetModels drops every cached CanGetModels decision.
+func (c *ModelAuthZCache) InvalidateCanGetModels() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[canGetModelsKey]canGetModelsEntry)
+}
+
+func copySlice[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return append([]T{}, s...)
+}
+
+// InvalidateCanGetModelsCache drops cached CanGetModels decisions of every registered ModelAuthZ
+// that caches them, since workspaces may select types other than the default. It should be
+// called whenever models are created, moved or archived, or roles are assigned or removed.
+func InvalidateCanGetModelsCache() {
+	for _, name := range AuthZProvider.Types() {
+		impl, _ := AuthZProvider.GetType(name)
+		if c, ok := impl.(interface{ InvalidateCanGetModels() }); ok {
+			c.InvalidateCanGetModels()
+		}
+	}
+}
+
+// withCanGetModelsCache wraps impl in a ModelAuthZCache if CanGetModelsCacheTTLEnv is set.
+func withCanGetModelsCache(impl ModelAuthZ) ModelAuthZ {
+	value := os.Getenv(CanGetModelsCacheTTLEnv)
+	if value == "" {
+		return impl
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		log.WithError(err).Warnf("invalid %s %q, using default of %s",
+			CanGetModelsCacheTTLEnv, value, DefaultCanGetModelsCacheTTL)
+		ttl = DefaultCanGetModelsCacheTTL
+	} else if ttl == 0 {
+		return impl
+	}
+	return NewModelAuthZCache(impl, ttl)
+}
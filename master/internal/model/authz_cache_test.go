@@ -0,0 +1,119 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/authz"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+type countingModelAuthZ struct {
+	ModelAuthZBasic
+	calls int
+	err   error
+}
+
+func (a *countingModelAuthZ) CanGetModels(ctx context.Context,
+	curUser model.User, workspaceIDs []int32,
+) ([]int32, error) {
+	a.calls++
+	if a.err != nil {
+		return nil, a.err
+	}
+	return workspaceIDs, nil
+}
+
+func TestModelAuthZCacheCanGetModels(t *testing.T) {
+	ctx := context.Background()
+	wrapped := &countingModelAuthZ{}
+	c := NewModelAuthZCache(wrapped, time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	alice := model.User{ID: 1}
+	bob := model.User{ID: 2}
+
+	ids, err := c.CanGetModels(ctx, alice, []int32{2, 1})
+	require.NoError(t, err)
+	require.Equal(t, []int32{2, 1}, ids)
+	_, err = c.CanGetModels(ctx, alice, []int32{1, 2})
+	require.NoError(t, err)
+	require.Equal(t, 1, wrapped.calls, "same user and workspaces should hit")
+
+	_, err = c.CanGetModels(ctx, bob, []int32{1, 2})
+	require.NoError(t, err)
+	require.Equal(t, 2, wrapped.calls, "decisions must not be shared across users")
+
+	_, err = c.CanGetModels(ctx, alice, nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, wrapped.calls, "all workspaces is a different key")
+
+	now = now.Add(2 * time.Minute)
+	_, err = c.CanGetModels(ctx, alice, []int32{1, 2})
+	require.NoError(t, err)
+	require.Equal(t, 4, wrapped.calls, "expired entries should miss")
+
+	c.InvalidateCanGetModels()
+	_, err = c.CanGetModels(ctx, alice, []int32{1, 2})
+	require.NoError(t, err)
+	require.Equal(t, 5, wrapped.calls, "invalidated entries should miss")
+}
+
+func TestModelAuthZCacheSweepsExpiredEntries(t *testing.T) {
+	ctx := context.Background()
+	c := NewModelAuthZCache(&countingModelAuthZ{}, time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	for id := model.UserID(1); id <= 3; id++ {
+		_, err := c.CanGetModels(ctx, model.User{ID: id}, nil)
+		require.NoError(t, err)
+	}
+	require.Len(t, c.entries, 3)
+
+	now = now.Add(2 * time.Minute)
+	_, err := c.CanGetModels(ctx, model.User{ID: 4}, nil)
+	require.NoError(t, err)
+	require.Len(t, c.entries, 1, "expired entries of other users should be swept")
+}
+
+func TestModelAuthZCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	wrapped := &countingModelAuthZ{err: errors.New("db down")}
+	c := NewModelAuthZCache(wrapped, 0)
+	require.Equal(t, DefaultCanGetModelsCacheTTL, c.ttl)
+	curUser := model.User{ID: 1}
+
+	for i := 0; i < 2; i++ {
+		_, err := c.CanGetModels(ctx, curUser, nil)
+		require.ErrorIs(t, err, wrapped.err)
+	}
+	require.Equal(t, 2, wrapped.calls, "server errors must not be cached")
+
+	wrapped.err = authz.PermissionDeniedError{}
+	for i := 0; i < 2; i++ {
+		_, err := c.CanGetModels(ctx, curUser, nil)
+		require.True(t, authz.IsPermissionDenied(err))
+	}
+	require.Equal(t, 3, wrapped.calls, "denials should be cached")
+}
+
+func TestInvalidateCanGetModelsCacheAllTypes(t *testing.T) {
+	ctx := context.Background()
+	wrapped := &countingModelAuthZ{}
+	c := NewModelAuthZCache(wrapped, time.Minute)
+	AuthZProvider.Register("test-invalidate-cache", c)
+
+	_, err := c.CanGetModels(ctx, model.User{ID: 1}, nil)
+	require.NoError(t, err)
+	// The cache isn't the selected type, but a workspace may still resolve to it.
+	InvalidateCanGetModelsCache()
+	_, err = c.CanGetModels(ctx, model.User{ID: 1}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, wrapped.calls, "every registered cache should be invalidated")
+}
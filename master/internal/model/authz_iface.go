@@ -12,43 +12,159 @@ import (
 
 // ModelAuthZ describes authz methods for experiments.
 type ModelAuthZ interface {
+	// Called once when the master starts, before any request is authorized. Returns an error
+	// if the implementation cannot make decisions, such as when a service it depends on is
+	// unreachable.
+	Validate(ctx context.Context) error
+
 	// GET /api/v1/models
 	CanGetModels(ctx context.Context, curUser model.User, workspaceIDs []int32,
 	) (workspaceIDsWithPermsFilter []int32, serverError error)
+	// GET /api/v1/models with a label filter
+	CanGetModelsByLabel(ctx context.Context, curUser model.User, workspaceIDs []int32,
+		labels []string,
+	) (workspaceIDsWithPermsFilter []int32, labelsFilter []string, serverError error)
+	// GET /api/v1/models/{model_name}
+	// Checked before the model is loaded, so that users who cannot see models in the
+	// workspace are turned away without fetching it.
+	CanAccessModelWorkspace(ctx context.Context, curUser model.User,
+		workspaceID int32,
+	) (bool, error)
 	// GET /api/v1/checkpoints/{checkpoint_uuid}
 	// GET /api/v1/models/{model_name}
-	// GET /api/v1/models/{model_name}/versions/{model_version_num}
-	// GET /api/v1/models/{model_name}/versions
 	CanGetModel(ctx context.Context, curUser model.User,
 		m *modelv1.Model, workspaceID int32,
 	) error
+	// POST /api/v1/models/check-authz
+	// Like CanGetModel, but also says why the user is allowed or denied, for showing to users.
+	// It may do more work than CanGetModel, so it is only called on demand, never per model.
+	ExplainGetModel(ctx context.Context, curUser model.User,
+		m *modelv1.Model, workspaceID int32,
+	) (allowed bool, reason string, serverError error)
+	// POST /api/v1/models/by-ids
+	// Returns whether the user may view each of the models. Models that do not exist, or are
+	// not in workspaceID when it is nonzero, are denied.
+	CanGetModelsByIDs(ctx context.Context, curUser model.User,
+		modelIDs []int32, workspaceID int32,
+	) (allowed map[int32]bool, serverError error)
 	// PATCH /api/v1/models/{model_name}
-	// PATCH /api/v1/models/{model_name}/versions/{model_version_num}
-	// POST /api/v1/models/{model_name}/versions
+	CanEditModel(ctx context.Context, curUser model.User,
+		m *modelv1.Model, workspaceID int32,
+	) error
+	// GET /api/v1/models/{model_name}/access-grants
+	// PUT /api/v1/models/{model_name}/access-grants/{user_id}
+	// DELETE /api/v1/models/{model_name}/access-grants/{user_id}
+	CanManageModelAccess(ctx context.Context, curUser model.User,
+		m *modelv1.Model, workspaceID int32,
+	) error
+	// GET /api/v1/models/{model_name}/webhooks
+	// POST /api/v1/models/{model_name}/webhooks
+	// PATCH /api/v1/models/{model_name}/webhooks/{webhook_id}
+	// DELETE /api/v1/models/{model_name}/webhooks/{webhook_id}
+	// GET /api/v1/models/{model_name}/webhooks/{webhook_id}/deliveries
+	CanEditModelWebhooks(ctx context.Context, curUser model.User,
+		m *modelv1.Model, workspaceID int32,
+	) error
+	// PATCH /api/v1/models/{model_name} when the metadata changes
+	CanEditModelMetadata(ctx context.Context, curUser model.User,
+		m *modelv1.Model, workspaceID int32,
+	) error
+	// PUT /api/v1/models/{model_name}/tags/{key}
+	// DELETE /api/v1/models/{model_name}/tags/{key}
+	CanEditModelTags(ctx context.Context, curUser model.User,
+		m *modelv1.Model, workspaceID int32,
+	) error
 	// POST /api/v1/models/{model_name}/archive
+	CanArchiveModel(ctx context.Context, curUser model.User,
+		m *modelv1.Model, workspaceID int32,
+	) error
 	// POST /api/v1/models/{model_name}/unarchive
-	CanEditModel(ctx context.Context, curUser model.User,
+	CanUnarchiveModel(ctx context.Context, curUser model.User,
 		m *modelv1.Model, workspaceID int32,
 	) error
 	// POST /api/v1/models
-	CanCreateModel(ctx context.Context,
+	// idb is the transaction the model is inserted in, so that checks against existing models
+	// hold until it commits. It may be nil when there is no transaction.
+	CanCreateModel(ctx context.Context, idb bun.IDB,
 		curUser model.User, workspaceID int32,
 	) error
+	// GET /api/v1/workspaces/{workspace_id}/models/creation-quota
+	// Reports what CanCreateModel would currently decide for the user in the workspace, along
+	// with the workspace's model limit. It is read-only and locks nothing.
+	GetModelCreationQuota(ctx context.Context, curUser model.User, workspaceID int32,
+	) (*model.ModelCreationQuota, error)
 	// DELETE /api/v1/models/{modelName}
 	CanDeleteModel(ctx context.Context, curUser model.User,
 		m *modelv1.Model, workspaceID int32,
 	) error
+	// POST /api/v1/models/{model_name}/restore
+	// idb is the transaction the model is restored in, as for CanCreateModel.
+	CanRestoreModel(ctx context.Context, idb bun.IDB, curUser model.User,
+		m *modelv1.Model, workspaceID int32,
+	) error
+	// POST /api/v1/models/{model_name}/transfer-ownership
+	CanTransferModelOwnership(ctx context.Context, curUser model.User,
+		m *modelv1.Model, newOwnerID int32,
+	) error
+	// GET /api/v1/models/{model_name}/versions
+	CanGetModelVersions(ctx context.Context, curUser model.User,
+		m *modelv1.Model, workspaceID int32) error
+	// GET /api/v1/models/{model_name}/versions/{model_version_num}
+	// GET /api/v1/models/{model_name}/versions/{model_version_num}/metrics
+	CanGetModelVersion(ctx context.Context, curUser model.User,
+		m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32) error
+	// GET /api/v1/models/{model_name}/versions/{model_version_num}
+	// Returns whether the checkpoint's storage details may be shown with the version.
+	// checkpointWorkspaceID is the workspace of the experiment that produced the checkpoint,
+	// or nil if the checkpoint was not produced by an experiment.
+	CanDownloadModelVersionCheckpoint(ctx context.Context, curUser model.User,
+		modelVersion *modelv1.ModelVersion, workspaceID int32, checkpointWorkspaceID *int32,
+	) (bool, error)
+	// POST /api/v1/models/{model_name}/versions
+	CanCreateModelVersion(ctx context.Context, curUser model.User,
+		m *modelv1.Model, workspaceID int32) error
+	// POST /api/v1/models/{model_name}/versions
+	// checkpointWorkspaceID is the workspace of the experiment that produced the checkpoint,
+	// or nil if the checkpoint was not produced by an experiment.
+	CanCreateModelVersionFromCheckpoint(ctx context.Context, curUser model.User,
+		m *modelv1.Model, workspaceID int32, checkpointUUID string, checkpointWorkspaceID *int32,
+	) error
+	// PATCH /api/v1/models/{model_name}/versions/{model_version_num}
+	CanEditModelVersion(ctx context.Context, curUser model.User,
+		m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32) error
 	// DELETE /api/v1/models/{modelName}/versions/{modelVersionNum}
 	CanDeleteModelVersion(ctx context.Context, curUser model.User,
-		modelVersion *modelv1.ModelVersion, workspaceID int32) error
+		m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32) error
+	// DELETE /api/v1/models/{modelName}/versions/{modelVersionNum} with force
+	// Checked in addition to CanDeleteModelVersion when the version is protected from deletion.
+	CanForceDeleteModelVersion(ctx context.Context, curUser model.User,
+		m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32) error
 	// POST /api/v1/models/{model_name}/move
-	CanMoveModel(ctx context.Context, curUser model.User, model *modelv1.Model,
+	// idb is the transaction the model is moved in, as for CanCreateModel.
+	CanMoveModel(ctx context.Context, idb bun.IDB, curUser model.User, model *modelv1.Model,
 		fromWorkspaceID int32, toWorkspaceID int32) error
 
+	// GET /api/v1/models, GET /api/v1/models/{model_name} and the other endpoints returning models
+	// Returns m as the user may see it, once they are authorized to get it. m itself is never
+	// modified; a copy is returned if anything is masked.
+	MaskModelFields(ctx context.Context, curUser model.User, m *modelv1.Model) *modelv1.Model
+
 	// GET /api/v1/models with filter to allow reading
 	FilterReadableModelsQuery(
 		ctx context.Context, curUser model.User, query *bun.SelectQuery,
 	) (*bun.SelectQuery, error)
+	// GET /api/v1/workspaces/{workspace_id}/models/count
+	// The query selects the models matching the request's filters from models aliased as m.
+	// Returns how many of them are in the workspace and readable by the user, counted the same
+	// way FilterReadableModelsQuery filters them.
+	CountModels(
+		ctx context.Context, curUser model.User, workspaceID int32, query *bun.SelectQuery,
+	) (int, error)
+	// GET /api/v1/models/{model_name}/versions with filter to allow reading.
+	// The query selects from model_versions aliased as mv.
+	FilterModelVersionsQuery(
+		ctx context.Context, curUser model.User, m *modelv1.Model, query *bun.SelectQuery,
+	) (*bun.SelectQuery, error)
 }
 
 // AuthZProvider is the authz registry for models.
@@ -5,6 +5,7 @@ import (
 
 	"github.com/uptrace/bun"
 
+	"github.com/determined-ai/determined/master/internal/db"
 	"github.com/determined-ai/determined/master/pkg/model"
 	"github.com/determined-ai/determined/proto/pkg/modelv1"
 )
@@ -20,6 +21,29 @@ func (a *ModelAuthZPermissive) CanGetModels(ctx context.Context,
 	return (&ModelAuthZBasic{}).CanGetModels(ctx, curUser, workspaceIDs)
 }
 
+// CanGetModelsByLabel calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CanGetModelsByLabel(ctx context.Context,
+	curUser model.User, workspaceIDs []int32, labels []string,
+) (workspaceIDsWithPermsFilter []int32, labelsFilter []string, serverError error) {
+	_, _, _ = (&ModelAuthZRBAC{}).CanGetModelsByLabel( //nolint:dogsled
+		ctx, curUser, workspaceIDs, labels)
+	return (&ModelAuthZBasic{}).CanGetModelsByLabel(ctx, curUser, workspaceIDs, labels)
+}
+
+// Validate calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) Validate(ctx context.Context) error {
+	_ = (&ModelAuthZRBAC{}).Validate(ctx)
+	return (&ModelAuthZBasic{}).Validate(ctx)
+}
+
+// CanAccessModelWorkspace calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CanAccessModelWorkspace(ctx context.Context, curUser model.User,
+	workspaceID int32,
+) (bool, error) {
+	_, _ = (&ModelAuthZRBAC{}).CanAccessModelWorkspace(ctx, curUser, workspaceID)
+	return (&ModelAuthZBasic{}).CanAccessModelWorkspace(ctx, curUser, workspaceID)
+}
+
 // CanGetModel calls RBAC authz but enforces basic authz..
 func (a *ModelAuthZPermissive) CanGetModel(ctx context.Context, curUser model.User,
 	m *modelv1.Model, workspaceID int32,
@@ -28,6 +52,22 @@ func (a *ModelAuthZPermissive) CanGetModel(ctx context.Context, curUser model.Us
 	return (&ModelAuthZBasic{}).CanGetModel(ctx, curUser, m, workspaceID)
 }
 
+// ExplainGetModel calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) ExplainGetModel(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) (bool, string, error) {
+	_, _, _ = (&ModelAuthZRBAC{}).ExplainGetModel(ctx, curUser, m, workspaceID)
+	return (&ModelAuthZBasic{}).ExplainGetModel(ctx, curUser, m, workspaceID)
+}
+
+// CanGetModelsByIDs calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CanGetModelsByIDs(ctx context.Context, curUser model.User,
+	modelIDs []int32, workspaceID int32,
+) (map[int32]bool, error) {
+	_, _ = (&ModelAuthZRBAC{}).CanGetModelsByIDs(ctx, curUser, modelIDs, workspaceID)
+	return (&ModelAuthZBasic{}).CanGetModelsByIDs(ctx, curUser, modelIDs, workspaceID)
+}
+
 // CanEditModel calls RBAC authz but enforces basic authz..
 func (a *ModelAuthZPermissive) CanEditModel(ctx context.Context, curUser model.User,
 	m *modelv1.Model, workspaceID int32,
@@ -36,12 +76,68 @@ func (a *ModelAuthZPermissive) CanEditModel(ctx context.Context, curUser model.U
 	return (&ModelAuthZBasic{}).CanEditModel(ctx, curUser, m, workspaceID)
 }
 
+// CanManageModelAccess calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CanManageModelAccess(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	_ = (&ModelAuthZRBAC{}).CanManageModelAccess(ctx, curUser, m, workspaceID)
+	return (&ModelAuthZBasic{}).CanManageModelAccess(ctx, curUser, m, workspaceID)
+}
+
+// CanEditModelWebhooks calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CanEditModelWebhooks(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	_ = (&ModelAuthZRBAC{}).CanEditModelWebhooks(ctx, curUser, m, workspaceID)
+	return (&ModelAuthZBasic{}).CanEditModelWebhooks(ctx, curUser, m, workspaceID)
+}
+
+// CanEditModelMetadata calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CanEditModelMetadata(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	_ = (&ModelAuthZRBAC{}).CanEditModelMetadata(ctx, curUser, m, workspaceID)
+	return (&ModelAuthZBasic{}).CanEditModelMetadata(ctx, curUser, m, workspaceID)
+}
+
+// CanEditModelTags calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CanEditModelTags(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	_ = (&ModelAuthZRBAC{}).CanEditModelTags(ctx, curUser, m, workspaceID)
+	return (&ModelAuthZBasic{}).CanEditModelTags(ctx, curUser, m, workspaceID)
+}
+
+// CanArchiveModel calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CanArchiveModel(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	_ = (&ModelAuthZRBAC{}).CanArchiveModel(ctx, curUser, m, workspaceID)
+	return (&ModelAuthZBasic{}).CanArchiveModel(ctx, curUser, m, workspaceID)
+}
+
+// CanUnarchiveModel calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CanUnarchiveModel(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	_ = (&ModelAuthZRBAC{}).CanUnarchiveModel(ctx, curUser, m, workspaceID)
+	return (&ModelAuthZBasic{}).CanUnarchiveModel(ctx, curUser, m, workspaceID)
+}
+
 // CanCreateModel calls RBAC authz but enforces basic authz..
-func (a *ModelAuthZPermissive) CanCreateModel(ctx context.Context,
+func (a *ModelAuthZPermissive) CanCreateModel(ctx context.Context, idb bun.IDB,
 	curUser model.User, workspaceID int32,
 ) error {
-	_ = (&ModelAuthZRBAC{}).CanCreateModel(ctx, curUser, workspaceID)
-	return (&ModelAuthZBasic{}).CanCreateModel(ctx, curUser, workspaceID)
+	_ = (&ModelAuthZRBAC{}).CanCreateModel(ctx, idb, curUser, workspaceID)
+	return (&ModelAuthZBasic{}).CanCreateModel(ctx, idb, curUser, workspaceID)
+}
+
+// GetModelCreationQuota calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) GetModelCreationQuota(ctx context.Context, curUser model.User,
+	workspaceID int32,
+) (*model.ModelCreationQuota, error) {
+	_, _ = (&ModelAuthZRBAC{}).GetModelCreationQuota(ctx, curUser, workspaceID)
+	return (&ModelAuthZBasic{}).GetModelCreationQuota(ctx, curUser, workspaceID)
 }
 
 // CanDeleteModel calls RBAC authz but enforces basic authz.
@@ -52,20 +148,114 @@ func (a *ModelAuthZPermissive) CanDeleteModel(ctx context.Context, curUser model
 	return (&ModelAuthZBasic{}).CanDeleteModel(ctx, curUser, m, workspaceID)
 }
 
+// CanRestoreModel calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CanRestoreModel(ctx context.Context, idb bun.IDB,
+	curUser model.User, m *modelv1.Model, workspaceID int32,
+) error {
+	_ = (&ModelAuthZRBAC{}).CanRestoreModel(ctx, idb, curUser, m, workspaceID)
+	return (&ModelAuthZBasic{}).CanRestoreModel(ctx, idb, curUser, m, workspaceID)
+}
+
+// CanTransferModelOwnership calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CanTransferModelOwnership(ctx context.Context,
+	curUser model.User, m *modelv1.Model, newOwnerID int32,
+) error {
+	_ = (&ModelAuthZRBAC{}).CanTransferModelOwnership(ctx, curUser, m, newOwnerID)
+	return (&ModelAuthZBasic{}).CanTransferModelOwnership(ctx, curUser, m, newOwnerID)
+}
+
+// CanGetModelVersions calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CanGetModelVersions(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	_ = (&ModelAuthZRBAC{}).CanGetModelVersions(ctx, curUser, m, workspaceID)
+	return (&ModelAuthZBasic{}).CanGetModelVersions(ctx, curUser, m, workspaceID)
+}
+
+// CanGetModelVersion calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CanGetModelVersion(ctx context.Context, curUser model.User,
+	m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32,
+) error {
+	_ = (&ModelAuthZRBAC{}).CanGetModelVersion(ctx, curUser, m, modelVersion,
+		workspaceID)
+	return (&ModelAuthZBasic{}).CanGetModelVersion(ctx, curUser, m, modelVersion,
+		workspaceID)
+}
+
+// CanDownloadModelVersionCheckpoint calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CanDownloadModelVersionCheckpoint(ctx context.Context,
+	curUser model.User, modelVersion *modelv1.ModelVersion, workspaceID int32,
+	checkpointWorkspaceID *int32,
+) (bool, error) {
+	_, _ = (&ModelAuthZRBAC{}).CanDownloadModelVersionCheckpoint(ctx, curUser, modelVersion,
+		workspaceID, checkpointWorkspaceID)
+	return (&ModelAuthZBasic{}).CanDownloadModelVersionCheckpoint(ctx, curUser, modelVersion,
+		workspaceID, checkpointWorkspaceID)
+}
+
+// CanCreateModelVersion calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CanCreateModelVersion(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) error {
+	_ = (&ModelAuthZRBAC{}).CanCreateModelVersion(ctx, curUser, m, workspaceID)
+	return (&ModelAuthZBasic{}).CanCreateModelVersion(ctx, curUser, m, workspaceID)
+}
+
+// CanCreateModelVersionFromCheckpoint calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CanCreateModelVersionFromCheckpoint(ctx context.Context,
+	curUser model.User, m *modelv1.Model, workspaceID int32, checkpointUUID string,
+	checkpointWorkspaceID *int32,
+) error {
+	_ = (&ModelAuthZRBAC{}).CanCreateModelVersionFromCheckpoint(ctx, curUser, m, workspaceID,
+		checkpointUUID, checkpointWorkspaceID)
+	return (&ModelAuthZBasic{}).CanCreateModelVersionFromCheckpoint(ctx, curUser, m, workspaceID,
+		checkpointUUID, checkpointWorkspaceID)
+}
+
+// CanEditModelVersion calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CanEditModelVersion(ctx context.Context, curUser model.User,
+	m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32,
+) error {
+	_ = (&ModelAuthZRBAC{}).CanEditModelVersion(ctx, curUser, m, modelVersion,
+		workspaceID)
+	return (&ModelAuthZBasic{}).CanEditModelVersion(ctx, curUser, m, modelVersion,
+		workspaceID)
+}
+
 // CanDeleteModelVersion calls RBAC authz but enforces basic authz.
 func (a *ModelAuthZPermissive) CanDeleteModelVersion(ctx context.Context, curUser model.User,
-	modelVersion *modelv1.ModelVersion, workspaceID int32,
+	m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32,
+) error {
+	_ = (&ModelAuthZRBAC{}).CanDeleteModelVersion(ctx, curUser, m, modelVersion,
+		workspaceID)
+	return (&ModelAuthZBasic{}).CanDeleteModelVersion(ctx, curUser, m, modelVersion,
+		workspaceID)
+}
+
+// CanForceDeleteModelVersion calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CanForceDeleteModelVersion(ctx context.Context, curUser model.User,
+	m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32,
 ) error {
-	_ = (&ModelAuthZRBAC{}).CanDeleteModelVersion(ctx, curUser, modelVersion, workspaceID)
-	return (&ModelAuthZBasic{}).CanDeleteModelVersion(ctx, curUser, modelVersion, workspaceID)
+	_ = (&ModelAuthZRBAC{}).CanForceDeleteModelVersion(ctx, curUser, m, modelVersion,
+		workspaceID)
+	return (&ModelAuthZBasic{}).CanForceDeleteModelVersion(ctx, curUser, m, modelVersion,
+		workspaceID)
 }
 
 // CanMoveModel always returns true.
-func (a *ModelAuthZPermissive) CanMoveModel(ctx context.Context,
+func (a *ModelAuthZPermissive) CanMoveModel(ctx context.Context, idb bun.IDB,
 	curUser model.User, m *modelv1.Model, origin int32, destination int32,
 ) error {
-	_ = (&ModelAuthZRBAC{}).CanMoveModel(ctx, curUser, m, origin, destination)
-	return (&ModelAuthZBasic{}).CanMoveModel(ctx, curUser, m, origin, destination)
+	_ = (&ModelAuthZRBAC{}).CanMoveModel(ctx, idb, curUser, m, origin, destination)
+	return (&ModelAuthZBasic{}).CanMoveModel(ctx, idb, curUser, m, origin, destination)
+}
+
+// MaskModelFields calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) MaskModelFields(ctx context.Context, curUser model.User,
+	m *modelv1.Model,
+) *modelv1.Model {
+	_ = (&ModelAuthZRBAC{}).MaskModelFields(ctx, curUser, m)
+	return (&ModelAuthZBasic{}).MaskModelFields(ctx, curUser, m)
 }
 
 // FilterReadableModelsQuery returns query and a nil error.
@@ -76,6 +266,26 @@ func (a *ModelAuthZPermissive) FilterReadableModelsQuery(
 	return (&ModelAuthZBasic{}).FilterReadableModelsQuery(ctx, curUser, query)
 }
 
+// CountModels calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) CountModels(
+	ctx context.Context, curUser model.User, workspaceID int32, query *bun.SelectQuery,
+) (int, error) {
+	// bun queries are mutable, so RBAC counts a throwaway query to avoid enforcing it.
+	_, _ = (&ModelAuthZRBAC{}).CountModels(ctx, curUser, workspaceID,
+		db.Bun().NewSelect().TableExpr("models AS m"))
+	return (&ModelAuthZBasic{}).CountModels(ctx, curUser, workspaceID, query)
+}
+
+// FilterModelVersionsQuery calls RBAC authz but enforces basic authz.
+func (a *ModelAuthZPermissive) FilterModelVersionsQuery(
+	ctx context.Context, curUser model.User, m *modelv1.Model, query *bun.SelectQuery,
+) (*bun.SelectQuery, error) {
+	// bun queries are mutable, so RBAC filters a throwaway query to avoid enforcing it.
+	_, _ = (&ModelAuthZRBAC{}).FilterModelVersionsQuery(ctx, curUser, m,
+		db.Bun().NewSelect().TableExpr("model_versions AS mv"))
+	return (&ModelAuthZBasic{}).FilterModelVersionsQuery(ctx, curUser, m, query)
+}
+
 func init() {
 	AuthZProvider.Register("permissive", &ModelAuthZPermissive{})
 }
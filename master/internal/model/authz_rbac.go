@@ -2,13 +2,19 @@ package model
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
 
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/determined-ai/determined/master/internal/authz"
+	"github.com/determined-ai/determined/master/internal/config"
 	"github.com/determined-ai/determined/master/internal/db"
 	"github.com/determined-ai/determined/master/internal/rbac"
 	"github.com/determined-ai/determined/master/internal/rbac/audit"
@@ -40,6 +46,9 @@ func addExpInfo(
 // CanGetModels checks if a user has permissions to view models.
 func (a *ModelAuthZRBAC) CanGetModels(ctx context.Context, curUser model.User, workspaceIDs []int32,
 ) (workspaceIDsWithPermsFilter []int32, serverError error) {
+	if err := RequireUser(&curUser); err != nil {
+		return nil, err
+	}
 	fields := audit.ExtractLogFields(ctx)
 	addExpInfo(curUser, fields, fmt.Sprintf("all models in workspaces %v", workspaceIDs),
 		[]rbacv1.PermissionType{rbacv1.PermissionType_PERMISSION_TYPE_VIEW_MODEL_REGISTRY})
@@ -74,7 +83,23 @@ func (a *ModelAuthZRBAC) CanGetModels(ctx context.Context, curUser model.User, w
 		}
 	}
 
-	if workspacesIDsWithPerms == nil {
+	// Models the user was granted access to can be listed without a role in their workspace.
+	var grantWorkspaceIDs []int32
+	needsGrants := workspacesIDsWithPerms == nil
+	for _, givenWID := range workspaceIDs {
+		needsGrants = needsGrants || !workspacesIDsWithPermsSet[givenWID]
+	}
+	if needsGrants {
+		if grantWorkspaceIDs, err = db.GetModelAccessGrantWorkspaceIDs(ctx, curUser.ID); err != nil {
+			return nil, err
+		}
+	}
+	grantWorkspaceIDsSet := make(map[int32]bool, len(grantWorkspaceIDs))
+	for _, id := range grantWorkspaceIDs {
+		grantWorkspaceIDsSet[id] = true
+	}
+
+	if workspacesIDsWithPerms == nil && len(grantWorkspaceIDs) == 0 {
 		// user doesn't have permissions to see models in any workspace.
 		return nil, authz.PermissionDeniedError{
 			RequiredPermissions: []rbacv1.PermissionType{
@@ -84,7 +109,7 @@ func (a *ModelAuthZRBAC) CanGetModels(ctx context.Context, curUser model.User, w
 	}
 
 	for _, givenWID := range workspaceIDs {
-		if _, ok := workspacesIDsWithPermsSet[givenWID]; !ok {
+		if !workspacesIDsWithPermsSet[givenWID] && !grantWorkspaceIDsSet[givenWID] {
 			// user doesn't have permissions to see models in the user given list of workspaces.
 			return nil, authz.PermissionDeniedError{
 				RequiredPermissions: []rbacv1.PermissionType{
@@ -99,13 +124,68 @@ func (a *ModelAuthZRBAC) CanGetModels(ctx context.Context, curUser model.User, w
 		// could be smaller than the workspaces with permissions.
 	}
 
-	return workspacesIDsWithPerms, nil
+	return append(workspacesIDsWithPerms, grantWorkspaceIDs...), nil
+}
+
+// CanGetModelsByLabel checks if a user has permissions to view models in the given workspaces.
+// RBAC does not restrict labels, so the label filter is returned unchanged.
+func (a *ModelAuthZRBAC) CanGetModelsByLabel(ctx context.Context, curUser model.User,
+	workspaceIDs []int32, labels []string,
+) (workspaceIDsWithPermsFilter []int32, labelsFilter []string, serverError error) {
+	workspaceIDsWithPermsFilter, serverError = a.CanGetModels(ctx, curUser, workspaceIDs)
+	if serverError != nil {
+		return workspaceIDsWithPermsFilter, nil, serverError
+	}
+	return workspaceIDsWithPermsFilter, labels, nil
+}
+
+// Validate checks that RBAC roles can be read, since every decision depends on them, and that
+// there are roles to assign.
+func (a *ModelAuthZRBAC) Validate(ctx context.Context) error {
+	_, total, err := rbac.GetAllRoles(ctx, false, 0, 1)
+	if err != nil {
+		return fmt.Errorf("reading RBAC roles: %w", err)
+	}
+	if total == 0 {
+		return errors.New("no RBAC roles are defined")
+	}
+	return nil
+}
+
+// CanAccessModelWorkspace checks if a user has permissions to view models in a workspace.
+func (a *ModelAuthZRBAC) CanAccessModelWorkspace(ctx context.Context, curUser model.User,
+	workspaceID int32,
+) (canAccess bool, serverError error) {
+	if err := RequireUser(&curUser); err != nil {
+		return false, err
+	}
+	fields := audit.ExtractLogFields(ctx)
+	addExpInfo(curUser, fields, fmt.Sprintf("all models in workspace %d", workspaceID),
+		[]rbacv1.PermissionType{rbacv1.PermissionType_PERMISSION_TYPE_VIEW_MODEL_REGISTRY})
+	defer func() {
+		if serverError == nil {
+			fields["permissionGranted"] = canAccess
+			audit.Log(fields)
+		}
+	}()
+
+	err := db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID,
+		rbacv1.PermissionType_PERMISSION_TYPE_VIEW_MODEL_REGISTRY)
+	if authz.IsPermissionDenied(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // CanGetModel checks if a user has permissions to view model.
 func (a *ModelAuthZRBAC) CanGetModel(ctx context.Context, curUser model.User,
 	m *modelv1.Model, workspaceID int32,
 ) (err error) {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
 	fields := audit.ExtractLogFields(ctx)
 	addExpInfo(curUser, fields, string(m.Id),
 		[]rbacv1.PermissionType{rbacv1.PermissionType_PERMISSION_TYPE_VIEW_MODEL_REGISTRY})
@@ -116,14 +196,220 @@ func (a *ModelAuthZRBAC) CanGetModel(ctx context.Context, curUser model.User,
 		}
 	}()
 
-	return db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID,
+	err = db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID,
 		rbacv1.PermissionType_PERMISSION_TYPE_VIEW_MODEL_REGISTRY)
+	if err == nil {
+		err = checkModelVisibility(curUser, m.Visibility, m.OwnerId)
+	}
+	return allowModelAccessGrant(ctx, curUser, m.Id, model.ModelAccessRead, fields, err)
+}
+
+// allowModelAccessGrant lifts err, the denial of an action on a model, if the user was granted
+// at least the access level to the model, and records the grant in the audit fields. READ grants
+// cover getting the model and its versions. EDIT grants also cover the edits authorized by
+// CanEditModel, CanEditModelMetadata, CanEditModelTags, CanArchiveModel and CanUnarchiveModel,
+// but not editing versions or webhooks, managing grants, or moving, deleting, restoring or
+// transferring the model, which still need the user's roles.
+func allowModelAccessGrant(ctx context.Context, curUser model.User, modelID int32,
+	level string, fields log.Fields, err error,
+) error {
+	if !authz.IsPermissionDenied(err) {
+		return err
+	}
+	granted, grantErr := hasModelAccessGrant(ctx, curUser, modelID, level)
+	if grantErr != nil {
+		return grantErr
+	} else if granted {
+		fields["accessGranted"] = level
+		return nil
+	}
+	return err
+}
+
+// hasModelAccessGrant returns whether the user was granted at least the access level to a
+// model. Grants are read on every check, so that revoking one takes effect immediately.
+func hasModelAccessGrant(
+	ctx context.Context, curUser model.User, modelID int32, level string,
+) (bool, error) {
+	g, err := db.GetModelAccessGrant(ctx, modelID, curUser.ID)
+	if errors.Is(err, db.ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return g.Allows(level), nil
+}
+
+// ExplainGetModel makes the same decision as CanGetModel and names the permission or the
+// visibility that decided it.
+func (a *ModelAuthZRBAC) ExplainGetModel(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) (bool, string, error) {
+	if err := RequireUser(&curUser); err != nil {
+		return false, "", err
+	}
+	perm := rbacv1.PermissionType_PERMISSION_TYPE_VIEW_MODEL_REGISTRY
+	var reason string
+	err := db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID, perm)
+	switch {
+	case authz.IsPermissionDenied(err):
+		reason = fmt.Sprintf("user does not have %s in workspace %d",
+			rbacv1.PermissionType_name[int32(perm)], workspaceID)
+	case err != nil:
+		return false, "", err
+	case checkModelVisibility(curUser, m.Visibility, m.OwnerId) != nil:
+		reason = "model is private and the user does not own it"
+	default:
+		return true, fmt.Sprintf("user has %s in workspace %d",
+			rbacv1.PermissionType_name[int32(perm)], workspaceID), nil
+	}
+
+	granted, err := hasModelAccessGrant(ctx, curUser, m.Id, model.ModelAccessRead)
+	if err != nil {
+		return false, "", err
+	} else if granted {
+		return true, reason + ", but was granted access to the model", nil
+	}
+	return false, reason, nil
+}
+
+// requireModelVisible fails with the same NotFound error as a missing model if the model is
+// private to another user who was not granted access to it, so that actions on it neither
+// succeed nor tell the user that it exists.
+func requireModelVisible(ctx context.Context, curUser model.User, m *modelv1.Model) error {
+	if checkModelVisibility(curUser, m.Visibility, m.OwnerId) == nil {
+		return nil
+	}
+	granted, err := hasModelAccessGrant(ctx, curUser, m.Id, model.ModelAccessRead)
+	if err != nil {
+		return err
+	} else if granted {
+		return nil
+	}
+	return status.Errorf(codes.NotFound, "model %q not found", m.Name)
+}
+
+// checkModelVisibility denies everyone but its owner access to a private model.
+func checkModelVisibility(
+	curUser model.User, visibility modelv1.ModelVisibility, ownerID int32,
+) error {
+	if visibility == modelv1.ModelVisibility_MODEL_VISIBILITY_PRIVATE &&
+		ownerID != int32(curUser.ID) {
+		return authz.PermissionDeniedError{Prefix: "model is private:"}
+	}
+	return nil
+}
+
+// filterModelVisibility filters out the private models of other users from a query on models.
+func filterModelVisibility(curUser model.User, query *bun.SelectQuery) *bun.SelectQuery {
+	return query.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Where("visibility != 'PRIVATE'").WhereOr("owner_id = ?", curUser.ID)
+	})
+}
+
+// CanGetModelsByIDs checks which of the models a user has permissions to view.
+func (a *ModelAuthZRBAC) CanGetModelsByIDs(ctx context.Context, curUser model.User,
+	modelIDs []int32, workspaceID int32,
+) (allowed map[int32]bool, serverError error) {
+	if err := RequireUser(&curUser); err != nil {
+		return nil, err
+	}
+	fields := audit.ExtractLogFields(ctx)
+	addExpInfo(curUser, fields, fmt.Sprintf("models %v", modelIDs),
+		[]rbacv1.PermissionType{rbacv1.PermissionType_PERMISSION_TYPE_VIEW_MODEL_REGISTRY})
+	defer func() {
+		if serverError == nil {
+			var granted []int32
+			for _, id := range modelIDs {
+				if allowed[id] {
+					granted = append(granted, id)
+				}
+			}
+			fields["permissionGranted"] = len(granted) == len(modelIDs)
+			fields["modelIDsGranted"] = granted
+			audit.Log(fields)
+		}
+	}()
+
+	allowed = make(map[int32]bool, len(modelIDs))
+	if len(modelIDs) == 0 {
+		return allowed, nil
+	}
+
+	var models []struct {
+		ID          int32
+		WorkspaceID int32
+		Visibility  string
+		OwnerID     int32
+	}
+	q := db.Bun().NewSelect().
+		Table("models").
+		Column("id", "workspace_id", "visibility", "owner_id").
+		Where("id IN (?)", bun.In(modelIDs)).
+		Where("deleted_at IS NULL")
+	if workspaceID != 0 {
+		q = q.Where("workspace_id = ?", workspaceID)
+	}
+	if err := q.Scan(ctx, &models); err != nil {
+		return nil, err
+	}
+
+	assignmentsMap, err := rbac.GetPermissionSummary(ctx, curUser.ID)
+	if err != nil {
+		return nil, err
+	}
+	global := false
+	workspacesWithPerms := make(map[int32]bool)
+	for role, roleAssignments := range assignmentsMap {
+		for _, permission := range role.Permissions {
+			if permission.ID != int(rbacv1.PermissionType_PERMISSION_TYPE_VIEW_MODEL_REGISTRY) {
+				continue
+			}
+			for _, assignment := range roleAssignments {
+				if assignment.Scope.WorkspaceID.Valid {
+					workspacesWithPerms[assignment.Scope.WorkspaceID.Int32] = true
+				} else {
+					global = true
+				}
+			}
+		}
+	}
+
+	for _, id := range modelIDs {
+		allowed[id] = false
+	}
+	for _, m := range models {
+		visibility := modelv1.ModelVisibility(
+			modelv1.ModelVisibility_value["MODEL_VISIBILITY_"+m.Visibility])
+		allowed[m.ID] = (global || workspacesWithPerms[m.WorkspaceID]) &&
+			checkModelVisibility(curUser, visibility, m.OwnerID) == nil
+	}
+
+	var denied []int32
+	for _, m := range models {
+		if !allowed[m.ID] {
+			denied = append(denied, m.ID)
+		}
+	}
+	if len(denied) > 0 {
+		granted, err := db.GetModelAccessGrantModelIDs(ctx, curUser.ID, denied)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range granted {
+			allowed[id] = true
+		}
+	}
+	return allowed, nil
 }
 
 // CanEditModel checks is user has permissions to edit models.
 func (a *ModelAuthZRBAC) CanEditModel(ctx context.Context, curUser model.User,
 	m *modelv1.Model, workspaceID int32,
 ) (err error) {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
 	fields := audit.ExtractLogFields(ctx)
 	addExpInfo(curUser, fields, string(m.Id),
 		[]rbacv1.PermissionType{rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY})
@@ -131,12 +417,180 @@ func (a *ModelAuthZRBAC) CanEditModel(ctx context.Context, curUser model.User,
 		audit.LogFromErr(fields, err)
 	}()
 
+	if err = requireModelVisible(ctx, curUser, m); err != nil {
+		return err
+	}
+
+	err = db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID,
+		rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY)
+	return allowModelAccessGrant(ctx, curUser, m.Id, model.ModelAccessEdit, fields, err)
+}
+
+// CanManageModelAccess checks if a user has permissions to grant other users access to a model.
+// Owners need to be able to edit the model, and anyone else also needs to be able to assign
+// roles in its workspace, since a grant gives access much like a role does.
+func (a *ModelAuthZRBAC) CanManageModelAccess(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) (err error) {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
+	expectedPermissions := []rbacv1.PermissionType{
+		rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY,
+	}
+	if m.OwnerId != int32(curUser.ID) {
+		expectedPermissions = append(expectedPermissions,
+			rbacv1.PermissionType_PERMISSION_TYPE_ASSIGN_ROLES)
+	}
+
+	fields := audit.ExtractLogFields(ctx)
+	addExpInfo(curUser, fields, fmt.Sprint(m.Id), expectedPermissions)
+	defer func() {
+		audit.LogFromErr(fields, err)
+	}()
+
+	if err = requireModelVisible(ctx, curUser, m); err != nil {
+		return err
+	}
+
+	for _, perm := range expectedPermissions {
+		if err := db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID, perm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CanEditModelWebhooks checks if a user has permissions to manage the webhooks of a model. The
+// webhooks of a model are part of editing it, and also reveal where its events are sent.
+func (a *ModelAuthZRBAC) CanEditModelWebhooks(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) (err error) {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
+	fields := audit.ExtractLogFields(ctx)
+	addExpInfo(curUser, fields, fmt.Sprint(m.Id),
+		[]rbacv1.PermissionType{rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY})
+	defer func() {
+		audit.LogFromErr(fields, err)
+	}()
+
+	if err = requireModelVisible(ctx, curUser, m); err != nil {
+		return err
+	}
+
 	return db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID,
 		rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY)
 }
 
-// CanCreateModel checks is user has permissions to create models.
-func (a *ModelAuthZRBAC) CanCreateModel(ctx context.Context,
+// CanEditModelMetadata checks if user has permissions to edit a model's metadata.
+func (a *ModelAuthZRBAC) CanEditModelMetadata(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) (err error) {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
+	fields := audit.ExtractLogFields(ctx)
+	addExpInfo(curUser, fields, fmt.Sprint(m.Id),
+		[]rbacv1.PermissionType{rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY_METADATA})
+	defer func() {
+		audit.LogFromErr(fields, err)
+	}()
+
+	if err = requireModelVisible(ctx, curUser, m); err != nil {
+		return err
+	}
+
+	err = db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID,
+		rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY_METADATA)
+	return allowModelAccessGrant(ctx, curUser, m.Id, model.ModelAccessEdit, fields, err)
+}
+
+// CanEditModelTags checks if user has permissions to edit a model's tags.
+func (a *ModelAuthZRBAC) CanEditModelTags(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) (err error) {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
+	fields := audit.ExtractLogFields(ctx)
+	addExpInfo(curUser, fields, fmt.Sprint(m.Id),
+		[]rbacv1.PermissionType{rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY})
+	defer func() {
+		audit.LogFromErr(fields, err)
+	}()
+
+	if err = requireModelVisible(ctx, curUser, m); err != nil {
+		return err
+	}
+
+	err = db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID,
+		rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY)
+	return allowModelAccessGrant(ctx, curUser, m.Id, model.ModelAccessEdit, fields, err)
+}
+
+// CanArchiveModel checks if user has permissions to archive a model.
+func (a *ModelAuthZRBAC) CanArchiveModel(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) (err error) {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
+	fields := audit.ExtractLogFields(ctx)
+	addExpInfo(curUser, fields, fmt.Sprint(m.Id),
+		[]rbacv1.PermissionType{rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY})
+	defer func() {
+		audit.LogFromErr(fields, err)
+	}()
+
+	if err = requireModelVisible(ctx, curUser, m); err != nil {
+		return err
+	}
+
+	err = db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID,
+		rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY)
+	return allowModelAccessGrant(ctx, curUser, m.Id, model.ModelAccessEdit, fields, err)
+}
+
+// CanUnarchiveModel checks if user has permissions to unarchive a model.
+func (a *ModelAuthZRBAC) CanUnarchiveModel(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) (err error) {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
+	fields := audit.ExtractLogFields(ctx)
+	addExpInfo(curUser, fields, fmt.Sprint(m.Id),
+		[]rbacv1.PermissionType{rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY})
+	defer func() {
+		audit.LogFromErr(fields, err)
+	}()
+
+	if err = requireModelVisible(ctx, curUser, m); err != nil {
+		return err
+	}
+
+	err = db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID,
+		rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY)
+	return allowModelAccessGrant(ctx, curUser, m.Id, model.ModelAccessEdit, fields, err)
+}
+
+// CanCreateModel checks is user has permissions to create models and that the workspace is
+// under its model quota.
+func (a *ModelAuthZRBAC) CanCreateModel(ctx context.Context, idb bun.IDB,
+	curUser model.User, workspaceID int32,
+) error {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
+	if err := a.canCreateModel(ctx, curUser, workspaceID); err != nil {
+		return err
+	}
+	return checkWorkspaceModelQuota(ctx, idb, workspaceID)
+}
+
+func (a *ModelAuthZRBAC) canCreateModel(ctx context.Context,
 	curUser model.User, workspaceID int32,
 ) (err error) {
 	fields := audit.ExtractLogFields(ctx)
@@ -150,12 +604,98 @@ func (a *ModelAuthZRBAC) CanCreateModel(ctx context.Context,
 		rbacv1.PermissionType_PERMISSION_TYPE_CREATE_MODEL_REGISTRY)
 }
 
+// GetModelCreationQuota reports whether CanCreateModel would allow the user to create a model
+// in the workspace, checking permissions and then the workspace's model quota.
+func (a *ModelAuthZRBAC) GetModelCreationQuota(ctx context.Context, curUser model.User,
+	workspaceID int32,
+) (*model.ModelCreationQuota, error) {
+	if err := RequireUser(&curUser); err != nil {
+		return nil, err
+	}
+	res := &model.ModelCreationQuota{Allowed: true}
+	if quota := config.GetMasterConfig().ModelRegistry.MaxModelsPerWorkspace; quota > 0 {
+		res.Limit = &quota
+	}
+
+	if err := a.canCreateModel(ctx, curUser, workspaceID); authz.IsPermissionDenied(err) {
+		return &model.ModelCreationQuota{Allowed: false, Reason: err.Error(), Limit: res.Limit}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if res.Limit == nil {
+		return res, nil
+	}
+
+	count, err := countWorkspaceModels(ctx, db.Bun(), workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	res.Count = count
+	if err := workspaceModelQuotaError(workspaceID, count, *res.Limit); err != nil {
+		res.Allowed = false
+		res.Reason = status.Convert(err).Message()
+	}
+	return res, nil
+}
+
+// checkWorkspaceModelQuota returns a ResourceExhausted error if the workspace already has
+// model_registry.max_models_per_workspace models. It locks the workspace row in idb, so within
+// a transaction concurrent creates in the workspace wait until it commits.
+func checkWorkspaceModelQuota(ctx context.Context, idb bun.IDB, workspaceID int32) error {
+	quota := config.GetMasterConfig().ModelRegistry.MaxModelsPerWorkspace
+	if quota <= 0 {
+		return nil
+	}
+	if idb == nil {
+		idb = db.Bun()
+	}
+
+	if _, err := idb.NewSelect().
+		Table("workspaces").
+		Column("id").
+		Where("id = ?", workspaceID).
+		For("NO KEY UPDATE").
+		Exec(ctx); err != nil {
+		return fmt.Errorf("locking workspace %d: %w", workspaceID, err)
+	}
+	count, err := countWorkspaceModels(ctx, idb, workspaceID)
+	if err != nil {
+		return err
+	}
+	return workspaceModelQuotaError(workspaceID, count, quota)
+}
+
+// countWorkspaceModels counts the models in the workspace that count towards its quota.
+func countWorkspaceModels(ctx context.Context, idb bun.IDB, workspaceID int32) (int, error) {
+	count, err := idb.NewSelect().
+		Table("models").
+		Where("workspace_id = ?", workspaceID).
+		Where("deleted_at IS NULL").
+		Count(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("counting models in workspace %d: %w", workspaceID, err)
+	}
+	return count, nil
+}
+
+// workspaceModelQuotaError returns a ResourceExhausted error if count models reach quota.
+func workspaceModelQuotaError(workspaceID int32, count, quota int) error {
+	if count >= quota {
+		return status.Errorf(codes.ResourceExhausted,
+			"workspace %d has reached its quota of %d models", workspaceID, quota)
+	}
+	return nil
+}
+
 // CanDeleteModel checks if user has permission to delete model.
 func (a *ModelAuthZRBAC) CanDeleteModel(ctx context.Context, curUser model.User,
 	m *modelv1.Model, workspaceID int32,
 ) (err error) {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
 	var expectedPermissions []rbacv1.PermissionType
-	userIsOwner := m.UserId == int32(curUser.ID)
+	userIsOwner := m.OwnerId == int32(curUser.ID)
 	if userIsOwner {
 		expectedPermissions = []rbacv1.PermissionType{
 			rbacv1.PermissionType_PERMISSION_TYPE_DELETE_MODEL_REGISTRY,
@@ -173,6 +713,54 @@ func (a *ModelAuthZRBAC) CanDeleteModel(ctx context.Context, curUser model.User,
 		audit.LogFromErr(fields, err)
 	}()
 
+	if err = requireModelVisible(ctx, curUser, m); err != nil {
+		return err
+	}
+
+	for _, perm := range expectedPermissions {
+		if err := db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID, perm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CanRestoreModel checks if user has permission to restore a deleted model, which requires the
+// same permissions as deleting it, and then that the workspace has room for it under its model
+// quota.
+func (a *ModelAuthZRBAC) CanRestoreModel(ctx context.Context, idb bun.IDB,
+	curUser model.User, m *modelv1.Model, workspaceID int32,
+) error {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
+	if err := a.canRestoreModel(ctx, curUser, m, workspaceID); err != nil {
+		return err
+	}
+	return checkWorkspaceModelQuota(ctx, idb, workspaceID)
+}
+
+func (a *ModelAuthZRBAC) canRestoreModel(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) (err error) {
+	expectedPermissions := []rbacv1.PermissionType{
+		rbacv1.PermissionType_PERMISSION_TYPE_DELETE_MODEL_REGISTRY,
+	}
+	if m.OwnerId != int32(curUser.ID) {
+		expectedPermissions = append(expectedPermissions,
+			rbacv1.PermissionType_PERMISSION_TYPE_DELETE_OTHER_USER_MODEL_REGISTRY)
+	}
+
+	fields := audit.ExtractLogFields(ctx)
+	addExpInfo(curUser, fields, fmt.Sprint(m.Id), expectedPermissions)
+	defer func() {
+		audit.LogFromErr(fields, err)
+	}()
+
+	if err = requireModelVisible(ctx, curUser, m); err != nil {
+		return err
+	}
+
 	for _, perm := range expectedPermissions {
 		if err := db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID, perm); err != nil {
 			return err
@@ -181,13 +769,204 @@ func (a *ModelAuthZRBAC) CanDeleteModel(ctx context.Context, curUser model.User,
 	return nil
 }
 
+// CanTransferModelOwnership checks if a user has permission to give a model to another user.
+// Owners need to be able to edit the model, and anyone else needs to be able to delete other
+// users' models, since taking a model from its owner is as drastic as deleting it.
+func (a *ModelAuthZRBAC) CanTransferModelOwnership(ctx context.Context, curUser model.User,
+	m *modelv1.Model, newOwnerID int32,
+) (err error) {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
+	expectedPermissions := []rbacv1.PermissionType{
+		rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY,
+	}
+	if m.OwnerId != int32(curUser.ID) {
+		expectedPermissions = append(expectedPermissions,
+			rbacv1.PermissionType_PERMISSION_TYPE_DELETE_OTHER_USER_MODEL_REGISTRY)
+	}
+
+	fields := audit.ExtractLogFields(ctx)
+	addExpInfo(curUser, fields, fmt.Sprint(m.Id), expectedPermissions)
+	fields["previousOwnerID"] = m.OwnerId
+	fields["newOwnerID"] = newOwnerID
+	defer func() {
+		audit.LogFromErr(fields, err)
+	}()
+
+	if err = requireModelVisible(ctx, curUser, m); err != nil {
+		return err
+	}
+
+	for _, perm := range expectedPermissions {
+		if err := db.DoesPermissionMatch(ctx, curUser.ID, &m.WorkspaceId, perm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CanGetModelVersions checks if a user has permissions to view a model's versions.
+func (a *ModelAuthZRBAC) CanGetModelVersions(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) (err error) {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
+	fields := audit.ExtractLogFields(ctx)
+	addExpInfo(curUser, fields, fmt.Sprint(m.Id),
+		[]rbacv1.PermissionType{rbacv1.PermissionType_PERMISSION_TYPE_VIEW_MODEL_REGISTRY})
+	defer func() {
+		if err == nil || authz.IsPermissionDenied(err) {
+			fields["permissionGranted"] = !authz.IsPermissionDenied(err)
+			audit.Log(fields)
+		}
+	}()
+
+	err = db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID,
+		rbacv1.PermissionType_PERMISSION_TYPE_VIEW_MODEL_REGISTRY)
+	if err == nil {
+		err = checkModelVisibility(curUser, m.Visibility, m.OwnerId)
+	}
+	return allowModelAccessGrant(ctx, curUser, m.Id, model.ModelAccessRead, fields, err)
+}
+
+// CanGetModelVersion checks if a user has permissions to view a model version.
+func (a *ModelAuthZRBAC) CanGetModelVersion(ctx context.Context, curUser model.User,
+	m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32,
+) (err error) {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
+	fields := audit.ExtractLogFields(ctx)
+	addExpInfo(curUser, fields, fmt.Sprint(m.Id),
+		[]rbacv1.PermissionType{rbacv1.PermissionType_PERMISSION_TYPE_VIEW_MODEL_REGISTRY})
+	defer func() {
+		if err == nil || authz.IsPermissionDenied(err) {
+			fields["permissionGranted"] = !authz.IsPermissionDenied(err)
+			audit.Log(fields)
+		}
+	}()
+
+	err = db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID,
+		rbacv1.PermissionType_PERMISSION_TYPE_VIEW_MODEL_REGISTRY)
+	if err == nil {
+		err = checkModelVisibility(curUser, m.Visibility, m.OwnerId)
+	}
+	return allowModelAccessGrant(ctx, curUser, m.Id, model.ModelAccessRead, fields, err)
+}
+
+// CanDownloadModelVersionCheckpoint checks if a user has permissions to read the artifacts
+// of the experiment that produced the checkpoint of a model version.
+func (a *ModelAuthZRBAC) CanDownloadModelVersionCheckpoint(ctx context.Context,
+	curUser model.User, modelVersion *modelv1.ModelVersion, workspaceID int32,
+	checkpointWorkspaceID *int32,
+) (canDownload bool, serverError error) {
+	if err := RequireUser(&curUser); err != nil {
+		return false, err
+	}
+	if checkpointWorkspaceID == nil {
+		return true, nil
+	}
+
+	fields := audit.ExtractLogFields(ctx)
+	addExpInfo(curUser, fields, modelVersion.GetCheckpoint().GetUuid(),
+		[]rbacv1.PermissionType{rbacv1.PermissionType_PERMISSION_TYPE_VIEW_EXPERIMENT_ARTIFACTS})
+	defer func() {
+		if serverError == nil {
+			fields["permissionGranted"] = canDownload
+			audit.Log(fields)
+		}
+	}()
+
+	err := db.DoesPermissionMatch(ctx, curUser.ID, checkpointWorkspaceID,
+		rbacv1.PermissionType_PERMISSION_TYPE_VIEW_EXPERIMENT_ARTIFACTS)
+	if authz.IsPermissionDenied(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CanCreateModelVersion checks if a user has permissions to register a version of a model.
+func (a *ModelAuthZRBAC) CanCreateModelVersion(ctx context.Context, curUser model.User,
+	m *modelv1.Model, workspaceID int32,
+) (err error) {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
+	fields := audit.ExtractLogFields(ctx)
+	addExpInfo(curUser, fields, fmt.Sprint(m.Id),
+		[]rbacv1.PermissionType{rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY})
+	defer func() {
+		audit.LogFromErr(fields, err)
+	}()
+
+	if err = requireModelVisible(ctx, curUser, m); err != nil {
+		return err
+	}
+
+	return db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID,
+		rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY)
+}
+
+// CanCreateModelVersionFromCheckpoint checks if a user has permissions to read the artifacts
+// of the experiment that produced the checkpoint, which may be in another workspace.
+func (a *ModelAuthZRBAC) CanCreateModelVersionFromCheckpoint(ctx context.Context,
+	curUser model.User, m *modelv1.Model, workspaceID int32, checkpointUUID string,
+	checkpointWorkspaceID *int32,
+) (err error) {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
+	if checkpointWorkspaceID == nil {
+		return nil
+	}
+
+	fields := audit.ExtractLogFields(ctx)
+	addExpInfo(curUser, fields, checkpointUUID,
+		[]rbacv1.PermissionType{rbacv1.PermissionType_PERMISSION_TYPE_VIEW_EXPERIMENT_ARTIFACTS})
+	defer func() {
+		audit.LogFromErr(fields, err)
+	}()
+
+	return db.DoesPermissionMatch(ctx, curUser.ID, checkpointWorkspaceID,
+		rbacv1.PermissionType_PERMISSION_TYPE_VIEW_EXPERIMENT_ARTIFACTS)
+}
+
+// CanEditModelVersion checks if a user has permissions to edit a model version.
+func (a *ModelAuthZRBAC) CanEditModelVersion(ctx context.Context, curUser model.User,
+	m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32,
+) (err error) {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
+	fields := audit.ExtractLogFields(ctx)
+	addExpInfo(curUser, fields, fmt.Sprint(m.Id),
+		[]rbacv1.PermissionType{rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY})
+	defer func() {
+		audit.LogFromErr(fields, err)
+	}()
+
+	if err = requireModelVisible(ctx, curUser, m); err != nil {
+		return err
+	}
+
+	return db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID,
+		rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY)
+}
+
 // CanDeleteModelVersion checks if user has permission to delete model version.
 func (a *ModelAuthZRBAC) CanDeleteModelVersion(ctx context.Context, curUser model.User,
-	modelVersion *modelv1.ModelVersion, workspaceID int32,
+	m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32,
 ) (err error) {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
 	var expectedPermissions []rbacv1.PermissionType
 	userIsOwner := modelVersion.UserId == int32(curUser.ID) ||
-		modelVersion.Model.UserId == int32(curUser.ID)
+		m.OwnerId == int32(curUser.ID)
 	if userIsOwner {
 		expectedPermissions = []rbacv1.PermissionType{
 			rbacv1.PermissionType_PERMISSION_TYPE_DELETE_MODEL_VERSION,
@@ -200,11 +979,15 @@ func (a *ModelAuthZRBAC) CanDeleteModelVersion(ctx context.Context, curUser mode
 	}
 
 	fields := audit.ExtractLogFields(ctx)
-	addExpInfo(curUser, fields, string(modelVersion.Model.Id), expectedPermissions)
+	addExpInfo(curUser, fields, fmt.Sprint(m.Id), expectedPermissions)
 	defer func() {
 		audit.LogFromErr(fields, err)
 	}()
 
+	if err = requireModelVisible(ctx, curUser, m); err != nil {
+		return err
+	}
+
 	for _, perm := range expectedPermissions {
 		if err := db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID, perm); err != nil {
 			return err
@@ -213,9 +996,49 @@ func (a *ModelAuthZRBAC) CanDeleteModelVersion(ctx context.Context, curUser mode
 	return nil
 }
 
-// CanMoveModel checks for edit permission in origin and create permission in destination.
-func (a *ModelAuthZRBAC) CanMoveModel(ctx context.Context,
-	curUser model.User, _ *modelv1.Model, origin int32, destination int32,
+// CanForceDeleteModelVersion checks if a user may delete protected versions of models in the
+// workspace, which requires permission to delete other users' versions even for their own.
+func (a *ModelAuthZRBAC) CanForceDeleteModelVersion(ctx context.Context, curUser model.User,
+	m *modelv1.Model, modelVersion *modelv1.ModelVersion, workspaceID int32,
+) (err error) {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
+	fields := audit.ExtractLogFields(ctx)
+	addExpInfo(curUser, fields, fmt.Sprint(m.Id), []rbacv1.PermissionType{
+		rbacv1.PermissionType_PERMISSION_TYPE_DELETE_OTHER_USER_MODEL_VERSION,
+	})
+	defer func() {
+		audit.LogFromErr(fields, err)
+	}()
+
+	if err = requireModelVisible(ctx, curUser, m); err != nil {
+		return err
+	}
+
+	return db.DoesPermissionMatch(ctx, curUser.ID, &workspaceID,
+		rbacv1.PermissionType_PERMISSION_TYPE_DELETE_OTHER_USER_MODEL_VERSION)
+}
+
+// CanMoveModel checks for edit permission in origin and create permission in destination, and
+// then that the destination has room for the model under its model quota.
+func (a *ModelAuthZRBAC) CanMoveModel(ctx context.Context, idb bun.IDB,
+	curUser model.User, m *modelv1.Model, origin int32, destination int32,
+) error {
+	if err := RequireUser(&curUser); err != nil {
+		return err
+	}
+	if err := a.canMoveModel(ctx, curUser, m, origin, destination); err != nil {
+		return err
+	}
+	if origin == destination {
+		return nil
+	}
+	return checkWorkspaceModelQuota(ctx, idb, destination)
+}
+
+func (a *ModelAuthZRBAC) canMoveModel(ctx context.Context,
+	curUser model.User, m *modelv1.Model, origin int32, destination int32,
 ) (err error) {
 	fields := audit.ExtractLogFields(ctx)
 	addExpInfo(curUser, fields, fmt.Sprintf("moving model from workspace %d to %d", origin,
@@ -228,6 +1051,10 @@ func (a *ModelAuthZRBAC) CanMoveModel(ctx context.Context,
 		audit.LogFromErr(fields, err)
 	}()
 
+	if err = requireModelVisible(ctx, curUser, m); err != nil {
+		return err
+	}
+
 	origErr := db.DoesPermissionMatch(ctx, curUser.ID, &origin,
 		rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY)
 	if origErr != nil {
@@ -238,10 +1065,44 @@ func (a *ModelAuthZRBAC) CanMoveModel(ctx context.Context,
 		rbacv1.PermissionType_PERMISSION_TYPE_CREATE_MODEL_REGISTRY)
 }
 
+// MaskModelFields removes model_registry.restricted_metadata_keys from the metadata of m unless
+// the user may edit models in its workspace.
+func (a *ModelAuthZRBAC) MaskModelFields(ctx context.Context, curUser model.User,
+	m *modelv1.Model,
+) *modelv1.Model {
+	var restricted []string
+	for _, key := range config.GetMasterConfig().ModelRegistry.RestrictedMetadataKeys {
+		if _, ok := m.GetMetadata().GetFields()[key]; ok {
+			restricted = append(restricted, key)
+		}
+	}
+	if len(restricted) == 0 {
+		return m
+	}
+
+	err := db.DoesPermissionMatch(ctx, curUser.ID, &m.WorkspaceId,
+		rbacv1.PermissionType_PERMISSION_TYPE_EDIT_MODEL_REGISTRY)
+	if err == nil {
+		return m
+	}
+	if !authz.IsPermissionDenied(err) {
+		log.WithError(err).Warnf("failed to check whether to mask metadata of model %d, masking it",
+			m.Id)
+	}
+	masked := proto.Clone(m).(*modelv1.Model)
+	for _, key := range restricted {
+		delete(masked.Metadata.Fields, key)
+	}
+	return masked
+}
+
 // FilterReadableModelsQuery returns query in relevant workspaces and a nil error.
 func (a *ModelAuthZRBAC) FilterReadableModelsQuery(
 	ctx context.Context, curUser model.User, query *bun.SelectQuery,
 ) (*bun.SelectQuery, error) {
+	if err := RequireUser(&curUser); err != nil {
+		return query, err
+	}
 	fields := audit.ExtractLogFields(ctx)
 	fields["userID"] = curUser.ID
 	fields["permissionRequired"] = []audit.PermissionWithSubject{
@@ -264,6 +1125,7 @@ func (a *ModelAuthZRBAC) FilterReadableModelsQuery(
 	}
 
 	var workspaces []int32
+	global := false
 
 	for role, roleAssignments := range assignmentsMap {
 		for _, permission := range role.Permissions {
@@ -273,23 +1135,98 @@ func (a *ModelAuthZRBAC) FilterReadableModelsQuery(
 					if assignment.Scope.WorkspaceID.Valid {
 						workspaces = append(workspaces, assignment.Scope.WorkspaceID.Int32)
 					} else {
-						// if permission is global, return without filtering
-						return query, nil
+						global = true
 					}
 				}
 			}
 		}
 	}
 
-	if len(workspaces) == 0 {
-		return query.Where("false"), nil
+	// Models the user was granted access to are readable regardless of their roles.
+	return query.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+		switch {
+		case global:
+			// if permission is global, only filter out private models
+			q = filterModelVisibility(curUser, q)
+		case len(workspaces) == 0:
+			q = q.Where("false")
+		default:
+			q = filterModelVisibility(curUser, q.Where("workspace_id IN (?)", bun.In(workspaces)))
+		}
+		return q.WhereOr("EXISTS (SELECT 1 FROM model_access_grants AS mag "+
+			"WHERE mag.model_id = m.id AND mag.user_id = ?)", curUser.ID)
+	}), nil
+}
+
+// CountModels counts the models of the query in the workspace that the user can read.
+func (a *ModelAuthZRBAC) CountModels(
+	ctx context.Context, curUser model.User, workspaceID int32, query *bun.SelectQuery,
+) (int, error) {
+	query, err := a.FilterReadableModelsQuery(ctx, curUser, query)
+	if err != nil {
+		return 0, err
+	}
+	return query.Where("m.workspace_id = ?", workspaceID).Count(ctx)
+}
+
+// FilterModelVersionsQuery filters out versions whose checkpoints come from experiments in
+// workspaces where the user cannot view experiment artifacts.
+func (a *ModelAuthZRBAC) FilterModelVersionsQuery(
+	ctx context.Context, curUser model.User, m *modelv1.Model, query *bun.SelectQuery,
+) (*bun.SelectQuery, error) {
+	if err := RequireUser(&curUser); err != nil {
+		return query, err
+	}
+	fields := audit.ExtractLogFields(ctx)
+	fields["userID"] = curUser.ID
+	fields["permissionRequired"] = []audit.PermissionWithSubject{
+		{
+			PermissionTypes: []rbacv1.PermissionType{
+				rbacv1.PermissionType_PERMISSION_TYPE_VIEW_EXPERIMENT_ARTIFACTS,
+			},
+			SubjectType: "model versions",
+		},
+	}
+
+	var err error
+	defer func() {
+		audit.LogFromErr(fields, err)
+	}()
+
+	assignmentsMap, err := rbac.GetPermissionSummary(ctx, curUser.ID)
+	if err != nil {
+		return query, err
+	}
+
+	workspaces := []int32{}
+
+	for role, roleAssignments := range assignmentsMap {
+		for _, permission := range role.Permissions {
+			if permission.ID == int(
+				rbacv1.PermissionType_PERMISSION_TYPE_VIEW_EXPERIMENT_ARTIFACTS) {
+				for _, assignment := range roleAssignments {
+					if assignment.Scope.WorkspaceID.Valid {
+						workspaces = append(workspaces, assignment.Scope.WorkspaceID.Int32)
+					} else {
+						// if permission is global, return without filtering
+						return query, nil
+					}
+				}
+			}
+		}
 	}
 
-	query = query.Where("workspace_id IN (?)", bun.In(workspaces))
+	// Checkpoints not produced by an experiment are always visible.
+	query = query.Where(`NOT EXISTS (
+		SELECT 1 FROM checkpoints_view AS fc
+		JOIN experiments AS fe ON fe.id = fc.experiment_id
+		JOIN projects AS fp ON fp.id = fe.project_id
+		WHERE fc.uuid = mv.checkpoint_uuid AND fp.workspace_id != ALL(?)
+	)`, pgdialect.Array(workspaces))
 
 	return query, nil
 }
 
 func init() {
-	AuthZProvider.Register("rbac", &ModelAuthZRBAC{})
+	AuthZProvider.Register("rbac", withCanGetModelsCache(&ModelAuthZRBAC{}))
 }
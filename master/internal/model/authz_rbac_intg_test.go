@@ -0,0 +1,290 @@
+//go:build integration
+// +build integration
+
+package model
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/determined-ai/determined/master/internal/authz"
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/internal/rbac"
+	"github.com/determined-ai/determined/master/pkg/etc"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+	"github.com/determined-ai/determined/proto/pkg/rbacv1"
+)
+
+func TestCheckWorkspaceModelQuota(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, etc.SetRootPath(db.RootFromDB))
+	pgDB := db.MustResolveTestPostgres(t)
+	db.MustMigrateTestPostgres(t, pgDB, db.MigrationsFromDB)
+	user := db.RequireMockUser(t, pgDB)
+	workspaceID, _ := db.RequireMockWorkspaceID(t, pgDB, "")
+
+	registry := &config.GetMasterConfig().ModelRegistry
+	prevQuota := registry.MaxModelsPerWorkspace
+	defer func() { registry.MaxModelsPerWorkspace = prevQuota }()
+	registry.MaxModelsPerWorkspace = 1
+
+	create := func() error {
+		return db.Bun().RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+			if err := checkWorkspaceModelQuota(ctx, tx, int32(workspaceID)); err != nil {
+				return err
+			}
+			_, err := db.InsertModelTx(ctx, tx, uuid.NewString(), "", []byte(`{}`), "", "",
+				user.ID, workspaceID, modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE)
+			return err
+		})
+	}
+
+	require.NoError(t, create())
+	err := create()
+	require.Equal(t, codes.ResourceExhausted, status.Code(err), err)
+
+	registry.MaxModelsPerWorkspace = 2
+	require.NoError(t, create())
+
+	registry.MaxModelsPerWorkspace = 0
+	require.NoError(t, create(), "zero disables the quota")
+}
+
+func TestMoveAndRestoreModelQuota(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, etc.SetRootPath(db.RootFromDB))
+	pgDB := db.MustResolveTestPostgres(t)
+	db.MustMigrateTestPostgres(t, pgDB, db.MigrationsFromDB)
+	user := db.RequireMockUser(t, pgDB)
+	fromID, _ := db.RequireMockWorkspaceID(t, pgDB, "")
+	toID, _ := db.RequireMockWorkspaceID(t, pgDB, "")
+
+	const workspaceAdminRoleID = 2
+	require.NoError(t, rbac.AddRoleAssignments(ctx, nil, []*rbacv1.UserRoleAssignment{{
+		UserId: int32(user.ID),
+		RoleAssignment: &rbacv1.RoleAssignment{
+			Role:         &rbacv1.Role{RoleId: workspaceAdminRoleID},
+			ScopeCluster: true,
+		},
+	}}))
+
+	insert := func(workspaceID int) *modelv1.Model {
+		m, err := db.InsertModelTx(ctx, db.Bun(), uuid.NewString(), "", []byte(`{}`), "", "",
+			user.ID, workspaceID, modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE)
+		require.NoError(t, err)
+		return m
+	}
+	insert(toID)
+	moved := insert(fromID)
+	deleted := insert(fromID)
+	_, err := db.Bun().NewUpdate().Table("models").
+		Set("deleted_at = current_timestamp").
+		Where("id = ?", deleted.Id).
+		Exec(ctx)
+	require.NoError(t, err)
+
+	registry := &config.GetMasterConfig().ModelRegistry
+	prevQuota := registry.MaxModelsPerWorkspace
+	defer func() { registry.MaxModelsPerWorkspace = prevQuota }()
+	registry.MaxModelsPerWorkspace = 1
+
+	modelAuthZ := &ModelAuthZRBAC{}
+	inTx := func(f func(tx bun.Tx) error) error {
+		return db.Bun().RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+			return f(tx)
+		})
+	}
+	move := func(to int) error {
+		return inTx(func(tx bun.Tx) error {
+			return modelAuthZ.CanMoveModel(ctx, tx, user, moved, int32(fromID), int32(to))
+		})
+	}
+	restore := func() error {
+		return inTx(func(tx bun.Tx) error {
+			return modelAuthZ.CanRestoreModel(ctx, tx, user, deleted, int32(fromID))
+		})
+	}
+
+	err = move(toID)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err), err)
+	require.NoError(t, move(fromID), "a model does not count against its own workspace")
+	err = restore()
+	require.Equal(t, codes.ResourceExhausted, status.Code(err), err)
+
+	registry.MaxModelsPerWorkspace = 2
+	require.NoError(t, move(toID))
+	require.NoError(t, restore())
+}
+
+func TestMaskModelFields(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, etc.SetRootPath(db.RootFromDB))
+	pgDB := db.MustResolveTestPostgres(t)
+	db.MustMigrateTestPostgres(t, pgDB, db.MigrationsFromDB)
+	user := db.RequireMockUser(t, pgDB)
+	workspaceID, _ := db.RequireMockWorkspaceID(t, pgDB, "")
+
+	registry := &config.GetMasterConfig().ModelRegistry
+	prevKeys := registry.RestrictedMetadataKeys
+	defer func() { registry.RestrictedMetadataKeys = prevKeys }()
+	registry.RestrictedMetadataKeys = []string{"owner_email"}
+
+	metadata, err := structpb.NewStruct(map[string]any{
+		"owner_email": "someone@example.com",
+		"framework":   "torch",
+	})
+	require.NoError(t, err)
+	m := &modelv1.Model{Id: 1, WorkspaceId: int32(workspaceID), Metadata: metadata}
+
+	masked := (&ModelAuthZRBAC{}).MaskModelFields(ctx, user, m)
+	require.NotContains(t, masked.Metadata.Fields, "owner_email",
+		"users without edit permission do not see restricted keys")
+	require.Contains(t, masked.Metadata.Fields, "framework")
+	require.Contains(t, m.Metadata.Fields, "owner_email", "the model itself is not modified")
+
+	unrestricted := &modelv1.Model{Id: 2, WorkspaceId: int32(workspaceID)}
+	require.Same(t, unrestricted, (&ModelAuthZRBAC{}).MaskModelFields(ctx, user, unrestricted))
+}
+
+func TestExplainGetModel(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, etc.SetRootPath(db.RootFromDB))
+	pgDB := db.MustResolveTestPostgres(t)
+	db.MustMigrateTestPostgres(t, pgDB, db.MigrationsFromDB)
+	user := db.RequireMockUser(t, pgDB)
+	workspaceID, _ := db.RequireMockWorkspaceID(t, pgDB, "")
+
+	m := &modelv1.Model{Id: 1, WorkspaceId: int32(workspaceID)}
+	allowed, reason, err := (&ModelAuthZRBAC{}).ExplainGetModel(ctx, user, m, m.WorkspaceId)
+	require.NoError(t, err)
+	require.False(t, allowed)
+	require.Contains(t, reason, "PERMISSION_TYPE_VIEW_MODEL_REGISTRY")
+
+	allowed, reason, err = (&ModelAuthZBasic{}).ExplainGetModel(ctx, user, m, m.WorkspaceId)
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.Equal(t, "OSS basic auth allows all", reason)
+}
+
+// A service account that holds a cluster-wide read grant but belongs to no workspace.
+func TestGlobalReadUserWithoutWorkspaces(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, etc.SetRootPath(db.RootFromDB))
+	pgDB := db.MustResolveTestPostgres(t)
+	db.MustMigrateTestPostgres(t, pgDB, db.MigrationsFromDB)
+	owner := db.RequireMockUser(t, pgDB)
+	serviceAccount := db.RequireMockUser(t, pgDB)
+	workspaceID, _ := db.RequireMockWorkspaceID(t, pgDB, "")
+	m, err := db.InsertModelTx(ctx, db.Bun(), uuid.NewString(), "", []byte(`{}`), "", "",
+		owner.ID, workspaceID, modelv1.ModelVisibility_MODEL_VISIBILITY_WORKSPACE)
+	require.NoError(t, err)
+
+	authz := &ModelAuthZRBAC{}
+	_, err = authz.CanGetModels(ctx, serviceAccount, nil)
+	require.Error(t, err, "no grant yet")
+
+	const modelRegistryViewerRoleID = 6
+	require.NoError(t, rbac.AddRoleAssignments(ctx, nil, []*rbacv1.UserRoleAssignment{{
+		UserId: int32(serviceAccount.ID),
+		RoleAssignment: &rbacv1.RoleAssignment{
+			Role:         &rbacv1.Role{RoleId: modelRegistryViewerRoleID},
+			ScopeCluster: true,
+		},
+	}}))
+
+	workspaceIDs, err := authz.CanGetModels(ctx, serviceAccount, nil)
+	require.NoError(t, err)
+	require.Nil(t, workspaceIDs, "every workspace")
+	workspaceIDs, err = authz.CanGetModels(ctx, serviceAccount, []int32{m.WorkspaceId})
+	require.NoError(t, err)
+	require.Equal(t, []int32{m.WorkspaceId}, workspaceIDs)
+	require.NoError(t, authz.CanGetModel(ctx, serviceAccount, m, m.WorkspaceId))
+
+	query, err := authz.FilterReadableModelsQuery(ctx, serviceAccount,
+		db.Bun().NewSelect().TableExpr("models AS m").Column("m.id"))
+	require.NoError(t, err)
+	var readable []int32
+	require.NoError(t, query.Scan(ctx, &readable))
+	require.Contains(t, readable, m.Id)
+
+	require.Error(t, authz.CanEditModel(ctx, serviceAccount, m, m.WorkspaceId), "read only")
+}
+
+func TestModelAccessGrants(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, etc.SetRootPath(db.RootFromDB))
+	pgDB := db.MustResolveTestPostgres(t)
+	db.MustMigrateTestPostgres(t, pgDB, db.MigrationsFromDB)
+	owner := db.RequireMockUser(t, pgDB)
+	grantee := db.RequireMockUser(t, pgDB)
+	workspaceID, _ := db.RequireMockWorkspaceID(t, pgDB, "")
+	m, err := db.InsertModelTx(ctx, db.Bun(), uuid.NewString(), "", []byte(`{}`), "", "",
+		owner.ID, workspaceID, modelv1.ModelVisibility_MODEL_VISIBILITY_PRIVATE)
+	require.NoError(t, err)
+
+	modelAuthZ := &ModelAuthZRBAC{}
+	require.True(t, authz.IsPermissionDenied(modelAuthZ.CanGetModel(ctx, grantee, m, m.WorkspaceId)))
+	require.Error(t, modelAuthZ.CanManageModelAccess(ctx, grantee, m, m.WorkspaceId))
+
+	grant := func(access string) {
+		require.NoError(t, db.PutModelAccessGrant(ctx, &model.ModelAccessGrant{
+			ModelID: m.Id, UserID: grantee.ID, Access: access, GrantedByID: &owner.ID,
+		}))
+	}
+	grant(model.ModelAccessRead)
+	require.NoError(t, modelAuthZ.CanGetModel(ctx, grantee, m, m.WorkspaceId),
+		"grants give access to private models in workspaces the user cannot see")
+	allowed, reason, err := modelAuthZ.ExplainGetModel(ctx, grantee, m, m.WorkspaceId)
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.Contains(t, reason, "granted access")
+	require.NoError(t, modelAuthZ.CanGetModelVersions(ctx, grantee, m, m.WorkspaceId))
+	require.NoError(t, modelAuthZ.CanGetModelVersion(ctx, grantee, m, &modelv1.ModelVersion{},
+		m.WorkspaceId))
+	query, err := modelAuthZ.FilterReadableModelsQuery(ctx, grantee,
+		db.Bun().NewSelect().TableExpr("models AS m").Column("m.id"))
+	require.NoError(t, err)
+	var readable []int32
+	require.NoError(t, query.Scan(ctx, &readable))
+	require.Equal(t, []int32{m.Id}, readable)
+	require.Error(t, modelAuthZ.CanEditModel(ctx, grantee, m, m.WorkspaceId), "read only")
+	require.Error(t, modelAuthZ.CanEditModelTags(ctx, grantee, m, m.WorkspaceId), "read only")
+	require.Error(t, modelAuthZ.CanManageModelAccess(ctx, grantee, m, m.WorkspaceId))
+
+	grant(model.ModelAccessEdit)
+	require.NoError(t, modelAuthZ.CanEditModel(ctx, grantee, m, m.WorkspaceId))
+	require.NoError(t, modelAuthZ.CanGetModel(ctx, grantee, m, m.WorkspaceId))
+	require.NoError(t, modelAuthZ.CanEditModelMetadata(ctx, grantee, m, m.WorkspaceId))
+	require.NoError(t, modelAuthZ.CanEditModelTags(ctx, grantee, m, m.WorkspaceId))
+	require.NoError(t, modelAuthZ.CanArchiveModel(ctx, grantee, m, m.WorkspaceId))
+	require.NoError(t, modelAuthZ.CanUnarchiveModel(ctx, grantee, m, m.WorkspaceId))
+	require.Error(t, modelAuthZ.CanEditModelWebhooks(ctx, grantee, m, m.WorkspaceId),
+		"grants do not cover webhooks")
+	require.Error(t, modelAuthZ.CanManageModelAccess(ctx, grantee, m, m.WorkspaceId))
+	require.Error(t, modelAuthZ.CanDeleteModel(ctx, grantee, m, m.WorkspaceId))
+
+	require.NoError(t, db.DeleteModelAccessGrant(ctx, m.Id, grantee.ID))
+	require.True(t, authz.IsPermissionDenied(modelAuthZ.CanGetModel(ctx, grantee, m, m.WorkspaceId)),
+		"revoking takes effect immediately")
+	require.Error(t, modelAuthZ.CanEditModel(ctx, grantee, m, m.WorkspaceId))
+	require.Error(t, modelAuthZ.CanArchiveModel(ctx, grantee, m, m.WorkspaceId))
+	require.ErrorIs(t, db.DeleteModelAccessGrant(ctx, m.Id, grantee.ID), db.ErrNotFound)
+}
+
+func TestModelAuthZRBACValidate(t *testing.T) {
+	require.NoError(t, etc.SetRootPath(db.RootFromDB))
+	pgDB := db.MustResolveTestPostgres(t)
+	db.MustMigrateTestPostgres(t, pgDB, db.MigrationsFromDB)
+
+	require.NoError(t, (&ModelAuthZRBAC{}).Validate(context.Background()))
+}
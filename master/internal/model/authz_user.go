@@ -0,0 +1,23 @@
+package model
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// ErrUnauthenticated is the error for model registry requests and RBAC model authz checks that
+// have no authenticated user.
+var ErrUnauthenticated = status.Error(codes.Unauthenticated,
+	"the model registry requires an authenticated user")
+
+// RequireUser returns ErrUnauthenticated if curUser is nil or the zero user. Service accounts
+// are ordinary users with role assignments, so they pass even without a password or any
+// workspace membership.
+func RequireUser(curUser *model.User) error {
+	if curUser == nil || curUser.ID == 0 {
+		return ErrUnauthenticated
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+func TestRequireUser(t *testing.T) {
+	require.Equal(t, codes.Unauthenticated, status.Code(RequireUser(nil)))
+	require.Equal(t, codes.Unauthenticated, status.Code(RequireUser(&model.User{})))
+	require.NoError(t, RequireUser(&model.User{ID: 1, Username: "service-account"}))
+}
+
+func TestRBACZeroUser(t *testing.T) {
+	ctx := context.Background()
+	rbac := &ModelAuthZRBAC{}
+	m := &modelv1.Model{Id: 1, WorkspaceId: 1}
+
+	_, err := rbac.CanGetModels(ctx, model.User{}, nil)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	err = rbac.CanGetModel(ctx, model.User{}, m, m.WorkspaceId)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	err = rbac.CanEditModel(ctx, model.User{}, m, m.WorkspaceId)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = rbac.FilterReadableModelsQuery(ctx, model.User{}, nil)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	allowed, err := (&ModelAuthZBasic{}).CanGetModels(ctx, model.User{}, nil)
+	require.NoError(t, err, "basic allows everyone")
+	require.Nil(t, allowed)
+}
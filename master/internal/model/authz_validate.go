@@ -0,0 +1,52 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+
+	"github.com/determined-ai/determined/master/internal/config"
+)
+
+// ValidateAuthZ checks the model authz implementations selected by the master config and logs
+// which are active. It is called when the master starts, so that a misconfigured authz type
+// fails startup instead of models silently being authorized by the fallback type.
+func ValidateAuthZ(ctx context.Context) error {
+	authZType := config.GetAuthZConfig().Type
+	impl, ok := AuthZProvider.GetType(authZType)
+	if !ok {
+		return fmt.Errorf("model authz provider %q is not registered, must be one of: %s",
+			authZType, strings.Join(AuthZProvider.Types(), ", "))
+	}
+	if err := impl.Validate(ctx); err != nil {
+		return fmt.Errorf("model authz provider %q is not usable: %w", authZType, err)
+	}
+	log.Infof("model authz provider = %s", authZType)
+
+	workspaceAuthZ := config.GetMasterConfig().ModelRegistry.WorkspaceAuthZ
+	workspaceIDs := maps.Keys(workspaceAuthZ)
+	sort.Slice(workspaceIDs, func(i, j int) bool { return workspaceIDs[i] < workspaceIDs[j] })
+	validated := map[string]bool{authZType: true}
+	for _, workspaceID := range workspaceIDs {
+		workspaceType := workspaceAuthZ[workspaceID]
+		impl, ok := AuthZProvider.GetType(workspaceType)
+		if !ok {
+			return fmt.Errorf("model authz provider %q of workspace %d is not registered, "+
+				"must be one of: %s", workspaceType, workspaceID,
+				strings.Join(AuthZProvider.Types(), ", "))
+		}
+		if !validated[workspaceType] {
+			if err := impl.Validate(ctx); err != nil {
+				return fmt.Errorf("model authz provider %q of workspace %d is not usable: %w",
+					workspaceType, workspaceID, err)
+			}
+			validated[workspaceType] = true
+		}
+		log.Infof("model authz provider = %s for workspace %d", workspaceType, workspaceID)
+	}
+	return nil
+}
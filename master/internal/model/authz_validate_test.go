@@ -0,0 +1,55 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/config"
+)
+
+func TestValidateAuthZ(t *testing.T) {
+	hook := test.NewGlobal()
+	defer hook.Reset()
+	ctx := context.Background()
+	masterConfig := config.GetMasterConfig()
+	defer func(authZ config.AuthZConfig, workspaceAuthZ map[int32]string) {
+		masterConfig.Security.AuthZ = authZ
+		masterConfig.ModelRegistry.WorkspaceAuthZ = workspaceAuthZ
+	}(masterConfig.Security.AuthZ, masterConfig.ModelRegistry.WorkspaceAuthZ)
+
+	masterConfig.Security.AuthZ.Type = config.BasicAuthZType
+	masterConfig.ModelRegistry.WorkspaceAuthZ = map[int32]string{4: AuditAuthZType}
+	require.NoError(t, ValidateAuthZ(ctx))
+	var messages []string
+	for _, e := range hook.AllEntries() {
+		messages = append(messages, e.Message)
+	}
+	require.Equal(t, []string{
+		"model authz provider = basic",
+		"model authz provider = audit for workspace 4",
+	}, messages)
+
+	// A type registered for another module only would otherwise fall back silently.
+	masterConfig.Security.AuthZ.Type = "not-registered"
+	err := ValidateAuthZ(ctx)
+	require.ErrorContains(t, err, `model authz provider "not-registered" is not registered`)
+	require.ErrorContains(t, err, "audit, basic, permissive, rbac")
+
+	masterConfig.Security.AuthZ.Type = config.BasicAuthZType
+	masterConfig.ModelRegistry.WorkspaceAuthZ = map[int32]string{4: "not-registered"}
+	require.ErrorContains(t, ValidateAuthZ(ctx),
+		`model authz provider "not-registered" of workspace 4 is not registered`)
+
+	// The audit decorator must wrap a registered type other than itself.
+	masterConfig.ModelRegistry.WorkspaceAuthZ = map[int32]string{4: AuditAuthZType}
+	t.Setenv(AuditWrappedAuthZTypeEnv, "not-registered")
+	require.ErrorContains(t, ValidateAuthZ(ctx),
+		`model authz provider "audit" wraps "not-registered", which is not registered`)
+	t.Setenv(AuditWrappedAuthZTypeEnv, AuditAuthZType)
+	require.ErrorContains(t, ValidateAuthZ(ctx), `model authz provider "audit" can't wrap itself`)
+	t.Setenv(AuditWrappedAuthZTypeEnv, config.BasicAuthZType)
+	require.NoError(t, ValidateAuthZ(ctx))
+}
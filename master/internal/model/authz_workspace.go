@@ -0,0 +1,190 @@
+package model
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uptrace/bun"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// WorkspaceAuthZResolver returns the authz type selected for models in a workspace. ok is
+// false if the workspace should use the cluster-wide authz type.
+type WorkspaceAuthZResolver func(workspaceID int32) (authZType string, ok bool)
+
+// WorkspaceResolver selects the ModelAuthZ used for a workspace. It defaults to reading
+// model_registry.workspace_authz from the master config.
+var WorkspaceResolver WorkspaceAuthZResolver = ConfigWorkspaceAuthZResolver
+
+// ConfigWorkspaceAuthZResolver resolves workspaces using model_registry.workspace_authz.
+func ConfigWorkspaceAuthZResolver(workspaceID int32) (string, bool) {
+	authZType, ok := config.GetMasterConfig().ModelRegistry.WorkspaceAuthZ[workspaceID]
+	return authZType, ok
+}
+
+// ForWorkspace returns the ModelAuthZ for actions on models in workspaceID, falling back to
+// AuthZProvider.Get() if the workspace has no authz type of its own.
+func ForWorkspace(workspaceID int32) ModelAuthZ {
+	authZType, ok := WorkspaceResolver(workspaceID)
+	if !ok {
+		return AuthZProvider.Get()
+	}
+	impl, ok := AuthZProvider.GetType(authZType)
+	if !ok {
+		log.Warnf("authz type %q of workspace %d is not registered, using the default",
+			authZType, workspaceID)
+		return AuthZProvider.Get()
+	}
+	return impl
+}
+
+// ForWorkspaces returns the distinct ModelAuthZ implementations for workspaceIDs, for actions
+// that have to be allowed in several workspaces at once.
+func ForWorkspaces(workspaceIDs ...int32) []ModelAuthZ {
+	// Implementations are deduplicated by resolved type name rather than by value, since
+	// pointers to distinct zero-size structs may compare equal.
+	seen := make(map[string]bool)
+	var impls []ModelAuthZ
+	for _, id := range workspaceIDs {
+		authZType, ok := WorkspaceResolver(id)
+		if ok {
+			if _, registered := AuthZProvider.GetType(authZType); !registered {
+				ok = false
+			}
+		}
+		if !ok {
+			authZType = ""
+		}
+		if seen[authZType] {
+			continue
+		}
+		seen[authZType] = true
+		impls = append(impls, ForWorkspace(id))
+	}
+	return impls
+}
+
+// WorkspaceAuthZGroup is a set of workspaces whose models are authorized by the same ModelAuthZ.
+type WorkspaceAuthZGroup struct {
+	AuthZ ModelAuthZ
+	// WorkspaceIDs are the workspaces in the group. They are nil for the default group, of
+	// AuthZProvider.Get(), which instead holds every workspace that isn't in another group.
+	WorkspaceIDs []int32
+
+	otherIDs []int32
+}
+
+// Contains reports whether workspaceID is in the group.
+func (g WorkspaceAuthZGroup) Contains(workspaceID int32) bool {
+	ids, want := g.WorkspaceIDs, true
+	if ids == nil {
+		ids, want = g.otherIDs, false
+	}
+	for _, id := range ids {
+		if id == workspaceID {
+			return want
+		}
+	}
+	return !want
+}
+
+// Filter returns the workspaceIDs that are in the group.
+func (g WorkspaceAuthZGroup) Filter(workspaceIDs []int32) []int32 {
+	filtered := []int32{}
+	for _, id := range workspaceIDs {
+		if g.Contains(id) {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered
+}
+
+// ApplyWorkspaces restricts query, which selects from models aliased as m, to the models in the
+// workspaces of the group.
+func (g WorkspaceAuthZGroup) ApplyWorkspaces(query *bun.SelectQuery) *bun.SelectQuery {
+	switch {
+	case g.WorkspaceIDs != nil:
+		return query.Where("m.workspace_id IN (?)", bun.In(g.WorkspaceIDs))
+	case len(g.otherIDs) > 0:
+		return query.Where("m.workspace_id NOT IN (?)", bun.In(g.otherIDs))
+	default:
+		return query
+	}
+}
+
+// GroupWorkspacesByAuthZ resolves the ModelAuthZ of every workspace and groups workspaces by it,
+// for listings that span workspaces. The default group always comes first, and is the only one
+// if no workspace has a registered authz type of its own.
+func GroupWorkspacesByAuthZ(ctx context.Context) ([]WorkspaceAuthZGroup, error) {
+	var workspaceIDs []int32
+	if err := db.Bun().NewSelect().Table("workspaces").Column("id").Order("id").
+		Scan(ctx, &workspaceIDs); err != nil {
+		return nil, fmt.Errorf("getting workspaces to resolve model authz: %w", err)
+	}
+
+	groups := []WorkspaceAuthZGroup{{AuthZ: AuthZProvider.Get()}}
+	byType := make(map[string]int)
+	for _, id := range workspaceIDs {
+		authZType, ok := WorkspaceResolver(id)
+		if !ok {
+			continue
+		}
+		impl, ok := AuthZProvider.GetType(authZType)
+		if !ok {
+			// Falls back to the default, as in ForWorkspace.
+			continue
+		}
+		i, ok := byType[authZType]
+		if !ok {
+			i = len(groups)
+			byType[authZType] = i
+			groups = append(groups, WorkspaceAuthZGroup{AuthZ: impl, WorkspaceIDs: []int32{}})
+		}
+		groups[i].WorkspaceIDs = append(groups[i].WorkspaceIDs, id)
+		groups[0].otherIDs = append(groups[0].otherIDs, id)
+	}
+	return groups, nil
+}
+
+// FilterReadableModelsByWorkspace restricts query, which selects from models aliased as m, to
+// the models curUser may view, applying the FilterReadableModelsQuery of the ModelAuthZ of each
+// model's workspace.
+func FilterReadableModelsByWorkspace(
+	ctx context.Context, curUser model.User, query *bun.SelectQuery,
+) (*bun.SelectQuery, error) {
+	groups, err := GroupWorkspacesByAuthZ(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 1 {
+		return groups[0].AuthZ.FilterReadableModelsQuery(ctx, curUser, query)
+	}
+
+	readable := make([]*bun.SelectQuery, 0, len(groups))
+	for _, g := range groups {
+		sub := g.ApplyWorkspaces(db.Bun().NewSelect().TableExpr("models AS m").Column("m.id"))
+		if sub, err = g.AuthZ.FilterReadableModelsQuery(ctx, curUser, sub); err != nil {
+			return nil, err
+		}
+		readable = append(readable, sub)
+	}
+	return WhereModelIn(query, readable), nil
+}
+
+// WhereModelIn restricts query, which selects from models aliased as m, to the models selected
+// by any of subqueries.
+func WhereModelIn(query *bun.SelectQuery, subqueries []*bun.SelectQuery) *bun.SelectQuery {
+	if len(subqueries) == 0 {
+		return query.Where("false")
+	}
+	return query.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+		for _, sub := range subqueries {
+			q = q.WhereOr("m.id IN (?)", sub)
+		}
+		return q
+	})
+}
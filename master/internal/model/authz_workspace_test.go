@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForWorkspace(t *testing.T) {
+	resolver := WorkspaceResolver
+	defer func() { WorkspaceResolver = resolver }()
+	WorkspaceResolver = func(workspaceID int32) (string, bool) {
+		switch workspaceID {
+		case 2:
+			return "permissive", true
+		case 3:
+			return "not-registered", true
+		default:
+			return "", false
+		}
+	}
+
+	require.Equal(t, AuthZProvider.Get(), ForWorkspace(1))
+	require.IsType(t, &ModelAuthZPermissive{}, ForWorkspace(2))
+	require.Equal(t, AuthZProvider.Get(), ForWorkspace(3))
+
+	require.Len(t, ForWorkspaces(1, 1), 1)
+	require.Len(t, ForWorkspaces(1, 3), 1)
+	require.Len(t, ForWorkspaces(1, 2, 2), 2)
+}
+
+func TestWorkspaceAuthZGroup(t *testing.T) {
+	byDefault := WorkspaceAuthZGroup{otherIDs: []int32{2, 3}}
+	own := WorkspaceAuthZGroup{WorkspaceIDs: []int32{2, 3}}
+
+	require.True(t, byDefault.Contains(1))
+	require.False(t, byDefault.Contains(2))
+	require.False(t, own.Contains(1))
+	require.True(t, own.Contains(3))
+
+	require.Equal(t, []int32{1, 4}, byDefault.Filter([]int32{1, 2, 4}))
+	require.Equal(t, []int32{2}, own.Filter([]int32{1, 2, 4}))
+	require.Equal(t, []int32{}, own.Filter([]int32{1}))
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/config"
+)
+
+// Rules that model names are checked against.
+const (
+	BlankNameRule              = "blank"
+	ExcessiveSpacingRule       = "excessive-spacing"
+	SlashesNameRule            = "slashes"
+	OnlyNumbersNameRule        = "only-numbers"
+	NormalizedNameConflictRule = "normalized-name-conflict"
+)
+
+// ModelNamePolicy validates model names before models are created or renamed.
+type ModelNamePolicy interface {
+	// Validate returns a *ModelNameError if name may not be used for a model in the
+	// workspace. Uniqueness is checked separately.
+	Validate(name string, workspaceID int32) error
+}
+
+// NamePolicyProvider is the name policy registry for models.
+var NamePolicyProvider = ProviderType[ModelNamePolicy]{
+	kind:         "model name policy",
+	registerType: config.RegisterModelNamePolicyType,
+	selectedType: func(c *config.ModelRegistryConfig) string { return c.NamePolicy },
+}
+
+// ModelNameError is returned when a model name breaks a name policy rule.
+type ModelNameError struct {
+	Name   string
+	Rule   string
+	Reason string
+}
+
+// NewModelNameError returns a ModelNameError for name breaking rule.
+func NewModelNameError(name, rule, reason string) *ModelNameError {
+	return &ModelNameError{Name: name, Rule: rule, Reason: reason}
+}
+
+func (e *ModelNameError) Error() string {
+	return fmt.Sprintf("invalid model name %q: %s (rule %s)", e.Name, e.Reason, e.Rule)
+}
+
+// GRPCStatus returns the error as an InvalidArgument status.
+func (e *ModelNameError) GRPCStatus() *status.Status {
+	return status.New(codes.InvalidArgument, e.Error())
+}
+
+var onlyNumbers = regexp.MustCompile(`^\d+$`)
+
+// ModelNamePolicyDefault allows any name that can be used to identify a model in the API.
+type ModelNamePolicyDefault struct{}
+
+// Validate checks that the name is not blank, has no repeated spaces or slashes and is not
+// only numbers, which would be taken for a model ID.
+func (p *ModelNamePolicyDefault) Validate(name string, workspaceID int32) error {
+	if len(strings.ReplaceAll(name, " ", "")) == 0 {
+		return NewModelNameError(name, BlankNameRule, "model names cannot be blank")
+	}
+	if strings.Contains(name, "  ") {
+		return NewModelNameError(name, ExcessiveSpacingRule,
+			"model names cannot have excessive spacing")
+	}
+	if strings.Contains(name, "/") || strings.Contains(name, "\\") {
+		return NewModelNameError(name, SlashesNameRule, "model names cannot have slashes")
+	}
+	if onlyNumbers.MatchString(name) {
+		return NewModelNameError(name, OnlyNumbersNameRule, "model names cannot be only numbers")
+	}
+	return nil
+}
+
+func init() {
+	NamePolicyProvider.Register(config.DefaultModelNamePolicyType, &ModelNamePolicyDefault{})
+}
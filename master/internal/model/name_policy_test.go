@@ -0,0 +1,41 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNamePolicyProviderDefault(t *testing.T) {
+	require.IsType(t, &ModelNamePolicyDefault{}, NamePolicyProvider.Get())
+}
+
+func TestModelNamePolicyDefault(t *testing.T) {
+	p := &ModelNamePolicyDefault{}
+	cases := map[string]string{
+		"mnist":       "",
+		"mnist cnn 2": "",
+		"café":        "",
+		"42a":         "",
+		"   ":         BlankNameRule,
+		"mnist  cnn":  ExcessiveSpacingRule,
+		"mnist/cnn":   SlashesNameRule,
+		`mnist\cnn`:   SlashesNameRule,
+		"42":          OnlyNumbersNameRule,
+	}
+	for name, rule := range cases {
+		err := p.Validate(name, 1)
+		if rule == "" {
+			require.NoError(t, err, name)
+			continue
+		}
+		var nameErr *ModelNameError
+		require.True(t, errors.As(err, &nameErr), name)
+		require.Equal(t, rule, nameErr.Rule)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		require.Contains(t, err.Error(), rule)
+	}
+}
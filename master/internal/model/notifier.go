@@ -0,0 +1,72 @@
+package model
+
+import (
+	"context"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+// ModelNotifier is told about model registry changes so that external systems, like CI, can
+// react to them. Methods are only called after the change is committed and authorized, and
+// must not block for longer than the configured notifier timeout.
+type ModelNotifier interface {
+	// POST /api/v1/models
+	ModelCreated(ctx context.Context, m *modelv1.Model) error
+	// POST /api/v1/models/{model_name}/archive
+	ModelArchived(ctx context.Context, m *modelv1.Model) error
+	// POST /api/v1/models/{model_name}/unarchive
+	ModelUnarchived(ctx context.Context, m *modelv1.Model) error
+	// DELETE /api/v1/models/{model_name}
+	ModelDeleted(ctx context.Context, m *modelv1.Model) error
+	// POST /api/v1/models/{model_name}/restore
+	ModelRestored(ctx context.Context, m *modelv1.Model) error
+	// POST /api/v1/models/{model_name}/versions
+	ModelVersionCreated(ctx context.Context, modelVersion *modelv1.ModelVersion) error
+}
+
+// NotifierProvider is the notifier registry for models.
+var NotifierProvider = ProviderType[ModelNotifier]{
+	kind:         "model notifier",
+	registerType: config.RegisterModelNotifierType,
+	selectedType: func(c *config.ModelRegistryConfig) string { return c.Notifier.Type },
+}
+
+// ModelNotifierNoop ignores every event.
+type ModelNotifierNoop struct{}
+
+// ModelCreated does nothing.
+func (n *ModelNotifierNoop) ModelCreated(ctx context.Context, m *modelv1.Model) error {
+	return nil
+}
+
+// ModelArchived does nothing.
+func (n *ModelNotifierNoop) ModelArchived(ctx context.Context, m *modelv1.Model) error {
+	return nil
+}
+
+// ModelUnarchived does nothing.
+func (n *ModelNotifierNoop) ModelUnarchived(ctx context.Context, m *modelv1.Model) error {
+	return nil
+}
+
+// ModelDeleted does nothing.
+func (n *ModelNotifierNoop) ModelDeleted(ctx context.Context, m *modelv1.Model) error {
+	return nil
+}
+
+// ModelRestored does nothing.
+func (n *ModelNotifierNoop) ModelRestored(ctx context.Context, m *modelv1.Model) error {
+	return nil
+}
+
+// ModelVersionCreated does nothing.
+func (n *ModelNotifierNoop) ModelVersionCreated(ctx context.Context,
+	modelVersion *modelv1.ModelVersion,
+) error {
+	return nil
+}
+
+func init() {
+	NotifierProvider.Register(config.NoopModelNotifierType, &ModelNotifierNoop{})
+}
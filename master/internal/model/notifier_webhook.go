@@ -0,0 +1,192 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	back "github.com/cenkalti/backoff/v4"
+	"github.com/hashicorp/go-cleanhttp"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/webhooks"
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+// Model event types sent by ModelNotifierWebhook.
+const (
+	ModelCreatedEvent        = "MODEL_CREATED"
+	ModelArchivedEvent       = "MODEL_ARCHIVED"
+	ModelUnarchivedEvent     = "MODEL_UNARCHIVED"
+	ModelDeletedEvent        = "MODEL_DELETED"
+	ModelRestoredEvent       = "MODEL_RESTORED"
+	ModelVersionCreatedEvent = "MODEL_VERSION_CREATED"
+)
+
+// ModelEventPayload is the JSON body ModelNotifierWebhook posts for each event.
+type ModelEventPayload struct {
+	EventType    string          `json:"event_type"`
+	Timestamp    int64           `json:"timestamp"`
+	Model        json.RawMessage `json:"model,omitempty"`
+	ModelVersion json.RawMessage `json:"model_version,omitempty"`
+}
+
+// ModelNotifierWebhook posts model events as JSON to a URL. Events are queued and delivered in
+// the background, with retries, so a slow or failing endpoint never holds up a request for
+// longer than the configured timeout.
+type ModelNotifierWebhook struct {
+	// cfg is resolved from the master config on first use if nil.
+	cfg           *config.ModelNotifierConfig
+	retryInterval time.Duration
+
+	once   sync.Once
+	queue  chan []byte
+	client *http.Client //nolint:forbidigo
+	log    *log.Entry
+}
+
+// NewModelNotifierWebhook returns a webhook notifier using cfg.
+func NewModelNotifierWebhook(cfg config.ModelNotifierConfig) *ModelNotifierWebhook {
+	return &ModelNotifierWebhook{cfg: &cfg}
+}
+
+func (w *ModelNotifierWebhook) start() {
+	w.once.Do(func() {
+		if w.cfg == nil {
+			cfg := config.GetMasterConfig().ModelRegistry.Notifier
+			w.cfg = &cfg
+		}
+		if w.retryInterval == 0 {
+			w.retryInterval = time.Second
+		}
+		w.queue = make(chan []byte, w.cfg.QueueSize)
+		w.client = cleanhttp.DefaultClient()
+		w.log = log.WithField("component", "model-notifier")
+		go w.run()
+	})
+}
+
+func (w *ModelNotifierWebhook) run() {
+	for payload := range w.queue {
+		bf := back.NewExponentialBackOff()
+		bf.InitialInterval = w.retryInterval
+		bf.MaxInterval = time.Minute
+		if err := back.Retry(func() error {
+			return w.deliver(payload)
+		}, back.WithMaxRetries(bf, uint64(w.cfg.MaxRetries))); err != nil {
+			w.log.WithError(err).Error("failed to deliver model event")
+		}
+	}
+}
+
+func (w *ModelNotifierWebhook) deliver(payload []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	req, err := webhooks.NewSignedRequest(ctx, w.cfg.URL, payload)
+	if err != nil {
+		return back.Permanent(err)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending model event: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			w.log.WithError(err).Warn("failed to close response body")
+		}
+	}()
+
+	switch {
+	case resp.StatusCode >= 500: //nolint: usestdlibvars
+		return fmt.Errorf("model event request returned %v", resp.StatusCode)
+	case resp.StatusCode >= 400: //nolint: usestdlibvars
+		return back.Permanent(fmt.Errorf("model event request returned %v", resp.StatusCode))
+	default:
+		return nil
+	}
+}
+
+// enqueue queues an event, waiting at most the configured timeout for room in the queue.
+func (w *ModelNotifierWebhook) enqueue(
+	ctx context.Context, eventType string, m *modelv1.Model, modelVersion *modelv1.ModelVersion,
+) error {
+	w.start()
+
+	payload := ModelEventPayload{EventType: eventType, Timestamp: time.Now().Unix()}
+	var err error
+	if payload.Model, err = marshalEventMessage(m); err != nil {
+		return err
+	}
+	if payload.ModelVersion, err = marshalEventMessage(modelVersion); err != nil {
+		return err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling %s event: %w", eventType, err)
+	}
+
+	timer := time.NewTimer(time.Duration(w.cfg.Timeout))
+	defer timer.Stop()
+	select {
+	case w.queue <- body:
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("timed out queueing %s event after %s", eventType,
+			time.Duration(w.cfg.Timeout))
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func marshalEventMessage[T proto.Message](msg T) (json.RawMessage, error) {
+	if !msg.ProtoReflect().IsValid() {
+		return nil, nil
+	}
+	b, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling model event: %w", err)
+	}
+	return b, nil
+}
+
+// ModelCreated queues a MODEL_CREATED event.
+func (w *ModelNotifierWebhook) ModelCreated(ctx context.Context, m *modelv1.Model) error {
+	return w.enqueue(ctx, ModelCreatedEvent, m, nil)
+}
+
+// ModelArchived queues a MODEL_ARCHIVED event.
+func (w *ModelNotifierWebhook) ModelArchived(ctx context.Context, m *modelv1.Model) error {
+	return w.enqueue(ctx, ModelArchivedEvent, m, nil)
+}
+
+// ModelUnarchived queues a MODEL_UNARCHIVED event.
+func (w *ModelNotifierWebhook) ModelUnarchived(ctx context.Context, m *modelv1.Model) error {
+	return w.enqueue(ctx, ModelUnarchivedEvent, m, nil)
+}
+
+// ModelDeleted queues a MODEL_DELETED event.
+func (w *ModelNotifierWebhook) ModelDeleted(ctx context.Context, m *modelv1.Model) error {
+	return w.enqueue(ctx, ModelDeletedEvent, m, nil)
+}
+
+// ModelRestored queues a MODEL_RESTORED event.
+func (w *ModelNotifierWebhook) ModelRestored(ctx context.Context, m *modelv1.Model) error {
+	return w.enqueue(ctx, ModelRestoredEvent, m, nil)
+}
+
+// ModelVersionCreated queues a MODEL_VERSION_CREATED event.
+func (w *ModelNotifierWebhook) ModelVersionCreated(ctx context.Context,
+	modelVersion *modelv1.ModelVersion,
+) error {
+	return w.enqueue(ctx, ModelVersionCreatedEvent, modelVersion.GetModel(), modelVersion)
+}
+
+func init() {
+	NotifierProvider.Register(config.WebhookModelNotifierType, &ModelNotifierWebhook{})
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/pkg/model"
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+func TestNotifierProviderDefault(t *testing.T) {
+	require.IsType(t, &ModelNotifierNoop{}, NotifierProvider.Get())
+}
+
+func TestModelNotifierWebhookRetries(t *testing.T) {
+	received := make(chan ModelEventPayload, 1)
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var payload ModelEventPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		received <- payload
+	}))
+	defer server.Close()
+
+	n := NewModelNotifierWebhook(config.ModelNotifierConfig{
+		Type:       config.WebhookModelNotifierType,
+		URL:        server.URL,
+		Timeout:    model.Duration(time.Second),
+		QueueSize:  1,
+		MaxRetries: 2,
+	})
+	n.retryInterval = time.Millisecond
+
+	mv := &modelv1.ModelVersion{Id: 7, Model: &modelv1.Model{Id: 3, Name: "mnist"}}
+	require.NoError(t, n.ModelVersionCreated(context.Background(), mv))
+
+	select {
+	case payload := <-received:
+		require.Equal(t, ModelVersionCreatedEvent, payload.EventType)
+		require.JSONEq(t, `{"id":3,"name":"mnist"}`, string(payload.Model))
+		require.JSONEq(t, `{"id":7,"model":{"id":3,"name":"mnist"}}`, string(payload.ModelVersion))
+	case <-time.After(10 * time.Second):
+		t.Fatal("model event was not delivered")
+	}
+	require.Equal(t, 2, attempts)
+}
+
+func TestModelNotifierWebhookTimeout(t *testing.T) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer server.Close()
+	defer close(block)
+
+	n := NewModelNotifierWebhook(config.ModelNotifierConfig{
+		Type:      config.WebhookModelNotifierType,
+		URL:       server.URL,
+		Timeout:   model.Duration(10 * time.Millisecond),
+		QueueSize: 1,
+	})
+	ctx := context.Background()
+	m := &modelv1.Model{Id: 1}
+
+	// The first event is taken by the sender, which blocks, and the second fills the queue.
+	require.NoError(t, n.ModelCreated(ctx, m))
+	require.Eventually(t, func() bool { return len(n.queue) == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, n.ModelArchived(ctx, m))
+
+	start := time.Now()
+	require.ErrorContains(t, n.ModelDeleted(ctx, m), "timed out")
+	require.Less(t, time.Since(start), time.Second)
+}
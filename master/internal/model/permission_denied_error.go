@@ -0,0 +1,56 @@
+package model
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/authz"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// PermissionDeniedError is returned when a user is denied an action on a model resource.
+// It always converts to a codes.PermissionDenied gRPC status.
+type PermissionDeniedError struct {
+	Username string
+	// Action is the denied verb, e.g. "get" or "delete".
+	Action string
+	// Subject describes what the action was attempted on, e.g. `model "mnist"`.
+	Subject string
+	// Cause is the error returned by the authz implementation.
+	Cause error
+}
+
+// Error returns an error string.
+func (e PermissionDeniedError) Error() string {
+	msg := fmt.Sprintf("user %q cannot %s %s", e.Username, e.Action, e.Subject)
+	if e.Cause != nil {
+		msg = fmt.Sprintf("%s: %s", msg, e.Cause)
+	}
+	return msg
+}
+
+// Unwrap returns the authz error that caused the denial.
+func (e PermissionDeniedError) Unwrap() error {
+	return e.Cause
+}
+
+// GRPCStatus returns the gRPC status of the error.
+func (e PermissionDeniedError) GRPCStatus() *status.Status {
+	return status.New(codes.PermissionDenied, e.Error())
+}
+
+// PermissionDenied converts an authz denial into a PermissionDeniedError for the given action
+// and subject. Any other error, including nil, is returned unchanged.
+func PermissionDenied(err error, curUser model.User, action string, subject string) error {
+	if !authz.IsPermissionDenied(err) {
+		return err
+	}
+	return PermissionDeniedError{
+		Username: curUser.Username,
+		Action:   action,
+		Subject:  subject,
+		Cause:    err,
+	}
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/authz"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestPermissionDenied(t *testing.T) {
+	curUser := model.User{Username: "alice"}
+
+	require.NoError(t, PermissionDenied(nil, curUser, "get", `model "m"`))
+
+	serverErr := errors.New("connection reset")
+	require.Equal(t, serverErr, PermissionDenied(serverErr, curUser, "get", `model "m"`))
+
+	denied := authz.PermissionDeniedError{}
+	err := PermissionDenied(denied, curUser, "get", `model "m"`)
+	require.Equal(t, `user "alice" cannot get model "m": access denied`, err.Error())
+	require.True(t, authz.IsPermissionDenied(err))
+	var cause authz.PermissionDeniedError
+	require.ErrorAs(t, err, &cause)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
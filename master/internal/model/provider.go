@@ -0,0 +1,50 @@
+package model
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/determined-ai/determined/master/internal/config"
+)
+
+// ProviderType is a registry for a model registry extension point, like notifiers, whose
+// implementation is selected by name in the model_registry master config.
+type ProviderType[T any] struct {
+	// kind names the extension point in errors.
+	kind         string
+	registerType func(string)
+	selectedType func(*config.ModelRegistryConfig) string
+
+	mu       sync.Mutex
+	registry map[string]T
+}
+
+// Register adds new implementation.
+func (p *ProviderType[T]) Register(name string, impl T) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.registry == nil {
+		p.registry = make(map[string]T)
+	}
+	p.registerType(name)
+	if _, ok := p.registry[name]; ok {
+		panic(fmt.Errorf("can't do double register of %s type %s", p.kind, name))
+	}
+	p.registry[name] = impl
+}
+
+// Get returns the implementation selected by the master config.
+func (p *ProviderType[T]) Get() T {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	name := p.selectedType(&config.GetMasterConfig().ModelRegistry)
+	res, ok := p.registry[name]
+	if !ok {
+		panic(fmt.Errorf("failed to find %s type %s in %v", p.kind, name, maps.Keys(p.registry)))
+	}
+	return res
+}
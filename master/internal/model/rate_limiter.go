@@ -0,0 +1,169 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+// ModelRateLimiter limits how often each user may take model registry actions. It is consulted
+// once the user is authorized for the action, so that requests that are turned away anyway do
+// not use up the budget.
+type ModelRateLimiter interface {
+	// Allow returns a *ModelRateLimitError if curUser may not take action, one of the
+	// config.ModelRateLimit actions, right now.
+	Allow(ctx context.Context, curUser model.User, action string) error
+}
+
+// RateLimiterProvider is the rate limiter registry for models.
+var RateLimiterProvider = ProviderType[ModelRateLimiter]{
+	kind:         "model rate limiter",
+	registerType: config.RegisterModelRateLimiterType,
+	selectedType: func(c *config.ModelRegistryConfig) string { return c.RateLimiter.Type },
+}
+
+// ModelRateLimitError is returned when a user takes an action more often than it is limited to.
+type ModelRateLimitError struct {
+	Action     string
+	RetryAfter time.Duration
+}
+
+func (e *ModelRateLimitError) Error() string {
+	return fmt.Sprintf("rate limit of model registry %s requests exceeded, retry after %s",
+		e.Action, e.RetryAfter.Round(time.Millisecond))
+}
+
+// GRPCStatus returns the error as a ResourceExhausted status, with when to retry as its
+// RetryInfo.
+func (e *ModelRateLimitError) GRPCStatus() *status.Status {
+	s := status.New(codes.ResourceExhausted, e.Error())
+	if d, err := s.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(e.RetryAfter),
+	}); err == nil {
+		return d
+	}
+	return s
+}
+
+// ModelRateLimiterNoop allows every action.
+type ModelRateLimiterNoop struct{}
+
+// Allow always allows the action.
+func (l *ModelRateLimiterNoop) Allow(ctx context.Context, curUser model.User, action string) error {
+	return nil
+}
+
+// modelRateLimiterSweepInterval is how often ModelRateLimiterTokenBucket drops the buckets of
+// users who have not made requests for long enough that they are full again.
+const modelRateLimiterSweepInterval = 10 * time.Minute
+
+type modelRateLimitKey struct {
+	userID model.UserID
+	action string
+}
+
+type modelRateLimitBucket struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// ModelRateLimiterTokenBucket gives each user a token bucket per action, with the rate and
+// burst of the action's configured limit.
+type ModelRateLimiterTokenBucket struct {
+	// limits is resolved from the master config on each call if nil.
+	limits map[string]config.ModelRateLimit
+	now    func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[modelRateLimitKey]*modelRateLimitBucket
+	lastSweep time.Time
+}
+
+// NewModelRateLimiterTokenBucket returns a token bucket rate limiter using limits.
+func NewModelRateLimiterTokenBucket(
+	limits map[string]config.ModelRateLimit,
+) *ModelRateLimiterTokenBucket {
+	return &ModelRateLimiterTokenBucket{limits: limits}
+}
+
+// Allow takes a token from the bucket of curUser for action, if action is limited.
+func (l *ModelRateLimiterTokenBucket) Allow(
+	ctx context.Context, curUser model.User, action string,
+) error {
+	limits := l.limits
+	if limits == nil {
+		limits = config.GetMasterConfig().ModelRegistry.RateLimiter.Limits
+	}
+	limit, ok := limits[action]
+	if !ok {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if l.now != nil {
+		now = l.now()
+	}
+	if l.buckets == nil {
+		l.buckets = make(map[modelRateLimitKey]*modelRateLimitBucket)
+		l.lastSweep = now
+	}
+	if now.Sub(l.lastSweep) >= modelRateLimiterSweepInterval {
+		l.sweep(now, limits)
+	}
+
+	key := modelRateLimitKey{userID: curUser.ID, action: action}
+	b, ok := l.buckets[key]
+	if !ok || b.limiter.Limit() != rate.Limit(limit.RequestsPerSecond) ||
+		b.limiter.Burst() != limit.Burst {
+		b = &modelRateLimitBucket{
+			limiter: rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), limit.Burst),
+		}
+		l.buckets[key] = b
+	}
+	b.lastUsed = now
+
+	r := b.limiter.ReserveN(now, 1)
+	if !r.OK() {
+		return &ModelRateLimitError{Action: action, RetryAfter: time.Second}
+	}
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return &ModelRateLimitError{Action: action, RetryAfter: delay}
+	}
+	return nil
+}
+
+// sweep drops the buckets that have refilled since they were last used, which behave like new
+// ones.
+func (l *ModelRateLimiterTokenBucket) sweep(
+	now time.Time, limits map[string]config.ModelRateLimit,
+) {
+	for key, b := range l.buckets {
+		refill := time.Duration(math.Ceil(float64(b.limiter.Burst()) /
+			float64(b.limiter.Limit()) * float64(time.Second)))
+		if _, ok := limits[key.action]; !ok || now.Sub(b.lastUsed) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+func init() {
+	RateLimiterProvider.Register(config.NoopModelRateLimiterType, &ModelRateLimiterNoop{})
+	RateLimiterProvider.Register(config.TokenBucketModelRateLimiterType,
+		&ModelRateLimiterTokenBucket{})
+}
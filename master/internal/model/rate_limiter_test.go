@@ -0,0 +1,89 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestRateLimiterProviderDefault(t *testing.T) {
+	require.IsType(t, &ModelRateLimiterNoop{}, RateLimiterProvider.Get())
+}
+
+func TestModelRateLimiterTokenBucket(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	l := NewModelRateLimiterTokenBucket(map[string]config.ModelRateLimit{
+		config.ModelRateLimitGet:    {RequestsPerSecond: 1, Burst: 2},
+		config.ModelRateLimitCreate: {RequestsPerSecond: 0.5, Burst: 1},
+	})
+	l.now = func() time.Time { return now }
+	alice := model.User{ID: 1}
+	bob := model.User{ID: 2}
+
+	require.NoError(t, l.Allow(ctx, alice, config.ModelRateLimitGet))
+	require.NoError(t, l.Allow(ctx, alice, config.ModelRateLimitGet))
+	err := l.Allow(ctx, alice, config.ModelRateLimitGet)
+	var limitErr *ModelRateLimitError
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, config.ModelRateLimitGet, limitErr.Action)
+	require.Equal(t, time.Second, limitErr.RetryAfter)
+
+	// Reads and writes have their own budgets, as do users.
+	require.NoError(t, l.Allow(ctx, alice, config.ModelRateLimitCreate))
+	require.Error(t, l.Allow(ctx, alice, config.ModelRateLimitCreate))
+	require.NoError(t, l.Allow(ctx, bob, config.ModelRateLimitGet))
+	require.NoError(t, l.Allow(ctx, bob, config.ModelRateLimitCreate))
+
+	// Actions without a limit are never limited.
+	for i := 0; i < 10; i++ {
+		require.NoError(t, l.Allow(ctx, alice, config.ModelRateLimitDelete))
+	}
+
+	// Rejected requests do not use up the budget.
+	now = now.Add(time.Second)
+	require.NoError(t, l.Allow(ctx, alice, config.ModelRateLimitGet))
+	require.Error(t, l.Allow(ctx, alice, config.ModelRateLimitGet))
+	now = now.Add(time.Second)
+	require.NoError(t, l.Allow(ctx, alice, config.ModelRateLimitCreate))
+}
+
+func TestModelRateLimiterTokenBucketSweep(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	l := NewModelRateLimiterTokenBucket(map[string]config.ModelRateLimit{
+		config.ModelRateLimitGet: {RequestsPerSecond: 1, Burst: 1},
+	})
+	l.now = func() time.Time { return now }
+	for id := model.UserID(1); id <= 3; id++ {
+		require.NoError(t, l.Allow(ctx, model.User{ID: id}, config.ModelRateLimitGet))
+	}
+	require.Len(t, l.buckets, 3)
+
+	now = now.Add(modelRateLimiterSweepInterval)
+	require.NoError(t, l.Allow(ctx, model.User{ID: 1}, config.ModelRateLimitGet))
+	require.Len(t, l.buckets, 1)
+}
+
+func TestModelRateLimitErrorStatus(t *testing.T) {
+	err := &ModelRateLimitError{
+		Action:     config.ModelRateLimitCreate,
+		RetryAfter: 1500 * time.Millisecond,
+	}
+	s := status.Convert(err)
+	require.Equal(t, codes.ResourceExhausted, s.Code())
+	require.Contains(t, s.Message(), "retry after 1.5s")
+	require.Len(t, s.Details(), 1)
+	info, ok := s.Details()[0].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	require.Equal(t, 1500*time.Millisecond, info.RetryDelay.AsDuration())
+}
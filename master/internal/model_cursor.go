@@ -0,0 +1,336 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/determined-ai/determined/master/internal/config"
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/proto/pkg/apiv1"
+)
+
+// modelSortKey is how GetModels orders models: by expr, then by id to break ties.
+type modelSortKey struct {
+	// expr is the SQL expression models are sorted by, over models aliased as m and their
+	// workspaces aliased as w. It is empty when models are sorted by id alone.
+	expr string
+	args []any
+	// sqlType is the type the key is compared as when paginating by cursor.
+	sqlType string
+	desc    bool
+	idDesc  bool
+	// nullsLast puts NULL keys last when descending too, instead of first.
+	nullsLast bool
+	// ranked is set when models are ordered by how well they match a search.
+	ranked bool
+}
+
+// modelSortKeyFor returns the ordering requested by req.
+func modelSortKeyFor(req *apiv1.GetModelsRequest) (modelSortKey, error) {
+	key := modelSortKey{
+		desc:   req.OrderBy == apiv1.OrderBy_ORDER_BY_DESC,
+		idDesc: req.OrderBy == apiv1.OrderBy_ORDER_BY_DESC,
+	}
+	switch req.SortBy {
+	case apiv1.GetModelsRequest_SORT_BY_UNSPECIFIED:
+		if search := strings.TrimSpace(req.Search); search != "" {
+			// Most relevant first.
+			key.expr = "ts_rank(model_search_vector(m.name, m.description, m.labels), " +
+				"plainto_tsquery('simple', ?))"
+			key.args = []any{search}
+			key.sqlType = "real"
+			key.desc = true
+			key.ranked = true
+		}
+	case apiv1.GetModelsRequest_SORT_BY_NAME:
+		key.expr, key.sqlType = "m.name", "text"
+	case apiv1.GetModelsRequest_SORT_BY_DESCRIPTION:
+		key.expr, key.sqlType = "m.description", "text"
+	case apiv1.GetModelsRequest_SORT_BY_CREATION_TIME:
+		key.expr, key.sqlType = "m.creation_time", "timestamptz"
+	case apiv1.GetModelsRequest_SORT_BY_LAST_UPDATED_TIME:
+		key.expr, key.sqlType = "m.last_updated_time", "timestamptz"
+	case apiv1.GetModelsRequest_SORT_BY_NUM_VERSIONS:
+		key.expr = "(SELECT COUNT(*) FROM model_versions AS mv WHERE mv.model_id = m.id)"
+		key.sqlType = "bigint"
+	case apiv1.GetModelsRequest_SORT_BY_WORKSPACE:
+		key.expr, key.sqlType = "w.name", "text"
+	case apiv1.GetModelsRequest_SORT_BY_METADATA:
+		if req.SortByMetadataKey == "" {
+			return modelSortKey{}, status.Error(codes.InvalidArgument,
+				"sort_by_metadata_key is required to sort by metadata")
+		}
+		// jsonb orders numbers numerically and strings as text. A JSON null counts as missing.
+		key.expr = "NULLIF(m.metadata -> ?, 'null'::jsonb)"
+		key.args = []any{req.SortByMetadataKey}
+		key.sqlType = "jsonb"
+		key.nullsLast = true
+	default:
+		return modelSortKey{}, fmt.Errorf("unsupported sort by %s", req.SortBy)
+	}
+	return key, nil
+}
+
+func sqlDirection(desc bool) string {
+	if desc {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// validateModelSortMetadataKey checks that models may be sorted by a metadata key. The key
+// must be stored in the metadata of some model, and must not be a restricted key, since the
+// order would give away values that are masked.
+func validateModelSortMetadataKey(ctx context.Context, key string) error {
+	if slices.Contains(config.GetMasterConfig().ModelRegistry.RestrictedMetadataKeys, key) {
+		return status.Errorf(codes.InvalidArgument,
+			"models cannot be sorted by restricted metadata key %q", key)
+	}
+	exists, err := db.ModelMetadataKeyExists(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return status.Errorf(codes.InvalidArgument, "no model has metadata key %q", key)
+	}
+	return nil
+}
+
+// order sorts query by the key.
+func (k modelSortKey) order(query *bun.SelectQuery) *bun.SelectQuery {
+	if k.expr != "" {
+		dir := sqlDirection(k.desc)
+		if k.nullsLast {
+			dir += " NULLS LAST"
+		}
+		query = query.OrderExpr(k.expr+" "+dir, k.args...)
+	}
+	return query.OrderExpr("m.id " + sqlDirection(k.idDesc))
+}
+
+// textExpr returns the key as text, in a form that casting back to sqlType restores exactly.
+func (k modelSortKey) textExpr() string {
+	if k.sqlType == "real" {
+		return "(" + k.expr + ")::float8::text"
+	}
+	return "(" + k.expr + ")::text"
+}
+
+// afterCursor returns a condition, and its arguments, selecting the models that sort after the
+// one at the cursor. Like the ORDER BY, it puts NULL keys last when ascending and first when
+// descending, unless nullsLast is set.
+func (k modelSortKey) afterCursor(c *modelCursor) (string, []any) {
+	idOp := ">"
+	if k.idDesc {
+		idOp = "<"
+	}
+	idCond := "m.id " + idOp + " ?"
+	if k.expr == "" {
+		return idCond, []any{c.ID}
+	}
+
+	key := "(" + k.expr + ")"
+	value := "?::" + k.sqlType
+	keyOp := ">"
+	if k.desc {
+		keyOp = "<"
+	}
+	nullsLast := !k.desc || k.nullsLast
+	var args []any
+	withKey := func(n int) {
+		for i := 0; i < n; i++ {
+			args = append(args, k.args...)
+		}
+	}
+	switch {
+	case c.Key == nil && nullsLast:
+		withKey(1)
+		args = append(args, c.ID)
+		return key + " IS NULL AND " + idCond, args
+	case c.Key == nil:
+		withKey(2)
+		args = append(args, c.ID)
+		return key + " IS NOT NULL OR (" + key + " IS NULL AND " + idCond + ")", args
+	case nullsLast:
+		withKey(1)
+		args = append(args, *c.Key)
+		withKey(1)
+		args = append(args, *c.Key, c.ID)
+		withKey(1)
+		return key + " " + keyOp + " " + value + " OR (" + key + " = " + value + " AND " +
+			idCond + ") OR " + key + " IS NULL", args
+	default:
+		withKey(1)
+		args = append(args, *c.Key)
+		withKey(1)
+		args = append(args, *c.Key, c.ID)
+		return key + " " + keyOp + " " + value + " OR (" + key + " = " + value + " AND " +
+			idCond + ")", args
+	}
+}
+
+// modelCursor is the position after the last model of a page of GetModels. It records the sort
+// key of that model, not just its id, so the next page starts from the same place even if the
+// model or ones before it are archived or deleted in the meantime.
+type modelCursor struct {
+	SortBy  apiv1.GetModelsRequest_SortBy `json:"s"`
+	OrderBy apiv1.OrderBy                 `json:"o"`
+	Ranked  bool                          `json:"r,omitempty"`
+	// MetadataKey is the metadata key models were sorted by, if any.
+	MetadataKey string `json:"m,omitempty"`
+	// Key is the key the model was sorted by, as text, or nil if it is NULL.
+	Key *string `json:"k,omitempty"`
+	ID  int32   `json:"i"`
+}
+
+// modelCursorMetadataKey returns the metadata key recorded in the cursors of req.
+func modelCursorMetadataKey(req *apiv1.GetModelsRequest) string {
+	if req.SortBy != apiv1.GetModelsRequest_SORT_BY_METADATA {
+		return ""
+	}
+	return req.SortByMetadataKey
+}
+
+func encodeModelCursor(c modelCursor) (string, error) {
+	bs, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bs), nil
+}
+
+func decodeModelCursor(cursor string) (*modelCursor, error) {
+	bs, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid cursor %q", cursor)
+	}
+	var c modelCursor
+	if err := json.Unmarshal(bs, &c); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid cursor %q", cursor)
+	}
+	return &c, nil
+}
+
+// parseModelCursor returns the position that the models of req start after, or nil if they start
+// from the first model. The cursor must have been returned for models in the same order.
+func parseModelCursor(req *apiv1.GetModelsRequest, key modelSortKey) (*modelCursor, error) {
+	if req.Cursor == nil || *req.Cursor == "" {
+		return nil, nil
+	}
+	c, err := decodeModelCursor(*req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	if c.SortBy != req.SortBy || c.OrderBy != req.OrderBy || c.Ranked != key.ranked ||
+		c.MetadataKey != modelCursorMetadataKey(req) {
+		return nil, status.Error(codes.InvalidArgument,
+			"cursor was returned for a different sort_by, sort_by_metadata_key or order_by")
+	}
+	return c, nil
+}
+
+// modelCursorAt returns the position after the model with the given id when the models of req
+// are sorted by key, reading the sort key of the model in idb.
+func modelCursorAt(ctx context.Context, idb bun.IDB, req *apiv1.GetModelsRequest,
+	key modelSortKey, id int32,
+) (*modelCursor, error) {
+	c := &modelCursor{
+		SortBy:      req.SortBy,
+		OrderBy:     req.OrderBy,
+		Ranked:      key.ranked,
+		MetadataKey: modelCursorMetadataKey(req),
+		ID:          id,
+	}
+	if key.expr != "" {
+		if err := idb.NewSelect().
+			TableExpr("models AS m").
+			Join("LEFT JOIN workspaces AS w ON w.id = m.workspace_id").
+			ColumnExpr(key.textExpr(), key.args...).
+			Where("m.id = ?", id).
+			Scan(ctx, &c.Key); err != nil {
+			return nil, fmt.Errorf("getting the sort key of model %d: %w", id, err)
+		}
+	}
+	return c, nil
+}
+
+// modelsAfterCursor is a condition selecting the models that sort after a cursor by key, or all
+// models when the cursor is nil. It is formatted when its query runs, so that moving the cursor
+// moves where the next run of the query starts.
+type modelsAfterCursor struct {
+	key   modelSortKey
+	after *modelCursor
+}
+
+// AppendQuery implements schema.QueryAppender.
+func (c *modelsAfterCursor) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	if c.after == nil {
+		return append(b, "TRUE"...), nil
+	}
+	cond, args := c.key.afterCursor(c.after)
+	return fmter.AppendQuery(b, cond, args...), nil
+}
+
+// getModelsPage scans the page of query that follows req.Cursor into resp, along with the cursor
+// of the page after it. The page and the key of its last model are read from one snapshot.
+func getModelsPage(ctx context.Context, query *bun.SelectQuery, req *apiv1.GetModelsRequest,
+	key modelSortKey, resp *apiv1.GetModelsResponse,
+) error {
+	if req.Offset != 0 {
+		return status.Error(codes.InvalidArgument, "offset cannot be used with cursor")
+	}
+	if req.Limit < 0 {
+		return status.Error(codes.InvalidArgument, "limit cannot be negative with cursor")
+	}
+	after, err := parseModelCursor(req, key)
+	if err != nil {
+		return err
+	}
+
+	return db.Bun().RunInTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true},
+		func(ctx context.Context, tx bun.Tx) error {
+			query = query.Conn(tx)
+			total, err := query.Count(ctx)
+			if err != nil {
+				return err
+			}
+			if after != nil {
+				cond, args := key.afterCursor(after)
+				query = query.Where(cond, args...)
+			}
+			if req.Limit > 0 {
+				// One more model tells whether there is a next page.
+				query = query.Limit(int(req.Limit) + 1)
+			}
+			if err := query.Scan(ctx); err != nil {
+				return err
+			}
+
+			if req.Limit > 0 && len(resp.Models) > int(req.Limit) {
+				resp.Models = resp.Models[:req.Limit]
+				next, err := modelCursorAt(ctx, tx, req, key, resp.Models[len(resp.Models)-1].Id)
+				if err != nil {
+					return err
+				}
+				if resp.NextCursor, err = encodeModelCursor(*next); err != nil {
+					return err
+				}
+			}
+			resp.Pagination = &apiv1.Pagination{
+				Limit:    req.Limit,
+				EndIndex: int32(len(resp.Models)),
+				Total:    int32(total),
+			}
+			return nil
+		})
+}
@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	back "github.com/cenkalti/backoff/v4"
+	"github.com/hashicorp/go-cleanhttp"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	modelauth "github.com/determined-ai/determined/master/internal/model"
+	"github.com/determined-ai/determined/master/internal/webhooks"
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+// Model webhook events are queued in model_webhook_deliveries, which is also their delivery log.
+const (
+	modelWebhookBatchSize      = 10
+	modelWebhookMaxAttempts    = 6
+	modelWebhookRetryInterval  = 10 * time.Second
+	modelWebhookMaxRetryDelay  = time.Hour
+	modelWebhookPollInterval   = 30 * time.Second
+	modelWebhookLease          = 5 * time.Minute
+	modelWebhookRequestTimeout = 30 * time.Second
+)
+
+var modelWebhookWake = make(chan struct{}, 1)
+
+// fireModelWebhooks queues event for the webhooks of the model of modelVersion. It must only be
+// called after the change it reports is authorized and committed.
+func fireModelWebhooks(
+	ctx context.Context, event string, modelVersion *modelv1.ModelVersion,
+) error {
+	m := modelVersion.GetModel()
+	payload := modelauth.ModelEventPayload{EventType: event, Timestamp: time.Now().Unix()}
+	var err error
+	if payload.Model, err = protojson.Marshal(m); err != nil {
+		return fmt.Errorf("marshaling %s event: %w", event, err)
+	}
+	if payload.ModelVersion, err = protojson.Marshal(modelVersion); err != nil {
+		return fmt.Errorf("marshaling %s event: %w", event, err)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling %s event: %w", event, err)
+	}
+
+	queued, err := db.EnqueueModelWebhookEvent(ctx, m.Id, event, body)
+	if err != nil {
+		return err
+	}
+	if queued > 0 {
+		select {
+		case modelWebhookWake <- struct{}{}:
+		default:
+			// A wake is already pending and will pick up this event too.
+		}
+	}
+	return nil
+}
+
+// deliverModelWebhooks delivers queued model webhook events until ctx is canceled. Failed
+// deliveries are retried with exponential backoff, up to modelWebhookMaxAttempts attempts.
+func deliverModelWebhooks(ctx context.Context) {
+	client := cleanhttp.DefaultClient()
+	logger := log.WithField("component", "model-webhooks")
+	t := time.NewTicker(modelWebhookPollInterval)
+	defer t.Stop()
+	for {
+		if err := deliverDueModelWebhooks(ctx, client); err != nil && ctx.Err() == nil {
+			logger.WithError(err).Error("error delivering model webhooks")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		case <-modelWebhookWake:
+		}
+	}
+}
+
+// deliverDueModelWebhooks attempts every delivery that is due, a batch at a time.
+func deliverDueModelWebhooks(
+	ctx context.Context, client *http.Client, //nolint:forbidigo
+) error {
+	for {
+		attempts, err := db.ClaimModelWebhookDeliveries(ctx, modelWebhookBatchSize,
+			modelWebhookLease)
+		if err != nil {
+			return err
+		}
+		if len(attempts) == 0 {
+			return nil
+		}
+
+		var wg sync.WaitGroup
+		errs := make([]error, len(attempts))
+		for i, a := range attempts {
+			wg.Add(1)
+			go func(i int, a *db.ModelWebhookAttempt) {
+				defer wg.Done()
+				attemptErr := deliverModelWebhook(ctx, client, a)
+				if ctx.Err() != nil {
+					// Shutting down; the lease runs out and the delivery is attempted again.
+					return
+				}
+				errs[i] = db.FinishModelWebhookAttempt(ctx, a.ID, attemptErr,
+					modelWebhookRetryAt(a.Attempts+1, attemptErr))
+			}(i, a)
+		}
+		wg.Wait()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+	}
+}
+
+// modelWebhookRetryAt returns when to retry a delivery after its attempts-th attempt failed
+// with attemptErr, or nil if it should not be retried.
+func modelWebhookRetryAt(attempts int32, attemptErr error) *time.Time {
+	var permanent *back.PermanentError
+	if attemptErr == nil || errors.As(attemptErr, &permanent) ||
+		attempts >= modelWebhookMaxAttempts {
+		return nil
+	}
+	retryAt := time.Now().Add(modelWebhookRetryDelay(attempts))
+	return &retryAt
+}
+
+// modelWebhookRetryDelay doubles the wait after each failed attempt, up to
+// modelWebhookMaxRetryDelay.
+func modelWebhookRetryDelay(attempts int32) time.Duration {
+	delay := modelWebhookRetryInterval
+	for i := int32(1); i < attempts && delay < modelWebhookMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, modelWebhookMaxRetryDelay)
+}
+
+// deliverModelWebhook posts a delivery once. Errors that retrying cannot fix are wrapped with
+// back.Permanent.
+func deliverModelWebhook(
+	ctx context.Context, client *http.Client, a *db.ModelWebhookAttempt, //nolint:forbidigo
+) error {
+	ctx, cancel := context.WithTimeout(ctx, modelWebhookRequestTimeout)
+	defer cancel()
+	var req *http.Request
+	var err error
+	if a.Secret != "" {
+		req, err = webhooks.NewRequestSignedWith(ctx, a.URL, a.Payload, []byte(a.Secret))
+	} else {
+		req, err = webhooks.NewSignedRequest(ctx, a.URL, a.Payload)
+	}
+	if err != nil {
+		return back.Permanent(err)
+	}
+	req.Header.Add("X-Determined-AI-Model-Webhook-Delivery", fmt.Sprint(a.ID))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending %s event: %w", a.Event, err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.WithError(err).Warn("failed to close model webhook response body")
+		}
+	}()
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("%s event request returned %v", a.Event, resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return back.Permanent(
+			fmt.Errorf("%s event request returned %v", a.Event, resp.StatusCode))
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/master/internal/db"
+	"github.com/determined-ai/determined/master/pkg/model"
+)
+
+func TestModelWebhookRetryAt(t *testing.T) {
+	require.Equal(t, 10*time.Second, modelWebhookRetryDelay(1))
+	require.Equal(t, 40*time.Second, modelWebhookRetryDelay(3))
+	require.Equal(t, time.Hour, modelWebhookRetryDelay(30))
+
+	failed := fmt.Errorf("request returned 503")
+	require.Nil(t, modelWebhookRetryAt(1, nil), "successful deliveries are not retried")
+	require.NotNil(t, modelWebhookRetryAt(1, failed))
+	require.Nil(t, modelWebhookRetryAt(modelWebhookMaxAttempts, failed),
+		"deliveries are given up on after the last attempt")
+}
+
+func TestDeliverModelWebhook(t *testing.T) {
+	const secret = "s3cret"
+	var code int
+	var body []byte
+	var signature, timestamp string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get("X-Determined-AI-Signature")
+		timestamp = r.Header.Get("X-Determined-AI-Signature-Timestamp")
+		w.WriteHeader(code)
+	}))
+	defer srv.Close()
+
+	attempt := &db.ModelWebhookAttempt{
+		ModelWebhookDelivery: model.ModelWebhookDelivery{
+			ID: 1, Event: model.ModelWebhookVersionCreated, Payload: []byte(`{"a":1}`),
+		},
+		URL:    srv.URL,
+		Secret: secret,
+	}
+	client := cleanhttp.DefaultClient()
+
+	code = http.StatusOK
+	require.NoError(t, deliverModelWebhook(context.Background(), client, attempt))
+	require.Equal(t, `{"a":1}`, string(body))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "," + string(body)))
+	require.Equal(t, hex.EncodeToString(mac.Sum(nil)), signature,
+		"deliveries are signed with the secret of the webhook")
+
+	code = http.StatusServiceUnavailable
+	err := deliverModelWebhook(context.Background(), client, attempt)
+	require.Error(t, err)
+	require.NotNil(t, modelWebhookRetryAt(1, err), "server errors are retried")
+
+	code = http.StatusNotFound
+	err = deliverModelWebhook(context.Background(), client, attempt)
+	require.Error(t, err)
+	require.Nil(t, modelWebhookRetryAt(1, err), "client errors are not retried")
+}
@@ -99,6 +99,7 @@ func createFilteredModelVersionIDQuery(
 	q := db.Bun().NewSelect().
 		TableExpr("model_versions m").
 		Column("m.id").
+		Where("NOT EXISTS (SELECT 1 FROM models dm WHERE dm.id = m.model_id AND dm.deleted_at IS NOT NULL)").
 		OrderExpr("m.id ASC")
 
 	// add permission scope filter in event of non-global access
@@ -42,9 +42,11 @@ type ModelMsg struct {
 	Notes           string    `bun:"notes" json:"notes"`
 	WorkspaceID     int       `bun:"workspace_id" json:"workspace_id"`
 	UserID          int       `bun:"user_id" json:"user_id"`
+	OwnerID         int       `bun:"owner_id" json:"owner_id"`
 	LastUpdatedTime time.Time `bun:"last_updated_time" json:"last_updated_time"`
 	Metadata        JSONB     `bun:"metadata,type:jsonb" json:"metadata"`
 	Labels          []string  `bun:"labels,array" json:"labels"`
+	Tags            JSONB     `bun:"tags,type:jsonb,nullzero" json:"tags"`
 
 	// metadata
 	Seq int64 `bun:"seq" json:"seq"`
@@ -93,6 +95,7 @@ func createFilteredModelIDQuery(
 	q := db.Bun().NewSelect().
 		TableExpr("models m").
 		Column("m.id").
+		Where("m.deleted_at IS NULL").
 		OrderExpr("m.id ASC")
 
 	// add permission scope filter in event of non-global access
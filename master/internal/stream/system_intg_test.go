@@ -80,7 +80,8 @@ func initializeStreamDB(ctx context.Context, t *testing.T) *db.PgDB {
 	_, err := db.Bun().NewRaw(
 		`INSERT INTO workspaces (name) VALUES ('test_workspace');
 		INSERT INTO projects (name, workspace_id) VALUES ('test_project_1', 2);
-		INSERT INTO models (name, workspace_id, creation_time, user_id) VALUES ('test_model_1', 2, NOW(), 1);
+		INSERT INTO models (name, workspace_id, creation_time, user_id, owner_id)
+		VALUES ('test_model_1', 2, NOW(), 1, 1);
 		INSERT INTO model_versions (name, version, model_id, creation_time, user_id, checkpoint_uuid) 
 		VALUES ('test_model_version_1',1, 1, NOW(), 1, 
 		uuid_in(md5(random()::text || random()::text)::cstring));
@@ -528,6 +529,7 @@ func TestMultipleSubscriptions(t *testing.T) {
 		CreationTime: time.Now(),
 		WorkspaceID:  2,
 		UserID:       1,
+		OwnerID:      1,
 	}
 	testModelVersion := ModelVersionMsg{
 		ID:             2,
@@ -590,6 +592,7 @@ func TestSubscribeByUserID(t *testing.T) {
 		CreationTime: time.Now(),
 		WorkspaceID:  2,
 		UserID:       1,
+		OwnerID:      1,
 	}
 	testCases := []updateTestCase{
 		{
@@ -206,17 +206,41 @@ func (w *worker) deliver(ctx context.Context, e Event) error {
 	}
 }
 
+// NewSignedRequest returns a request that posts payload to url, signed with the master's webhook
+// signing key in the same way as webhook events.
+func NewSignedRequest(ctx context.Context, url string, payload []byte) (*http.Request, error) {
+	return generateWebhookRequest(ctx, url, payload, time.Now().Unix())
+}
+
+// NewRequestSignedWith returns a request like NewSignedRequest, but signed with key instead of
+// the master's webhook signing key.
+func NewRequestSignedWith(
+	ctx context.Context, url string, payload []byte, key []byte,
+) (*http.Request, error) {
+	return generateKeyedWebhookRequest(ctx, url, payload, time.Now().Unix(), key)
+}
+
 func generateWebhookRequest(
 	ctx context.Context,
 	url string,
 	payload []byte,
 	t int64,
+) (*http.Request, error) {
+	key := []byte(conf.GetMasterConfig().Webhooks.SigningKey)
+	return generateKeyedWebhookRequest(ctx, url, payload, t, key)
+}
+
+func generateKeyedWebhookRequest(
+	ctx context.Context,
+	url string,
+	payload []byte,
+	t int64,
+	key []byte,
 ) (*http.Request, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(payload))
 	if err != nil {
 		return nil, fmt.Errorf("failed creating webhook request: %w", err)
 	}
-	key := []byte(conf.GetMasterConfig().Webhooks.SigningKey)
 	signedPayload := generateSignedPayload(req, t, key)
 	req.Header.Add("X-Determined-AI-Signature-Timestamp", fmt.Sprintf("%v", t))
 	req.Header.Add("X-Determined-AI-Signature", signedPayload)
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+// Model access levels, as stored in model_access_grants.
+const (
+	ModelAccessRead = "READ"
+	ModelAccessEdit = "EDIT"
+)
+
+// ModelAccessLevelToDB returns how a model access level is stored in the database.
+func ModelAccessLevelToDB(level modelv1.ModelAccessLevel) string {
+	return strings.TrimPrefix(level.String(), "MODEL_ACCESS_LEVEL_")
+}
+
+// ModelAccessLevelFromDB is the inverse of ModelAccessLevelToDB.
+func ModelAccessLevelFromDB(level string) modelv1.ModelAccessLevel {
+	return modelv1.ModelAccessLevel(modelv1.ModelAccessLevel_value["MODEL_ACCESS_LEVEL_"+level])
+}
+
+// ModelAccessGrant is access to one model granted to a user, in addition to their roles.
+type ModelAccessGrant struct {
+	bun.BaseModel `bun:"table:model_access_grants"`
+	ModelID       int32     `bun:"model_id,pk"`
+	UserID        UserID    `bun:"user_id,pk"`
+	Access        string    `bun:"access"`
+	GrantedByID   *UserID   `bun:"granted_by_id"`
+	CreationTime  time.Time `bun:"creation_time,nullzero,notnull,default:current_timestamp"`
+}
+
+// Allows returns whether the grant gives at least the access level, one of the ModelAccess
+// levels. Edit access includes read access.
+func (g *ModelAccessGrant) Allows(level string) bool {
+	return g.Access == ModelAccessEdit || g.Access == level
+}
+
+// Proto converts the grant to its protobuf representation.
+func (g *ModelAccessGrant) Proto() *modelv1.ModelAccessGrant {
+	pb := &modelv1.ModelAccessGrant{
+		ModelId:      g.ModelID,
+		UserId:       int32(g.UserID),
+		Access:       ModelAccessLevelFromDB(g.Access),
+		CreationTime: timestamppb.New(g.CreationTime),
+	}
+	if g.GrantedByID != nil {
+		grantedByID := int32(*g.GrantedByID)
+		pb.GrantedById = &grantedByID
+	}
+	return pb
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+func TestModelAccessGrant(t *testing.T) {
+	require.Equal(t, ModelAccessRead,
+		ModelAccessLevelToDB(modelv1.ModelAccessLevel_MODEL_ACCESS_LEVEL_READ))
+	require.Equal(t, ModelAccessEdit,
+		ModelAccessLevelToDB(modelv1.ModelAccessLevel_MODEL_ACCESS_LEVEL_EDIT))
+
+	read := &ModelAccessGrant{ModelID: 1, UserID: 2, Access: ModelAccessRead}
+	require.True(t, read.Allows(ModelAccessRead))
+	require.False(t, read.Allows(ModelAccessEdit))
+	edit := &ModelAccessGrant{ModelID: 1, UserID: 2, Access: ModelAccessEdit}
+	require.True(t, edit.Allows(ModelAccessRead), "edit access includes read access")
+	require.True(t, edit.Allows(ModelAccessEdit))
+
+	grantedByID := UserID(3)
+	edit.GrantedByID = &grantedByID
+	pb := edit.Proto()
+	require.Equal(t, modelv1.ModelAccessLevel_MODEL_ACCESS_LEVEL_EDIT, pb.Access)
+	require.Equal(t, int32(3), pb.GetGrantedById())
+	require.Nil(t, read.Proto().GrantedById)
+}
@@ -0,0 +1,14 @@
+package model
+
+// ModelCreationQuota describes whether a user may currently create a model in a workspace.
+type ModelCreationQuota struct {
+	// Allowed is whether creating a model in the workspace would currently be allowed.
+	Allowed bool
+	// Reason explains why the creation is not allowed.
+	Reason string
+	// Limit is the most models the workspace may have, or nil if it has no limit.
+	Limit *int
+	// Count is the number of models counted against Limit. It is only set if there is a
+	// limit and the user has permission to create models in the workspace.
+	Count int
+}
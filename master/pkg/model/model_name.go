@@ -0,0 +1,13 @@
+package model
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeModelName returns the form model names are compared in: NFC normalized and lower
+// case, so that names that only differ in case or Unicode composition conflict.
+func NormalizeModelName(name string) string {
+	return strings.ToLower(norm.NFC.String(name))
+}
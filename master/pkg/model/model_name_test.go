@@ -0,0 +1,12 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeModelName(t *testing.T) {
+	require.Equal(t, NormalizeModelName("café"), NormalizeModelName("Café"))
+	require.NotEqual(t, NormalizeModelName("café"), NormalizeModelName("cafe"))
+}
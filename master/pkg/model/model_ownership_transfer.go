@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// ModelOwnershipTransfer records a model registry model being given to a new owner.
+type ModelOwnershipTransfer struct {
+	bun.BaseModel   `bun:"table:model_ownership_transfers"`
+	ID              int       `bun:"id,pk,autoincrement"`
+	ModelID         int32     `bun:"model_id"`
+	PreviousOwnerID UserID    `bun:"previous_owner_id"`
+	NewOwnerID      UserID    `bun:"new_owner_id"`
+	TransferredBy   UserID    `bun:"transferred_by"`
+	TransferredAt   time.Time `bun:"transferred_at,nullzero,notnull,default:current_timestamp"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// ModelVersionIdempotencyKey records the model version that a request with an idempotency key
+// registered, so that retries of the request return it instead of registering another version.
+type ModelVersionIdempotencyKey struct {
+	bun.BaseModel  `bun:"table:model_version_idempotency_keys"`
+	ModelID        int32     `bun:"model_id,pk"`
+	IdempotencyKey string    `bun:"idempotency_key,pk"`
+	ModelVersionID int32     `bun:"model_version_id"`
+	CheckpointUUID string    `bun:"checkpoint_uuid,type:uuid"`
+	CreationTime   time.Time `bun:"creation_time,nullzero,notnull,default:current_timestamp"`
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/determined-ai/determined/proto/pkg/modelv1"
+)
+
+// Model webhook events, as stored in model_webhooks and sent as the event type of deliveries.
+const (
+	ModelWebhookVersionCreated  = "MODEL_VERSION_CREATED"
+	ModelWebhookVersionPromoted = "MODEL_VERSION_PROMOTED"
+)
+
+// ModelWebhookEventToDB returns how a model webhook event is stored in the database.
+func ModelWebhookEventToDB(event modelv1.ModelWebhookEvent) string {
+	return "MODEL_" + strings.TrimPrefix(event.String(), "MODEL_WEBHOOK_EVENT_")
+}
+
+// ModelWebhookEventFromDB is the inverse of ModelWebhookEventToDB.
+func ModelWebhookEventFromDB(event string) modelv1.ModelWebhookEvent {
+	return modelv1.ModelWebhookEvent(modelv1.ModelWebhookEvent_value["MODEL_WEBHOOK_EVENT_"+
+		strings.TrimPrefix(event, "MODEL_")])
+}
+
+// ModelWebhook is a webhook that is sent events of one model.
+type ModelWebhook struct {
+	bun.BaseModel `bun:"table:model_webhooks"`
+	ID            int32    `bun:"id,pk,autoincrement"`
+	ModelID       int32    `bun:"model_id"`
+	URL           string   `bun:"url"`
+	Events        []string `bun:"events,array"`
+	// Secret signs deliveries. It must never be returned by the API or logged.
+	Secret       string    `bun:"secret" json:"-"`
+	UserID       *UserID   `bun:"user_id"`
+	CreationTime time.Time `bun:"creation_time,nullzero,notnull,default:current_timestamp"`
+}
+
+// Proto converts the webhook to its protobuf representation, leaving out the secret.
+func (w *ModelWebhook) Proto() *modelv1.ModelWebhook {
+	events := make([]modelv1.ModelWebhookEvent, len(w.Events))
+	for i, e := range w.Events {
+		events[i] = ModelWebhookEventFromDB(e)
+	}
+	return &modelv1.ModelWebhook{
+		Id:           w.ID,
+		ModelId:      w.ModelID,
+		Url:          w.URL,
+		Events:       events,
+		HasSecret:    w.Secret != "",
+		CreationTime: timestamppb.New(w.CreationTime),
+	}
+}
+
+// Model webhook delivery states.
+const (
+	ModelWebhookDeliveryPending   = "PENDING"
+	ModelWebhookDeliveryDelivered = "DELIVERED"
+	ModelWebhookDeliveryFailed    = "FAILED"
+)
+
+// ModelWebhookDelivery is an event queued for, or already sent to, a model webhook.
+type ModelWebhookDelivery struct {
+	bun.BaseModel   `bun:"table:model_webhook_deliveries"`
+	ID              int32      `bun:"id,pk,autoincrement"`
+	WebhookID       int32      `bun:"webhook_id"`
+	Event           string     `bun:"event"`
+	Payload         []byte     `bun:"payload,type:jsonb"`
+	State           string     `bun:"state,nullzero,notnull,default:'PENDING'"`
+	Attempts        int32      `bun:"attempts"`
+	LastError       string     `bun:"last_error"`
+	CreationTime    time.Time  `bun:"creation_time,nullzero,notnull,default:current_timestamp"`
+	LastAttemptTime *time.Time `bun:"last_attempt_time"`
+	NextAttemptTime time.Time  `bun:"next_attempt_time,nullzero,notnull,default:current_timestamp"`
+}
+
+// Proto converts the delivery to its protobuf representation, leaving out the payload.
+func (d *ModelWebhookDelivery) Proto() *modelv1.ModelWebhookDelivery {
+	state := modelv1.ModelWebhookDeliveryState_value["MODEL_WEBHOOK_DELIVERY_STATE_"+d.State]
+	pb := &modelv1.ModelWebhookDelivery{
+		Id:           d.ID,
+		WebhookId:    d.WebhookID,
+		Event:        ModelWebhookEventFromDB(d.Event),
+		State:        modelv1.ModelWebhookDeliveryState(state),
+		Attempts:     d.Attempts,
+		LastError:    d.LastError,
+		CreationTime: timestamppb.New(d.CreationTime),
+	}
+	if d.LastAttemptTime != nil {
+		pb.LastAttemptTime = timestamppb.New(*d.LastAttemptTime)
+	}
+	if d.State == ModelWebhookDeliveryPending {
+		pb.NextAttemptTime = timestamppb.New(d.NextAttemptTime)
+	}
+	return pb
+}
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS ix_models_labels;
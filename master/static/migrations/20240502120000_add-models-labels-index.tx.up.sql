@@ -0,0 +1 @@
+CREATE INDEX ix_models_labels ON models USING GIN (labels);
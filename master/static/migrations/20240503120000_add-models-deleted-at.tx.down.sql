@@ -0,0 +1,34 @@
+CREATE OR REPLACE FUNCTION stream_model_change() RETURNS TRIGGER AS $$
+BEGIN
+    IF (TG_OP = 'INSERT') THEN
+        PERFORM stream_model_notify(NULL, to_jsonb(NEW));
+    ELSEIF (TG_OP = 'UPDATE') THEN
+        PERFORM stream_model_notify(to_jsonb(OLD), to_jsonb(NEW));
+    ELSEIF (TG_OP = 'DELETE') THEN
+        PERFORM stream_model_notify(to_jsonb(OLD), NULL);
+        -- DELETEs trigger BEFORE, and must return a non-NULL value.
+        return OLD;
+    END IF;
+    return NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS stream_model_trigger_seq ON models;
+CREATE TRIGGER stream_model_trigger_seq
+    BEFORE INSERT OR UPDATE OF
+    name, description, creation_time, last_updated_time, metadata, labels, user_id, archived, notes, workspace_id
+                     ON models
+                         FOR EACH ROW EXECUTE PROCEDURE stream_model_seq_modify();
+
+DROP TRIGGER IF EXISTS stream_model_trigger_iu ON models;
+CREATE TRIGGER stream_model_trigger_iu
+    AFTER INSERT OR UPDATE OF
+    name, description, creation_time, last_updated_time, metadata, labels, user_id, archived, notes, workspace_id
+                    ON models
+                        FOR EACH ROW EXECUTE PROCEDURE stream_model_change();
+
+DELETE FROM model_versions WHERE model_id IN (SELECT id FROM models WHERE deleted_at IS NOT NULL);
+DELETE FROM models WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS ix_models_deleted_at;
+ALTER TABLE models DROP COLUMN deleted_at;
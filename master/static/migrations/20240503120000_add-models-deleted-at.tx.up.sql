@@ -0,0 +1,44 @@
+ALTER TABLE models ADD COLUMN deleted_at timestamptz NULL;
+
+CREATE INDEX ix_models_deleted_at ON models (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Soft deletes and restores look like DELETEs and INSERTs to streaming clients, and
+-- soft-deleted models are not streamed at all.
+CREATE OR REPLACE FUNCTION stream_model_change() RETURNS TRIGGER AS $$
+BEGIN
+    IF (TG_OP = 'INSERT') THEN
+        PERFORM stream_model_notify(NULL, to_jsonb(NEW));
+    ELSEIF (TG_OP = 'UPDATE') THEN
+        IF (OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL) THEN
+            PERFORM stream_model_notify(to_jsonb(OLD), NULL);
+        ELSEIF (OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL) THEN
+            PERFORM stream_model_notify(NULL, to_jsonb(NEW));
+        ELSEIF (NEW.deleted_at IS NULL) THEN
+            PERFORM stream_model_notify(to_jsonb(OLD), to_jsonb(NEW));
+        END IF;
+    ELSEIF (TG_OP = 'DELETE') THEN
+        IF (OLD.deleted_at IS NULL) THEN
+            PERFORM stream_model_notify(to_jsonb(OLD), NULL);
+        END IF;
+        -- DELETEs trigger BEFORE, and must return a non-NULL value.
+        return OLD;
+    END IF;
+    return NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS stream_model_trigger_seq ON models;
+CREATE TRIGGER stream_model_trigger_seq
+    BEFORE INSERT OR UPDATE OF
+    name, description, creation_time, last_updated_time, metadata, labels, user_id, archived, notes, workspace_id,
+    deleted_at
+                     ON models
+                         FOR EACH ROW EXECUTE PROCEDURE stream_model_seq_modify();
+
+DROP TRIGGER IF EXISTS stream_model_trigger_iu ON models;
+CREATE TRIGGER stream_model_trigger_iu
+    AFTER INSERT OR UPDATE OF
+    name, description, creation_time, last_updated_time, metadata, labels, user_id, archived, notes, workspace_id,
+    deleted_at
+                    ON models
+                        FOR EACH ROW EXECUTE PROCEDURE stream_model_change();
@@ -0,0 +1,2 @@
+DELETE FROM permission_assignments WHERE permission_id = 7008;
+DELETE FROM permissions WHERE id = 7008;
@@ -0,0 +1,7 @@
+INSERT INTO permissions(id, name, global_only) VALUES
+    (7008, 'edit model registry metadata', false) ON CONFLICT DO NOTHING;
+
+-- Every role that could edit models keeps being able to edit their metadata.
+INSERT INTO permission_assignments(permission_id, role_id)
+SELECT 7008, role_id FROM permission_assignments WHERE permission_id = 7002
+ON CONFLICT DO NOTHING;
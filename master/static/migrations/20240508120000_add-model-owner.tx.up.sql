@@ -0,0 +1,30 @@
+ALTER TABLE models ADD COLUMN owner_id integer REFERENCES users(id) NULL;
+UPDATE models SET owner_id = user_id;
+ALTER TABLE models ALTER COLUMN owner_id SET NOT NULL;
+
+CREATE TABLE model_ownership_transfers (
+    id serial PRIMARY KEY,
+    model_id integer NOT NULL REFERENCES models(id) ON DELETE CASCADE,
+    previous_owner_id integer NOT NULL REFERENCES users(id),
+    new_owner_id integer NOT NULL REFERENCES users(id),
+    transferred_by integer NOT NULL REFERENCES users(id),
+    transferred_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX ix_model_ownership_transfers_model_id ON model_ownership_transfers (model_id);
+
+DROP TRIGGER IF EXISTS stream_model_trigger_seq ON models;
+CREATE TRIGGER stream_model_trigger_seq
+    BEFORE INSERT OR UPDATE OF
+    name, description, creation_time, last_updated_time, metadata, labels, user_id, archived, notes, workspace_id,
+    deleted_at, owner_id
+                     ON models
+                         FOR EACH ROW EXECUTE PROCEDURE stream_model_seq_modify();
+
+DROP TRIGGER IF EXISTS stream_model_trigger_iu ON models;
+CREATE TRIGGER stream_model_trigger_iu
+    AFTER INSERT OR UPDATE OF
+    name, description, creation_time, last_updated_time, metadata, labels, user_id, archived, notes, workspace_id,
+    deleted_at, owner_id
+                    ON models
+                        FOR EACH ROW EXECUTE PROCEDURE stream_model_change();
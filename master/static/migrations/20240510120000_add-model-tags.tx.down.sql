@@ -0,0 +1,18 @@
+DROP TRIGGER IF EXISTS stream_model_trigger_seq ON models;
+CREATE TRIGGER stream_model_trigger_seq
+    BEFORE INSERT OR UPDATE OF
+    name, description, creation_time, last_updated_time, metadata, labels, user_id, archived, notes, workspace_id,
+    deleted_at, owner_id
+                     ON models
+                         FOR EACH ROW EXECUTE PROCEDURE stream_model_seq_modify();
+
+DROP TRIGGER IF EXISTS stream_model_trigger_iu ON models;
+CREATE TRIGGER stream_model_trigger_iu
+    AFTER INSERT OR UPDATE OF
+    name, description, creation_time, last_updated_time, metadata, labels, user_id, archived, notes, workspace_id,
+    deleted_at, owner_id
+                    ON models
+                        FOR EACH ROW EXECUTE PROCEDURE stream_model_change();
+
+DROP INDEX ix_models_tags;
+ALTER TABLE models DROP COLUMN tags;
@@ -0,0 +1,19 @@
+ALTER TABLE models ADD COLUMN tags jsonb NOT NULL DEFAULT '{}';
+
+CREATE INDEX ix_models_tags ON models USING GIN (tags jsonb_path_ops);
+
+DROP TRIGGER IF EXISTS stream_model_trigger_seq ON models;
+CREATE TRIGGER stream_model_trigger_seq
+    BEFORE INSERT OR UPDATE OF
+    name, description, creation_time, last_updated_time, metadata, labels, user_id, archived, notes, workspace_id,
+    deleted_at, owner_id, tags
+                     ON models
+                         FOR EACH ROW EXECUTE PROCEDURE stream_model_seq_modify();
+
+DROP TRIGGER IF EXISTS stream_model_trigger_iu ON models;
+CREATE TRIGGER stream_model_trigger_iu
+    AFTER INSERT OR UPDATE OF
+    name, description, creation_time, last_updated_time, metadata, labels, user_id, archived, notes, workspace_id,
+    deleted_at, owner_id, tags
+                    ON models
+                        FOR EACH ROW EXECUTE PROCEDURE stream_model_change();
@@ -0,0 +1,3 @@
+ALTER TABLE models DROP COLUMN visibility;
+
+DROP TYPE model_visibility;
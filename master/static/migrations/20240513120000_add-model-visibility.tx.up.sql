@@ -0,0 +1,3 @@
+CREATE TYPE model_visibility AS ENUM ('WORKSPACE', 'PRIVATE');
+
+ALTER TABLE models ADD COLUMN visibility model_visibility NOT NULL DEFAULT 'WORKSPACE';
@@ -0,0 +1,3 @@
+DROP INDEX ix_models_search;
+
+DROP FUNCTION model_search_vector;
@@ -0,0 +1,12 @@
+-- Model names and labels are weighted above descriptions when ranking search results. The
+-- 'simple' configuration is used so that names are matched without stemming.
+CREATE FUNCTION model_search_vector(name text, description text, labels text[])
+RETURNS tsvector AS $$
+    SELECT
+        setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(array_to_string(labels, ' '), '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(description, '')), 'C')
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE INDEX ix_models_search ON models
+    USING GIN (model_search_vector(name, description, labels));
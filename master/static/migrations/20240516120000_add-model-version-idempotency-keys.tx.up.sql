@@ -0,0 +1,8 @@
+CREATE TABLE model_version_idempotency_keys (
+    model_id integer NOT NULL REFERENCES models(id) ON DELETE CASCADE,
+    idempotency_key text NOT NULL,
+    model_version_id integer NOT NULL REFERENCES model_versions(id) ON DELETE CASCADE,
+    checkpoint_uuid uuid NOT NULL,
+    creation_time timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (model_id, idempotency_key)
+);
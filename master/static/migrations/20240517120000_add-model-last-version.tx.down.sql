@@ -0,0 +1 @@
+ALTER TABLE models DROP COLUMN last_model_version;
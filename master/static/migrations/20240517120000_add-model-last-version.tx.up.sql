@@ -0,0 +1,6 @@
+ALTER TABLE models ADD COLUMN last_model_version integer NOT NULL DEFAULT 0;
+
+-- The highest version number ever assigned, so numbers are not reused after deletions.
+UPDATE models SET last_model_version = COALESCE(
+    (SELECT MAX(version) FROM model_versions WHERE model_id = models.id), 0
+);
@@ -0,0 +1,2 @@
+DROP TABLE model_webhook_deliveries;
+DROP TABLE model_webhooks;
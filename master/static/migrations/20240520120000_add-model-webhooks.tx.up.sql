@@ -0,0 +1,29 @@
+CREATE TABLE model_webhooks (
+    id SERIAL PRIMARY KEY,
+    model_id integer NOT NULL REFERENCES models(id) ON DELETE CASCADE,
+    url text NOT NULL,
+    events text[] NOT NULL,
+    -- Never returned by the API.
+    secret text NOT NULL DEFAULT '',
+    user_id integer REFERENCES users(id) ON DELETE SET NULL,
+    creation_time timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX ix_model_webhooks_model_id ON model_webhooks(model_id);
+
+-- Both the queue of events to deliver and the log of past deliveries.
+CREATE TABLE model_webhook_deliveries (
+    id SERIAL PRIMARY KEY,
+    webhook_id integer NOT NULL REFERENCES model_webhooks(id) ON DELETE CASCADE,
+    event text NOT NULL,
+    payload jsonb NOT NULL,
+    state text NOT NULL DEFAULT 'PENDING',
+    attempts integer NOT NULL DEFAULT 0,
+    last_error text NOT NULL DEFAULT '',
+    creation_time timestamptz NOT NULL DEFAULT now(),
+    last_attempt_time timestamptz,
+    next_attempt_time timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX ix_model_webhook_deliveries_webhook_id
+    ON model_webhook_deliveries(webhook_id, creation_time DESC);
+CREATE INDEX ix_model_webhook_deliveries_pending
+    ON model_webhook_deliveries(next_attempt_time) WHERE state = 'PENDING';
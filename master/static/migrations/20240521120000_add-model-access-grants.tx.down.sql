@@ -0,0 +1,2 @@
+DROP TABLE model_access_grants;
+DROP TYPE model_access_level;
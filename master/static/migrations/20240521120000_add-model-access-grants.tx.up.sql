@@ -0,0 +1,12 @@
+CREATE TYPE model_access_level AS ENUM ('READ', 'EDIT');
+
+-- Access to one model granted to a user on top of what their roles give them.
+CREATE TABLE model_access_grants (
+    model_id integer NOT NULL REFERENCES models(id) ON DELETE CASCADE,
+    user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    access model_access_level NOT NULL,
+    granted_by_id integer REFERENCES users(id) ON DELETE SET NULL,
+    creation_time timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (model_id, user_id)
+);
+CREATE INDEX ix_model_access_grants_user_id ON model_access_grants(user_id);
@@ -0,0 +1 @@
+ALTER TABLE models DROP COLUMN max_versions, DROP COLUMN prune_versions;
@@ -0,0 +1,3 @@
+ALTER TABLE models
+    ADD COLUMN max_versions integer NOT NULL DEFAULT 0,
+    ADD COLUMN prune_versions boolean NOT NULL DEFAULT false;
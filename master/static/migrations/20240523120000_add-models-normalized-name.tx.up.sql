@@ -0,0 +1,10 @@
+ALTER TABLE models ADD COLUMN normalized_name text;
+
+-- The master sets normalized_name to the name in NFC and lower case. Postgres before 13 cannot
+-- normalize to NFC, so existing names are only lowercased. Of names that are equal ignoring case,
+-- only the oldest is backfilled; the others are left NULL until they are renamed.
+UPDATE models SET normalized_name = lower(name)
+WHERE id IN (SELECT min(id) FROM models GROUP BY lower(name));
+
+-- Deleted models keep their names until they are purged, so that they can be restored.
+CREATE UNIQUE INDEX ix_models_normalized_name ON models (normalized_name);
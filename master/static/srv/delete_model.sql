@@ -1,14 +1,4 @@
-WITH m AS (
-    SELECT id
-    FROM models
-    WHERE name = $1
-),
-
-mv AS (
-    DELETE FROM model_versions
-    WHERE model_id IN (SELECT id FROM m)
-)
-
-DELETE FROM models
-WHERE models.id IN (SELECT id FROM m)
+UPDATE models
+SET deleted_at = now()
+WHERE name = $1 AND deleted_at IS NULL
 RETURNING models.id;
@@ -8,6 +8,12 @@ SELECT
     m.last_updated_time,
     array_to_json(m.labels) AS labels,
     m.user_id,
+    m.owner_id,
+    m.tags,
+    m.version,
+    m.max_versions,
+    m.prune_versions,
+    'MODEL_VISIBILITY_' || m.visibility AS visibility,
     m.workspace_id,
     u.username,
     m.archived,
@@ -16,5 +22,5 @@ FROM models AS m
 LEFT JOIN model_versions AS mv
     ON mv.model_id = m.id
 LEFT JOIN users AS u ON u.id = m.user_id
-WHERE m.id = $1
+WHERE m.id = $1 AND m.deleted_at IS NULL
 GROUP BY m.id, u.id;
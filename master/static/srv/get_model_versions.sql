@@ -29,8 +29,15 @@ m AS (
         array_to_json(m.labels) AS labels,
         u.username,
         m.user_id,
+        m.owner_id,
+        m.tags,
+        m.version,
+        m.max_versions,
+        m.prune_versions,
+        'MODEL_VISIBILITY_' || m.visibility AS visibility,
         m.archived,
-        count(mv.version) AS num_versions
+        count(mv.version) AS num_versions,
+        m.workspace_id
     FROM models AS m
     JOIN users AS u ON u.id = m.user_id
     LEFT JOIN model_versions AS mv
@@ -0,0 +1,67 @@
+WITH mv AS (
+    SELECT
+        version,
+        checkpoint_uuid,
+        creation_time,
+        name,
+        comment,
+        model_versions.id,
+        model_id,
+        metadata,
+        labels,
+        notes,
+        username,
+        user_id,
+        last_updated_time
+    FROM model_versions
+    LEFT JOIN users ON users.id = model_versions.user_id
+    WHERE model_versions.id = ANY(string_to_array($1, ',')::int [])
+),
+
+m AS (
+    SELECT
+        m.id,
+        m.name,
+        m.description,
+        m.notes,
+        m.metadata,
+        m.creation_time,
+        m.last_updated_time,
+        array_to_json(m.labels) AS labels,
+        u.username,
+        m.user_id,
+        m.owner_id,
+        m.tags,
+        m.version,
+        m.max_versions,
+        m.prune_versions,
+        'MODEL_VISIBILITY_' || m.visibility AS visibility,
+        m.archived,
+        count(mv.version) AS num_versions,
+        m.workspace_id
+    FROM models AS m
+    JOIN users AS u ON u.id = m.user_id
+    LEFT JOIN model_versions AS mv
+        ON mv.model_id = m.id
+    WHERE m.id IN (SELECT model_id FROM mv)
+    GROUP BY m.id, u.id
+)
+
+SELECT
+    to_json(c) AS checkpoint,
+    to_json(m) AS model,
+    array_to_json(mv.labels) AS labels,
+    mv.version,
+    mv.id,
+    mv.creation_time,
+    mv.notes,
+    mv.username,
+    mv.user_id,
+    mv.name,
+    mv.comment,
+    mv.metadata,
+    mv.last_updated_time
+FROM proto_checkpoints_view c
+JOIN mv ON c.uuid = mv.checkpoint_uuid
+JOIN m ON m.id = mv.model_id
+ORDER BY m.id, mv.version;
@@ -1,3 +1,4 @@
-UPDATE models SET name = $2, description = $3, notes = $4, metadata = $5, labels = string_to_array($6, ','), workspace_id = $7, last_updated_time = current_timestamp
-WHERE id = $1
-RETURNING name, description, notes, metadata, array_to_json(labels) as labels, creation_time, last_updated_time
+UPDATE models SET name = $2, normalized_name = $12, description = $3, notes = $4, metadata = $5, labels = string_to_array($6, ','), workspace_id = $7, visibility = $8, max_versions = $10, prune_versions = $11, version = version + 1, last_updated_time = current_timestamp
+WHERE id = $1 AND ($9::integer IS NULL OR version = $9)
+RETURNING name, description, notes, metadata, array_to_json(labels) as labels, creation_time, last_updated_time,
+    'MODEL_VISIBILITY_' || visibility AS visibility, version, max_versions, prune_versions
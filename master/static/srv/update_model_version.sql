@@ -28,6 +28,13 @@ m AS (
         m.last_updated_time,
         array_to_json(m.labels) AS labels,
         u.username,
+        m.user_id,
+        m.owner_id,
+        m.tags,
+        m.version,
+        m.max_versions,
+        m.prune_versions,
+        'MODEL_VISIBILITY_' || m.visibility AS visibility,
         m.archived,
         count(mv.version) AS num_versions
     FROM models AS m
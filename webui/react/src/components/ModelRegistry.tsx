@@ -125,6 +125,7 @@ const ModelRegistry: React.FC<Props> = ({ workspace }: Props) => {
         {
           archived: settings.archived ? undefined : false,
           description: settings.description,
+          includeArchived: settings.archived,
           labels: settings.tags,
           limit: settings.tableLimit,
           name: settings.name,
@@ -1212,6 +1212,7 @@ export const getModels: DetApi<
       getUserIds(params.users),
       undefined,
       params.workspaceIds,
+      params.includeArchived,
     ),
 };
 
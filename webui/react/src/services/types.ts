@@ -170,6 +170,7 @@ export interface GetExperimentFileFromTreeParams {
 export interface GetModelsParams extends PaginationParams {
   archived?: boolean;
   description?: string;
+  includeArchived?: boolean;
   labels?: string[];
   name?: string;
   sortBy?: